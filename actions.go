@@ -0,0 +1,115 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var snoozeMinutes = 60 // os.Getenv("SNOOZE_MINUTES") // how long the snooze action silences the bulb and ntfy
+
+var snoozedUntil time.Time
+var ackedState = "" // cluster state acknowledged by the ack action, shown as healthy until it changes
+
+// ackedIssues are issue keys acknowledged with "ack <key>"; they don't raise signals until they clear
+var ackedIssues = map[string]bool{}
+
+// userActions carries ack, snooze and recheck actions from input devices such as a Stream Deck
+// to the scheduler, which applies them on its own goroutine
+var userActions = make(chan string, 4)
+
+// clusterRecheck asks the scheduler to run clusterChecks outside of the normal interval
+var clusterRecheck = make(chan struct{}, 1)
+
+// requestUserAction queues an action and reports whether it was queued; it is dropped when
+// the scheduler is busy with earlier ones
+func requestUserAction(action string) bool {
+	select {
+	case userActions <- action:
+		return true
+	default:
+		log.Printf("Dropping %s action, too many pending actions", action)
+		return false
+	}
+}
+
+// requestClusterRecheck schedules an immediate cluster check unless one is already pending
+func requestClusterRecheck() {
+	select {
+	case clusterRecheck <- struct{}{}:
+	default:
+	}
+}
+
+// recheckHandler triggers an immediate cluster and SCM check followed by a bulb update,
+// e.g. from a Home Assistant button right after fixing something. A dropped recheck fails with
+// 429, so the caller knows to retry.
+func recheckHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !requestUserAction("recheck") {
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many pending actions", http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// runUserAction applies an ack, snooze, unmute or recheck action; ack takes an optional issue
+// key and snooze an optional duration, e.g. "ack pod/prod/api-0" or "snooze 2h"
+func runUserAction(action string) {
+	action, arg, _ := strings.Cut(action, " ")
+	switch action {
+	case "ack":
+		if arg != "" {
+			ackedIssues[arg] = true
+			log.Printf("Acknowledged issue %s", arg)
+			return
+		}
+		ackedState = clusterState.String()
+		log.Printf("Acknowledged cluster state %s", ackedState)
+	case "snooze":
+		d := time.Duration(snoozeMinutes) * time.Minute
+		if v, err := time.ParseDuration(arg); err == nil && v > 0 {
+			d = v
+		}
+		snoozedUntil = time.Now().Add(d)
+		log.Printf("Snoozed bulb and notifications until %s", snoozedUntil.Format(time.RFC3339))
+	case "unmute":
+		snoozedUntil = time.Time{}
+		log.Printf("Snooze cleared")
+	case "recheck":
+		requestClusterRecheck()
+		requestSCMRecheck()
+	}
+}
+
+// isMuted reports whether bulb updates and notifications are silenced, by the snooze
+// action or the Home Assistant mute helper; checks keep running while muted
+func isMuted() bool {
+	return haMuted || time.Now().Before(snoozedUntil)
+}
+
+// unacked returns the issues that were not acknowledged individually
+func unacked(issues []Issue) []Issue {
+	return slices.DeleteFunc(slices.Clone(issues), func(issue Issue) bool { return ackedIssues[issue.Key] })
+}
+
+// pruneAckedIssues forgets acknowledgements of issues that cleared, so they alert again when they return
+func pruneAckedIssues(active []Issue) {
+	for key := range ackedIssues {
+		if !slices.ContainsFunc(active, func(issue Issue) bool { return issue.Key == key }) {
+			delete(ackedIssues, key)
+		}
+	}
+}
+
+// ackTransition clears the acknowledgement once the state changes
+func ackTransition(_ Transition) {
+	ackedState = ""
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var alertmanagerUrl = ""              // os.Getenv("ALERTMANAGER_URL") // e.g. http://alertmanager.monitoring:9093
+var alertmanagerMatchers = []string{} // os.Getenv("ALERTMANAGER_MATCHERS") // comma-separated, e.g. severity=~"critical|warning",namespace="prod"
+var alertmanagerIssues []Issue        // issues of the last successful check, kept while Alertmanager can't be reached
+
+// Alert represents a firing alert returned by the Alertmanager v2 API
+type Alert struct {
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// checkAlertmanager maps active, unsilenced Alertmanager alerts matching
+// ALERTMANAGER_MATCHERS into issues, using the alert's severity label. While Alertmanager can't
+// be reached the alerts of the last successful check are kept, so an outage doesn't look healthy.
+func checkAlertmanager(ctx context.Context, _ kubernetes.Interface) []Issue {
+	if !integrationReady("alertmanager") {
+		return alertmanagerIssues
+	}
+
+	q := url.Values{}
+	q.Set("active", "true")
+	q.Set("silenced", "false")
+	q.Set("inhibited", "false")
+	for _, m := range alertmanagerMatchers {
+		q.Add("filter", m)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/v2/alerts?%s", strings.TrimRight(alertmanagerUrl, "/"), q.Encode()), nil)
+	if err != nil {
+		HandleError("alertmanager", "Error creating Alertmanager request:", err)
+		return alertmanagerIssues
+	}
+	var alerts []Alert
+	if err := httpGetJSON("Alertmanager", req, &alerts); err != nil {
+		HandleError("alertmanager", "Error checking Alertmanager:", err)
+		return alertmanagerIssues
+	}
+	integrationOK("alertmanager")
+
+	var issues []Issue
+	for _, a := range alerts {
+		msg := a.Annotations["summary"]
+		if msg == "" {
+			msg = a.Annotations["description"]
+		}
+		if ns := a.Labels["namespace"]; ns != "" {
+			msg = fmt.Sprintf("%s/%s: %s", ns, a.Labels["alertname"], msg)
+		} else {
+			msg = fmt.Sprintf("%s: %s", a.Labels["alertname"], msg)
+		}
+		issues = append(issues, Issue{
+			Key:       fmt.Sprintf("alert/%s/%s", a.Labels["alertname"], a.Fingerprint),
+			Type:      "Alert",
+			Severity:  alertSeverity(a.Labels["severity"]),
+			Message:   msg,
+			Timestamp: time.Now(),
+			Namespace: a.Labels["namespace"],
+			Reason:    a.Labels["alertname"],
+		})
+	}
+	alertmanagerIssues = issues
+	return issues
+}
+
+// alertSeverity maps common Prometheus severity label values onto issue severities
+func alertSeverity(label string) string {
+	switch strings.ToLower(label) {
+	case "critical", "error", "page", "high":
+		return "critical"
+	case "info", "none", "low":
+		return "info"
+	default:
+		return "warning"
+	}
+}
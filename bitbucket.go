@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var bitbucketWorkspace = ""   // os.Getenv("BITBUCKET_WORKSPACE")
+var bitbucketRepo = ""        // os.Getenv("BITBUCKET_REPO")
+var bitbucketUsername = ""    // os.Getenv("BITBUCKET_USERNAME") // used with BITBUCKET_APP_PASSWORD
+var bitbucketAppPassword = "" // os.Getenv("BITBUCKET_APP_PASSWORD")
+var bitbucketToken = ""       // os.Getenv("BITBUCKET_TOKEN") // repository/workspace access token, alternative to an app password
+
+var bitbucketApiUrl = "https://api.bitbucket.org/2.0"
+
+// bitbucketProvider polls Bitbucket Cloud for open pull requests
+type bitbucketProvider struct{}
+
+// BitbucketPullRequests represents a page of Bitbucket pull requests
+type BitbucketPullRequests struct {
+	Values []struct {
+		ID     int    `json:"id"`
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		Author struct {
+			DisplayName string `json:"display_name"`
+			Nickname    string `json:"nickname"`
+		} `json:"author"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+		CreatedOn time.Time `json:"created_on"`
+		UpdatedOn time.Time `json:"updated_on"`
+	} `json:"values"`
+	Next string `json:"next"` // URL of the next page, empty on the last one
+}
+
+// BitbucketBranch represents a Bitbucket branch and its head commit
+type BitbucketBranch struct {
+	Target struct {
+		Hash string `json:"hash"`
+	} `json:"target"`
+}
+
+// BitbucketStatuses represents a page of Bitbucket commit build statuses
+type BitbucketStatuses struct {
+	Values []struct {
+		Key   string `json:"key"`
+		State string `json:"state"` // SUCCESSFUL, FAILED, INPROGRESS, STOPPED
+	} `json:"values"`
+}
+
+func (bitbucketProvider) Name() string {
+	return "Bitbucket"
+}
+
+func (bitbucketProvider) OpenPullRequests(ctx context.Context) ([]PullRequest, error) {
+	var prs []PullRequest
+	url := fmt.Sprintf("%s/repositories/%s/%s/pullrequests?state=OPEN&pagelen=50", bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo)
+	// Each page links to the next one until the last
+	for url != "" {
+		var page BitbucketPullRequests
+		if err := bitbucketGet(ctx, url, &page); err != nil {
+			return nil, err
+		}
+		for _, v := range page.Values {
+			prs = append(prs, PullRequest{
+				Number:    v.ID,
+				Title:     v.Title,
+				User:      User{Login: v.Author.Nickname},
+				State:     "open",
+				HTMLURL:   v.Links.HTML.Href,
+				CreatedAt: v.CreatedOn,
+				UpdatedAt: v.UpdatedOn,
+			})
+		}
+		url = page.Next
+	}
+	return prs, nil
+}
+
+func (bitbucketProvider) CIStatus(ctx context.Context, branch string) (string, error) {
+	// Statuses are attached to commits, so resolve the head of the branch first
+	var head BitbucketBranch
+	repoUrl := fmt.Sprintf("%s/repositories/%s/%s", bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo)
+	if err := bitbucketGet(ctx, repoUrl+"/refs/branches/"+url.PathEscape(branch), &head); err != nil {
+		return "", err
+	}
+	if head.Target.Hash == "" {
+		return "", fmt.Errorf("branch %s has no head commit", branch)
+	}
+
+	var statuses BitbucketStatuses
+	if err := bitbucketGet(ctx, repoUrl+"/commit/"+head.Target.Hash+"/statuses", &statuses); err != nil {
+		return "", err
+	}
+
+	state := ""
+	for _, s := range statuses.Values {
+		switch s.State {
+		case "FAILED", "STOPPED":
+			return "failure", nil
+		case "INPROGRESS":
+			state = "pending"
+		default:
+			if state == "" {
+				state = "success"
+			}
+		}
+	}
+	return state, nil
+}
+
+func (bitbucketProvider) MergeStatus(ctx context.Context, pr PullRequest) (string, error) {
+	// Bitbucket Cloud only computes conflicts when a merge is attempted, so there is nothing to report
+	return "", nil
+}
+
+// bitbucketGet performs an authenticated Bitbucket API GET request and decodes the JSON response into v
+func bitbucketGet(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if bitbucketToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bitbucketToken)
+	} else if bitbucketUsername != "" {
+		req.SetBasicAuth(bitbucketUsername, bitbucketAppPassword)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	return httpGetJSON("Bitbucket", req, v)
+}
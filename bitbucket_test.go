@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBitbucketCIStatus(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repositories/ws/repo/refs/branches/main":
+			w.Write([]byte(`{"target": {"hash": "abc123"}}`))
+		case "/repositories/ws/repo/commit/abc123/statuses":
+			w.Write([]byte(`{"values": [{"key": "build", "state": "SUCCESSFUL"}, {"key": "test", "state": "FAILED"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	defer func(u, ws, repo string) { bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo = u, ws, repo }(bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo)
+	bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo = api.URL, "ws", "repo"
+
+	state, err := bitbucketProvider{}.CIStatus(context.Background(), "main")
+	if err != nil || state != "failure" {
+		t.Errorf("CIStatus = %q, %v, want failure", state, err)
+	}
+}
+
+func TestNewSCMProviderNeedsConfig(t *testing.T) {
+	if p, err := newSCMProvider(""); p != nil || err != nil {
+		t.Errorf("unset SCM_PROVIDER = %v, %v, want no provider", p, err)
+	}
+	for _, name := range []string{"github", "gitea", "bitbucket", "gitlab"} {
+		if _, err := newSCMProvider(name); err == nil {
+			t.Errorf("unconfigured %s was accepted", name)
+		}
+	}
+}
+
+func TestOpenPullRequestsFollowPages(t *testing.T) {
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.Query().Get("page") {
+		case "/repositories/ws/repo/pullrequests?":
+			fmt.Fprintf(w, `{"values": [{"id": 1}], "next": "%s/repositories/ws/repo/pullrequests?page=2"}`, api.URL)
+		case "/repositories/ws/repo/pullrequests?2":
+			w.Write([]byte(`{"values": [{"id": 2}]}`))
+		case "/api/v1/repos/owner/repo/pulls?":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/repos/owner/repo/pulls?page=2>; rel="next", <%s/api/v1/repos/owner/repo/pulls?page=2>; rel="last"`, api.URL, api.URL))
+			w.Write([]byte(`[{"number": 1}]`))
+		case "/api/v1/repos/owner/repo/pulls?2":
+			w.Write([]byte(`[{"number": 2}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	defer func(u, ws, repo string) { bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo = u, ws, repo }(bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo)
+	defer func(u, owner, repo string) { giteaUrl, giteaOwner, giteaRepo = u, owner, repo }(giteaUrl, giteaOwner, giteaRepo)
+	bitbucketApiUrl, bitbucketWorkspace, bitbucketRepo = api.URL, "ws", "repo"
+	giteaUrl, giteaOwner, giteaRepo = api.URL, "owner", "repo"
+
+	for _, p := range []SCMProvider{bitbucketProvider{}, giteaProvider{}} {
+		prs, err := p.OpenPullRequests(context.Background())
+		if err != nil || len(prs) != 2 || prs[1].Number != 2 {
+			t.Errorf("%s pull requests = %+v, %v, want both pages", p.Name(), prs, err)
+		}
+	}
+}
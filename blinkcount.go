@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+var bulbCountMode = "" // os.Getenv("BULB_COUNT_MODE") // issues or prs, empty disables the blink count
+var bulbCountMax = 10  // os.Getenv("BULB_COUNT_MAX") // caps the blinks of a burst
+
+var activeIssueCount = 0 // issues of the last cluster check, shown with BULB_COUNT_MODE=issues
+var countBurstSteps = 0  // remaining off/on steps of the current burst
+var countBurstAt time.Time
+
+// bulbCount returns the number communicated by the blink count
+func bulbCount() int {
+	n := activeIssueCount
+	if bulbCountMode == "prs" {
+		n = prCount
+	}
+	return min(n, bulbCountMax)
+}
+
+// countBurstStep drives a burst of N short off/on blinks once a minute on top of the
+// current color; it returns false when no burst is running and the bulb update should proceed
+func countBurstStep(ctx context.Context) bool {
+	if bulbCountMode == "" {
+		return false
+	}
+	if countBurstSteps == 0 {
+		if time.Since(countBurstAt) < time.Minute {
+			return false
+		}
+		countBurstAt = time.Now()
+		countBurstSteps = 2 * bulbCount()
+		if countBurstSteps == 0 {
+			return false
+		}
+	}
+
+	countBurstSteps--
+	if countBurstSteps%2 == 1 {
+		haTurnOffBulb(ctx)
+		return true
+	}
+	haShowSignal(ctx, haLastColorState)
+	return true
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var clusterBootWindow time.Duration // os.Getenv("CLUSTER_BOOT_MINUTES") // how recent node and kube-system restarts count as a cluster boot
+
+// clusterBootedAt is the start of the last detected cluster boot, bootSettled is set once the
+// cluster was free of issues after it
+var clusterBootedAt time.Time
+var bootSettled = false
+
+// detectClusterBoot reports whether the cluster restarted within CLUSTER_BOOT_MINUTES and has not
+// settled yet. A boot is at least half of the nodes becoming ready and half of the kube-system
+// pods starting within the window; it ends when the window passes or no issues remain.
+func detectClusterBoot(ctx context.Context, clientset kubernetes.Interface) bool {
+	if clusterBootWindow <= 0 {
+		return false
+	}
+	since := time.Now().Add(-clusterBootWindow)
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching nodes: %v", err)
+		return false
+	}
+	var bootedAt time.Time
+	recentNodes := 0
+	for _, node := range nodes.Items {
+		for _, cond := range node.Status.Conditions {
+			// Nodes that just went NotReady are failing, not booting
+			if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue && cond.LastTransitionTime.After(since) {
+				recentNodes++
+				if bootedAt.IsZero() || cond.LastTransitionTime.Time.Before(bootedAt) {
+					bootedAt = cond.LastTransitionTime.Time
+				}
+			}
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods("kube-system").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching kube-system pods: %v", err)
+		return false
+	}
+	recentPods := 0
+	for _, pod := range pods.Items {
+		if pod.Status.StartTime == nil || pod.Status.StartTime.After(since) {
+			recentPods++
+		}
+	}
+
+	booted := len(nodes.Items) > 0 && recentNodes*2 >= len(nodes.Items) && recentPods*2 >= len(pods.Items)
+	if booted && !bootedAt.Equal(clusterBootedAt) {
+		log.Printf("Cluster booted at %s, showing the booting state until it settles", bootedAt.Format(time.RFC3339))
+		clusterBootedAt, bootSettled = bootedAt, false
+	}
+	return booted && !bootSettled
+}
+
+// applyClusterBoot replaces the signals of cluster issues with the booting signal while the
+// cluster boots, and ends the boot once no cluster issues remain
+func applyClusterBoot(inputs *StateInputs, booting bool) {
+	if !booting {
+		return
+	}
+	if !inputs.ClusterIssues && !inputs.WarningEvents && inputs.ScoreSig == "" {
+		log.Printf("Cluster settled after boot")
+		bootSettled = true
+		return
+	}
+	inputs.ClusterIssues, inputs.WarningEvents, inputs.ScoreSig = false, false, ""
+	inputs.Booting = true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDetectClusterBoot(t *testing.T) {
+	defer func(w time.Duration) { clusterBootWindow = w }(clusterBootWindow)
+	clusterBootWindow = 10 * time.Minute
+	clusterBootedAt, bootSettled = time.Time{}, false
+
+	nodeWithStatus := func(name string, status v1.ConditionStatus, changedAgo time.Duration) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: status, LastTransitionTime: metav1.NewTime(time.Now().Add(-changedAgo))},
+		}}}
+	}
+	node := func(name string, readyAgo time.Duration) *v1.Node {
+		return nodeWithStatus(name, v1.ConditionTrue, readyAgo)
+	}
+	pod := func(name string, startedAgo time.Duration) *v1.Pod {
+		started := metav1.NewTime(time.Now().Add(-startedAgo))
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"}, Status: v1.PodStatus{StartTime: &started}}
+	}
+
+	stable := fake.NewSimpleClientset(node("a", 48*time.Hour), node("b", time.Minute), pod("coredns", 48*time.Hour), pod("kube-proxy", 48*time.Hour))
+	if detectClusterBoot(context.Background(), stable) {
+		t.Error("detected a boot when only one node restarted")
+	}
+
+	notReady := fake.NewSimpleClientset(nodeWithStatus("a", v1.ConditionFalse, 3*time.Minute), nodeWithStatus("b", v1.ConditionUnknown, 2*time.Minute), pod("coredns", time.Minute))
+	if detectClusterBoot(context.Background(), notReady) {
+		t.Error("detected a boot when the nodes just went NotReady")
+	}
+
+	booted := fake.NewSimpleClientset(node("a", 3*time.Minute), node("b", 2*time.Minute), pod("coredns", time.Minute), pod("kube-proxy", 48*time.Hour))
+	if !detectClusterBoot(context.Background(), booted) {
+		t.Fatal("did not detect a boot after all nodes restarted")
+	}
+
+	inputs := StateInputs{ClusterIssues: true}
+	applyClusterBoot(&inputs, true)
+	if got := composeState(inputs).String(); got != "booting" {
+		t.Errorf("state while booting = %s, want booting", got)
+	}
+	applyClusterBoot(&StateInputs{}, true)
+	if detectClusterBoot(context.Background(), booted) {
+		t.Error("still booting after the cluster settled")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"slices"
+)
+
+// BrightnessConfig scales the bulb brightness with the magnitude of the shown signal, from Min for
+// a single minor issue up to Max for a critical issue, a node down or FullAt issues
+type BrightnessConfig struct {
+	Min    int `yaml:"min"`     // 1-255, enables scaling
+	Max    int `yaml:"max"`     // default HA_LIGHT_BRIGHTNESS
+	FullAt int `yaml:"full_at"` // issue count shown at Max, default 5
+}
+
+// signalIssues are the unacknowledged issues behind each signal of the last evaluation
+var signalIssues = map[Signal][]Issue{}
+
+// validateBrightness checks the brightness clamps
+func validateBrightness() configProblems {
+	var problems configProblems
+	b := config.Brightness
+	if b.Min < 0 || b.Min > 255 || b.Max < 0 || b.Max > 255 {
+		problems.add(-1, "brightness min and max must be between 0-255")
+	}
+	if b.Max > 0 && b.Min > b.Max {
+		problems.add(-1, "brightness min %d is above max %d", b.Min, b.Max)
+	}
+	if b.FullAt < 0 {
+		problems.add(-1, "brightness full_at must not be negative")
+	}
+	return problems
+}
+
+// updateSignalIssues groups the actionable issues by the signal they raise; warning events
+// raise their own signal unless cluster issues are active
+func updateSignalIssues(nodePodIssues, eventIssues, checkIssues []Issue) {
+	signalIssues = map[Signal][]Issue{}
+	for _, issue := range slices.Concat(nodePodIssues, checkIssues) {
+		if isActionable(issue) {
+			signal := issueState(issue)
+			signalIssues[signal] = append(signalIssues[signal], issue)
+		}
+	}
+	signal := warningEventsState
+	if len(signalIssues[SignalIssuesDetected]) > 0 {
+		signal = SignalIssuesDetected
+	}
+	signalIssues[signal] = append(signalIssues[signal], eventIssues...)
+}
+
+// scaledBrightness returns the brightness of the signal; signals without issues behind them,
+// such as open PRs, keep HA_LIGHT_BRIGHTNESS
+func scaledBrightness(signal Signal) int {
+	b := config.Brightness
+	issues := signalIssues[signal]
+	if b.Min <= 0 || len(issues) == 0 {
+		return haLightBrightness
+	}
+	maxBrightness, fullAt := max(haLightBrightness, b.Min), 5
+	if b.Max > 0 {
+		maxBrightness = b.Max
+	}
+	if b.FullAt > 0 {
+		fullAt = b.FullAt
+	}
+
+	critical := slices.ContainsFunc(issues, func(issue Issue) bool { return issue.Severity == "critical" || issue.Type == "Node" })
+	if critical || len(issues) >= fullAt {
+		return maxBrightness
+	}
+	return b.Min + (maxBrightness-b.Min)*(len(issues)-1)/(fullAt-1)
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestScaledBrightness(t *testing.T) {
+	defer func(c Config, b int) { config, haLightBrightness = c, b }(config, haLightBrightness)
+	defer func() { signalIssues = map[Signal][]Issue{} }()
+	haLightBrightness = 200
+
+	pod := Issue{Type: "Pod", Key: "pod/a/b"}
+	updateSignalIssues([]Issue{pod}, nil, nil)
+	if got := scaledBrightness(SignalIssuesDetected); got != 200 {
+		t.Errorf("brightness without scaling = %d, want HA_LIGHT_BRIGHTNESS", got)
+	}
+
+	config.Brightness = BrightnessConfig{Min: 40, Max: 240, FullAt: 5}
+	for _, tt := range []struct {
+		name   string
+		issues []Issue
+		want   int
+	}{
+		{"one warning", []Issue{pod}, 40},
+		{"three pods", []Issue{pod, pod, pod}, 140},
+		{"many pods", []Issue{pod, pod, pod, pod, pod, pod}, 240},
+		{"node down", []Issue{{Type: "Node", Key: "node/a"}}, 240},
+	} {
+		updateSignalIssues(tt.issues, nil, nil)
+		if got := scaledBrightness(SignalIssuesDetected); got != tt.want {
+			t.Errorf("%s: brightness = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+	if got := scaledBrightness(SignalPullRequestsOpen); got != 200 {
+		t.Errorf("brightness of open PRs = %d, want HA_LIGHT_BRIGHTNESS", got)
+	}
+}
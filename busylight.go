@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var busylightDevice = ""                                  // os.Getenv("BUSYLIGHT_DEVICE") // auto, or a hidraw path such as /dev/hidraw3
+var busylightBlinkStates = []Signal{SignalIssuesDetected} // os.Getenv("BUSYLIGHT_BLINK_STATES")
+
+// busylightModel is a supported USB busylight, matched by USB vendor and product id
+type busylightModel struct {
+	name      string
+	vendorID  uint32
+	productID uint32
+	report    func(color [3]int, blink bool) []byte
+	feature   bool // sent as a feature report rather than an output report
+}
+
+var busylightModels = []busylightModel{
+	{name: "Luxafor", vendorID: 0x04d8, productID: 0xf372, report: luxaforReport},
+	{name: "Kuando Busylight", vendorID: 0x27bb, productID: 0x3bcd, report: kuandoReport},
+	{name: "Kuando Busylight", vendorID: 0x27bb, productID: 0x3bcf, report: kuandoReport},
+	{name: "blink(1)", vendorID: 0x27b8, productID: 0x01ed, report: blink1Report, feature: true},
+}
+
+// luxaforReport sets all LEDs of a Luxafor Flag/Orb, strobing for blinking states
+func luxaforReport(color [3]int, blink bool) []byte {
+	if blink {
+		// strobe, all LEDs, speed 20, repeat forever
+		return []byte{3, 0xff, byte(color[0]), byte(color[1]), byte(color[2]), 20, 0, 0}
+	}
+	return []byte{1, 0xff, byte(color[0]), byte(color[1]), byte(color[2]), 0, 0, 0}
+}
+
+// kuandoReport programs step 0 of a Kuando Busylight to loop on itself; channels are 0-100
+// and the device switches off unless it receives a report at least every 30 seconds
+func kuandoReport(color [3]int, blink bool) []byte {
+	report := make([]byte, 64)
+	onTime, offTime := byte(0), byte(0)
+	if blink {
+		onTime, offTime = 5, 5 // tenths of a second
+	}
+	copy(report, []byte{0x10, 0, byte(color[0] * 100 / 255), byte(color[1] * 100 / 255), byte(color[2] * 100 / 255), onTime, offTime, 0x80})
+	copy(report[59:62], []byte{0xff, 0xff, 0xff})
+	sum := 0
+	for _, b := range report[:62] {
+		sum += int(b)
+	}
+	report[62], report[63] = byte(sum>>8), byte(sum)
+	return report
+}
+
+// blink1Report fades a blink(1) to the color; it has no hardware blink, so blinking states
+// rely on the bulb update cycle of combined states only
+func blink1Report(color [3]int, _ bool) []byte {
+	return []byte{1, 'c', byte(color[0]), byte(color[1]), byte(color[2]), 0, 10, 0}
+}
+
+// busylightDisplay drives a USB busylight through Linux hidraw, for out-of-cluster use on a desk machine
+type busylightDisplay struct {
+	path  string
+	model busylightModel
+	last  []byte
+}
+
+// newBusylightDisplay opens the configured device, or the first supported one when device is "auto"
+func newBusylightDisplay(device string) (*busylightDisplay, error) {
+	supported := func(vendorID, productID uint32) bool {
+		return slices.ContainsFunc(busylightModels, func(m busylightModel) bool {
+			return m.vendorID == vendorID && m.productID == productID
+		})
+	}
+	path, vendorID, productID, err := findHIDDevice(device, supported)
+	if err != nil {
+		return nil, fmt.Errorf("no supported busylight found (Luxafor, Kuando Busylight or blink(1)): %w", err)
+	}
+	i := slices.IndexFunc(busylightModels, func(m busylightModel) bool {
+		return m.vendorID == vendorID && m.productID == productID
+	})
+	return &busylightDisplay{path: path, model: busylightModels[i]}, nil
+}
+
+// findHIDDevice returns the hidraw node of device, or of the first supported device when device is "auto"
+func findHIDDevice(device string, supported func(vendorID, productID uint32) bool) (string, uint32, uint32, error) {
+	nodes := []string{device}
+	if device == "auto" {
+		nodes, _ = filepath.Glob("/dev/hidraw*")
+	}
+	for _, node := range nodes {
+		vendorID, productID, err := hidrawID(node)
+		if err != nil {
+			if device != "auto" {
+				return "", 0, 0, err
+			}
+			continue
+		}
+		if supported(vendorID, productID) {
+			return node, vendorID, productID, nil
+		}
+	}
+	if device == "auto" {
+		return "", 0, 0, fmt.Errorf("none of %d hidraw devices matched", len(nodes))
+	}
+	return "", 0, 0, fmt.Errorf("%s is not a supported device", device)
+}
+
+// hidrawID reads the USB vendor and product id of a hidraw node from sysfs
+func hidrawID(node string) (uint32, uint32, error) {
+	uevent, err := os.ReadFile(filepath.Join("/sys/class/hidraw", filepath.Base(node), "device/uevent"))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read device info of %s: %w", node, err)
+	}
+	for _, line := range strings.Split(string(uevent), "\n") {
+		// e.g. HID_ID=0003:000004D8:0000F372
+		if id, ok := strings.CutPrefix(line, "HID_ID="); ok {
+			var bus, vendorID, productID uint32
+			if _, err := fmt.Sscanf(id, "%x:%x:%x", &bus, &vendorID, &productID); err != nil {
+				return 0, 0, fmt.Errorf("unexpected HID_ID %q of %s", id, node)
+			}
+			return vendorID, productID, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no HID_ID for %s", node)
+}
+
+func (b *busylightDisplay) Name() string {
+	return b.model.name
+}
+
+func (b *busylightDisplay) Show(ctx context.Context, view DisplayView) error {
+	b.last = b.model.report(view.Color, slices.Contains(busylightBlinkStates, view.Signal))
+	return b.send(b.last)
+}
+
+// Refresh resends the last report, keeping lights with a watchdog such as the Kuando switched on
+func (b *busylightDisplay) Refresh() error {
+	if b.last == nil {
+		return nil
+	}
+	return b.send(b.last)
+}
+
+func (b *busylightDisplay) send(report []byte) error {
+	f, err := os.OpenFile(b.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", b.path, err)
+	}
+	defer f.Close()
+
+	if b.model.feature {
+		return hidSetFeature(f, report)
+	}
+	if _, err := f.Write(report); err != nil {
+		return fmt.Errorf("failed to write to %s: %w", b.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// hidSetFeature sends a feature report with the HIDIOCSFEATURE ioctl of hidraw
+func hidSetFeature(f *os.File, report []byte) error {
+	// _IOC(_IOC_WRITE|_IOC_READ, 'H', 0x06, len)
+	req := uintptr(3<<30 | len(report)<<16 | 'H'<<8 | 0x06)
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(&report[0]))); errno != 0 {
+		return fmt.Errorf("failed to send feature report to %s: %w", f.Name(), errno)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// hidSetFeature is only implemented for Linux hidraw
+func hidSetFeature(f *os.File, _ []byte) error {
+	return fmt.Errorf("feature reports to %s are only supported on Linux", f.Name())
+}
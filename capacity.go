@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var capacitySeverity = "warning"     // os.Getenv("CAPACITY_SEVERITY") // critical, warning or info
+var capacityPods = map[string]bool{} // namespace/name of the pods of capacityIssues
+var capacityIssues []Issue           // issues of the last successful check, kept while the events can't be listed
+
+// checkCapacity pairs FailedScheduling events with cluster-autoscaler NotTriggerScaleUp or
+// Karpenter warning events for the same pod, meaning the cluster can't grow to fit the workload.
+// While the events can't be listed the issues of the last successful check are kept.
+func checkCapacity(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		HandleError("capacity", "Error fetching events:", fmt.Errorf("%w: %v", ErrKubeAPI, err))
+		return capacityIssues
+	}
+	integrationOK("capacity")
+
+	since := time.Now().Add(-10 * time.Minute)
+	failedScheduling := map[string]bool{}
+	scaleUpFailures := map[string]string{}
+	for _, e := range events.Items {
+		if e.InvolvedObject.Kind != "Pod" || eventLastSeen(e).Before(since) {
+			continue
+		}
+		pod := e.Namespace + "/" + e.InvolvedObject.Name
+		switch {
+		case e.Reason == "FailedScheduling" && e.Source.Component != "karpenter":
+			failedScheduling[pod] = true
+		case e.Reason == "NotTriggerScaleUp":
+			scaleUpFailures[pod] = e.Message
+		case e.Type == v1.EventTypeWarning && (e.Source.Component == "karpenter" || e.ReportingController == "karpenter"):
+			scaleUpFailures[pod] = e.Message
+		}
+	}
+
+	var issues []Issue
+	pods := map[string]bool{}
+	for pod, msg := range scaleUpFailures {
+		if !failedScheduling[pod] {
+			continue
+		}
+		pods[pod] = true
+		namespace, name, _ := strings.Cut(pod, "/")
+		issues = append(issues, Issue{Key: "capacity/" + pod, Type: "Capacity", Severity: capacitySeverity, Message: fmt.Sprintf("%s can't be scheduled and the cluster can't scale up: %s", pod, msg), Timestamp: time.Now(),
+			Namespace: namespace, Kind: "Pod", Name: name, Reason: "FailedScheduling"})
+	}
+	capacityPods, capacityIssues = pods, issues
+	return issues
+}
+
+// excludeCapacityPods drops the pod issues and FailedScheduling events of pods already reported by
+// checkCapacity, so a pod the cluster can't scale up for isn't reported as a generic pod issue too
+func excludeCapacityPods(podIssues, eventIssues []Issue) ([]Issue, []Issue) {
+	blocked := func(i Issue) bool { return i.Kind == "Pod" && capacityPods[i.Namespace+"/"+i.Name] }
+	podIssues = slices.DeleteFunc(podIssues, blocked)
+	eventIssues = slices.DeleteFunc(eventIssues, func(i Issue) bool { return i.Reason == "FailedScheduling" && blocked(i) })
+	return podIssues, eventIssues
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var slackSigningSecret = ""    // os.Getenv("SLACK_SIGNING_SECRET") // enables the /webhooks/slack slash command endpoint
+var telegramWebhookSecret = "" // os.Getenv("TELEGRAM_WEBHOOK_SECRET") // enables the /webhooks/telegram bot endpoint
+var telegramChatIds []int64    // os.Getenv("TELEGRAM_CHAT_IDS") // chats allowed to send commands, empty allows all
+
+const chatHelp = "Commands: status, ack [issue key], mute <duration>, unmute, recheck"
+
+// chatCommand runs a ChatOps command such as "status", "ack pod/ns/name" or "mute 2h" through
+// the same user actions as the API and input devices, and returns the reply
+func chatCommand(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return chatHelp
+	}
+	command, args := strings.ToLower(fields[0]), fields[1:]
+
+	var action, reply string
+	switch command {
+	case "status":
+		return chatStatus()
+	case "ack":
+		if len(args) > 0 {
+			action, reply = "ack "+args[0], fmt.Sprintf("Acknowledged %s until it clears", args[0])
+		} else {
+			action, reply = "ack", "Acknowledged the current state until it changes"
+		}
+	case "mute", "snooze":
+		if len(args) == 0 {
+			action, reply = "snooze", fmt.Sprintf("Muted for %d minutes", snoozeMinutes)
+			break
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return fmt.Sprintf("Invalid duration %q, e.g. 30m or 2h", args[0])
+		}
+		action, reply = "snooze "+d.String(), fmt.Sprintf("Muted for %s", d)
+	case "unmute":
+		action, reply = "unmute", "Unmuted"
+	case "recheck":
+		action, reply = "recheck", "Checking now"
+	default:
+		return chatHelp
+	}
+	if !requestUserAction(action) {
+		return fmt.Sprintf("Couldn't run %s: too many pending actions, try again in a moment", command)
+	}
+	return reply
+}
+
+// chatStatus summarizes the most recent health report
+func chatStatus() string {
+	report := latestReport()
+	if report == nil {
+		return "No health report yet"
+	}
+	lines := []string{fmt.Sprintf("State: %s (%d issues, %d open PRs)", report.ClusterState, report.TotalIssues, report.OpenPRCount)}
+	// Pods of a workload are summarized by the offender lines instead of one line each
+	issues := slices.Concat(report.NodeIssues, report.PodIssues, report.EventIssues, report.CheckIssues)
+	for _, o := range report.TopOffenders {
+		lines = append(lines, "• "+o.Message)
+		issues = slices.DeleteFunc(issues, func(issue Issue) bool {
+			return issue.Owner == o.Owner && issue.Namespace == o.Namespace && issue.Reason == o.Reason
+		})
+	}
+	for i, issue := range issues {
+		if i == 10 {
+			lines = append(lines, fmt.Sprintf("… and %d more", len(issues)-i))
+			break
+		}
+		line := fmt.Sprintf("• %s: %s", issue.Key, issue.Message)
+		if issue.Runbook != "" {
+			line += " (runbook: " + issue.Runbook + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// slackCommandHandler serves Slack slash commands, e.g. "/clusterbulb status", after validating
+// the request signature; the reply is posted to the channel
+func slackCommandHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if !validSlackSignature(body, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"), slackSigningSecret, time.Now()) {
+		log.Printf("Rejected Slack command with invalid signature from %s", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("Slack command from %s: %s", form.Get("user_name"), form.Get("text"))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"response_type": "in_channel", "text": chatCommand(form.Get("text"))})
+}
+
+// validSlackSignature checks an X-Slack-Signature header against the HMAC of the timestamp and
+// body, rejecting requests older than five minutes to prevent replays
+func validSlackSignature(body []byte, timestamp, signature, secret string, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || now.Sub(time.Unix(ts, 0)).Abs() > 5*time.Minute {
+		return false
+	}
+	sig, ok := strings.CutPrefix(signature, "v0=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// telegramUpdate holds the fields of interest of a Telegram bot update
+type telegramUpdate struct {
+	Message *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+// telegramWebhookHandler serves Telegram bot commands such as "/status" or "/clusterbulb mute 2h";
+// the reply is returned as a sendMessage call in the webhook response, so no bot token is needed
+func telegramWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !hmac.Equal([]byte(r.Header.Get("X-Telegram-Bot-Api-Secret-Token")), []byte(telegramWebhookSecret)) {
+		log.Printf("Rejected Telegram update with invalid secret from %s", r.RemoteAddr)
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return
+	}
+
+	var update telegramUpdate
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&update); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if update.Message == nil || !strings.HasPrefix(update.Message.Text, "/") {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	chatId := update.Message.Chat.ID
+	if len(telegramChatIds) > 0 && !slices.Contains(telegramChatIds, chatId) {
+		log.Printf("Ignoring Telegram command from chat %d", chatId)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	log.Printf("Telegram command from chat %d: %s", chatId, update.Message.Text)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"method": "sendMessage", "chat_id": chatId, "text": chatCommand(telegramCommandText(update.Message.Text))})
+}
+
+// telegramCommandText turns "/clusterbulb@bot status" or "/status@bot" into "status"
+func telegramCommandText(text string) string {
+	command, rest, _ := strings.Cut(strings.TrimPrefix(text, "/"), " ")
+	command, _, _ = strings.Cut(command, "@")
+	if command == "clusterbulb" {
+		return rest
+	}
+	return strings.TrimSpace(command + " " + rest)
+}
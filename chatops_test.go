@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidSlackSignature(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	body := []byte("command=%2Fclusterbulb&text=status")
+	sign := func(ts string) string {
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("v0:" + ts + ":"))
+		mac.Write(body)
+		return "v0=" + hex.EncodeToString(mac.Sum(nil))
+	}
+	ts := strconv.FormatInt(now.Unix(), 10)
+	old := strconv.FormatInt(now.Add(-10*time.Minute).Unix(), 10)
+
+	if !validSlackSignature(body, ts, sign(ts), "secret", now) {
+		t.Error("valid signature rejected")
+	}
+	if validSlackSignature(body, ts, sign(ts), "other", now) {
+		t.Error("signature with the wrong secret accepted")
+	}
+	if validSlackSignature(body, old, sign(old), "secret", now) {
+		t.Error("replayed request accepted")
+	}
+}
+
+func TestPruneAckedIssues(t *testing.T) {
+	resetClusterGlobals(t)
+	defer func(alerts []Issue) {
+		ackedIssues, securityAlerts = map[string]bool{}, alerts
+	}(securityAlerts)
+	ackedIssues = map[string]bool{"pod/prod/api": true, "octo/app#12": true, "security/dependabot/3": true}
+	pullRequests = []Issue{{Key: "octo/app#12", Type: "PullRequest"}}
+	securityAlerts = []Issue{{Key: "security/dependabot/3", Type: "SecurityAlert"}}
+
+	// Acknowledged PRs and alerts stay acknowledged while they are open, the cleared pod is forgotten
+	evaluateCluster(context.Background(), fake.NewSimpleClientset())
+	if len(ackedIssues) != 2 || !ackedIssues["octo/app#12"] || !ackedIssues["security/dependabot/3"] {
+		t.Errorf("acked issues = %v, want the open PR and security alert kept", ackedIssues)
+	}
+}
+
+func TestChatCommand(t *testing.T) {
+	defer func() {
+		for len(userActions) > 0 {
+			<-userActions
+		}
+	}()
+
+	tests := []struct {
+		text, action string
+	}{
+		{"ack pod/prod/api-0", "ack pod/prod/api-0"},
+		{"mute 2h", "snooze 2h0m0s"},
+		{"MUTE", "snooze"},
+		{"unmute", "unmute"},
+	}
+	for _, tt := range tests {
+		chatCommand(tt.text)
+		if got := <-userActions; got != tt.action {
+			t.Errorf("chatCommand(%q) queued %q, want %q", tt.text, got, tt.action)
+		}
+	}
+
+	chatCommand("mute soon")
+	chatCommand("reboot")
+	if len(userActions) != 0 {
+		t.Errorf("invalid commands queued %d actions", len(userActions))
+	}
+
+	// A full queue drops the action, and the reply says so
+	for range cap(userActions) {
+		userActions <- "recheck"
+	}
+	if reply := chatCommand("ack"); !strings.Contains(reply, "too many pending actions") {
+		t.Errorf("reply with a full queue = %q, want the action reported as dropped", reply)
+	}
+	rec := httptest.NewRecorder()
+	recheckHandler(rec, httptest.NewRequest("POST", "/api/v1/recheck", nil))
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("recheck with a full queue = %d, want 429", rec.Code)
+	}
+	if got := telegramCommandText("/clusterbulb@cluster_bot mute 2h"); got != "mute 2h" {
+		t.Errorf("telegramCommandText() = %q", got)
+	}
+	if got := telegramCommandText("/status@cluster_bot"); got != "status" {
+		t.Errorf("telegramCommandText() = %q", got)
+	}
+}
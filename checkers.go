@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// checker is an optional health source polled by clusterChecks alongside the
+// core node, pod and event checks
+type checker struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context, clientset kubernetes.Interface) []Issue
+	lastRun  time.Time
+	issues   []Issue
+	timedOut bool // the last run did not finish in time
+}
+
+// checkers holds every enabled checker, in registration order
+var checkers []*checker
+
+// registerChecker enables a checker that runs at most once per interval
+func registerChecker(name string, interval time.Duration, run func(ctx context.Context, clientset kubernetes.Interface) []Issue) {
+	checkers = append(checkers, &checker{name: name, interval: interval, run: run})
+}
+
+// runCheckers runs every checker whose interval has elapsed and returns the
+// latest issues of all checkers, reusing cached results for the others. A checker
+// that doesn't finish within its interval keeps its previous issues, as its partial
+// result would clear the issues it didn't get to, and is retried on the next cycle.
+func runCheckers(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var issues []Issue
+	for _, c := range checkers {
+		if time.Since(c.lastRun) >= c.interval {
+			checkCtx, cancel := context.WithTimeout(ctx, c.interval)
+			found := c.run(checkCtx, clientset)
+			err := checkCtx.Err()
+			cancel()
+			if err != nil {
+				HandleError(c.name, fmt.Sprintf("Check %s did not finish:", c.name), err)
+				c.timedOut = true
+			} else {
+				if c.timedOut {
+					integrationOK(c.name)
+					c.timedOut = false
+				}
+				c.issues = found
+				c.lastRun = time.Now()
+			}
+		}
+		issues = append(issues, c.issues...)
+	}
+	return issues
+}
+
+// issueTypeStates maps check issue types to the bulb state they raise instead of issues_detected
+var issueTypeStates = map[string]Signal{}
+
+// issueState returns the signal raised by an actionable check issue
+func issueState(issue Issue) Signal {
+	if state, ok := issueTypeStates[issue.Type]; ok {
+		return state
+	}
+	return SignalIssuesDetected
+}
+
+// isActionable reports whether an issue should turn the bulb red; info issues are only reported
+func isActionable(issue Issue) bool {
+	return issue.Severity != "info"
+}
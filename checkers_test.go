@@ -0,0 +1,316 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// crdAPI serves the custom resource lists of one group version; a resource whose list is
+// empty fails with 503, and lists may be changed between checks
+func crdAPI(t *testing.T, groupVersion string, lists map[string]string) *kubernetes.Clientset {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/apis/"+groupVersion {
+			var resources []string
+			for name := range lists {
+				resources = append(resources, fmt.Sprintf(`{"name":%q,"namespaced":true,"kind":"x","verbs":["list"]}`, name))
+			}
+			fmt.Fprintf(w, `{"kind":"APIResourceList","groupVersion":%q,"resources":[%s]}`, groupVersion, strings.Join(resources, ","))
+			return
+		}
+		list, ok := lists[strings.TrimPrefix(r.URL.Path, "/apis/"+groupVersion+"/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if list == "" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, list)
+	}))
+	t.Cleanup(srv.Close)
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientset
+}
+
+func TestTimedOutCheckerKeepsPreviousIssues(t *testing.T) {
+	defer func(saved []*checker) {
+		checkers = saved
+		delete(integrations, "slow")
+	}(checkers)
+	checkers = nil
+
+	slow := false
+	registerChecker("slow", 20*time.Millisecond, func(ctx context.Context, _ kubernetes.Interface) []Issue {
+		if !slow {
+			return []Issue{{Key: "slow/a"}, {Key: "slow/b"}}
+		}
+		// Only part of the issues were found before the deadline
+		<-ctx.Done()
+		return []Issue{{Key: "slow/a"}}
+	})
+
+	if issues := runCheckers(context.Background(), nil); len(issues) != 2 {
+		t.Fatalf("issues = %v, want 2", issues)
+	}
+	lastRun := checkers[0].lastRun
+
+	slow = true
+	time.Sleep(25 * time.Millisecond)
+	if issues := runCheckers(context.Background(), nil); len(issues) != 2 {
+		t.Errorf("issues after a timeout = %v, want the previous 2 kept", issues)
+	}
+	if !checkers[0].lastRun.Equal(lastRun) {
+		t.Errorf("lastRun = %v, want %v unchanged so the check is retried", checkers[0].lastRun, lastRun)
+	}
+	if h := integrationsSnapshot()["slow"]; h.Failures != 1 {
+		t.Errorf("slow failures = %d, want the timeout reported", h.Failures)
+	}
+
+	slow = false
+	runCheckers(context.Background(), nil)
+	if h := integrationsSnapshot()["slow"]; h.Failures != 0 || h.LastSuccess.IsZero() {
+		t.Errorf("slow = %+v, want recovered after finishing in time", h)
+	}
+}
+
+func TestAlertmanagerOutageKeepsAlerts(t *testing.T) {
+	defer func(u string) {
+		alertmanagerUrl, alertmanagerIssues = u, nil
+		delete(integrations, "alertmanager")
+	}(alertmanagerUrl)
+	delete(integrations, "alertmanager")
+
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `[{"fingerprint":"f1","labels":{"alertname":"NodeDown","severity":"critical"},"annotations":{"summary":"node-1 is down"}}]`)
+	}))
+	defer srv.Close()
+	alertmanagerUrl = srv.URL
+
+	if issues := checkAlertmanager(context.Background(), nil); len(issues) != 1 || issues[0].Severity != "critical" {
+		t.Fatalf("issues = %+v, want the firing alert", issues)
+	}
+	down = true
+	if issues := checkAlertmanager(context.Background(), nil); len(issues) != 1 || issues[0].Key != "alert/NodeDown/f1" {
+		t.Errorf("issues during an outage = %+v, want the last alerts kept", issues)
+	}
+	if h := integrationsSnapshot()["alertmanager"]; h.Failures != 1 {
+		t.Errorf("alertmanager = %+v, want the failure reported", h)
+	}
+}
+
+func TestFailedPromQLKeepsLastResult(t *testing.T) {
+	defer func(p PrometheusConfig) {
+		config.Prometheus, promqlIssues = p, map[string][]Issue{}
+		delete(integrations, "prometheus")
+	}(config.Prometheus)
+	delete(integrations, "prometheus")
+
+	down := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down && r.URL.Query().Get("query") == "disk_free" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		value := "0.05"
+		if r.URL.Query().Get("query") == "up" {
+			value = "1"
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"instance":"node-1"},"value":[0,"%s"]}]}}`, value)
+	}))
+	defer srv.Close()
+	config.Prometheus = PrometheusConfig{URL: srv.URL, Queries: []PromQLCheck{
+		{Name: "disk", Query: "disk_free", Operator: "<", Threshold: 0.1},
+		{Name: "up", Query: "up", Operator: "<", Threshold: 1},
+	}}
+
+	if issues := checkPromQL(context.Background(), nil); len(issues) != 1 || issues[0].Key != `promql/disk{instance="node-1"}` {
+		t.Fatalf("issues = %+v, want the low disk", issues)
+	}
+	down = true
+	if issues := checkPromQL(context.Background(), nil); len(issues) != 1 || issues[0].Key != `promql/disk{instance="node-1"}` {
+		t.Errorf("issues while the query fails = %+v, want its last result kept", issues)
+	}
+	if h := integrationsSnapshot()["prometheus"]; h.Failures != 1 {
+		t.Errorf("prometheus = %+v, want the failure reported", h)
+	}
+}
+
+func TestFailedTrivyListKeepsVulnerabilities(t *testing.T) {
+	defer func() {
+		trivyIssues, vulnerabilitySummary = nil, ""
+		delete(integrations, "trivy")
+	}()
+	delete(integrations, "trivy")
+
+	lists := map[string]string{"vulnerabilityreports": `{"items":[{"metadata":{"name":"r","namespace":"default"},"report":{"artifact":{"repository":"nginx"},"summary":{"criticalCount":2}}}]}`}
+	clientset := crdAPI(t, "aquasecurity.github.io/v1alpha1", lists)
+	if issues := checkTrivy(context.Background(), clientset); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the critical CVEs", issues)
+	}
+	lists["vulnerabilityreports"] = ""
+	if issues := checkTrivy(context.Background(), clientset); len(issues) != 1 || issues[0].Key != "trivy/critical" {
+		t.Errorf("issues while the reports can't be listed = %+v, want the last result kept", issues)
+	}
+	if h := integrationsSnapshot()["trivy"]; h.Failures != 1 {
+		t.Errorf("trivy = %+v, want the failure reported", h)
+	}
+}
+
+func TestFailedPolicyListKeepsViolations(t *testing.T) {
+	defer func() {
+		policyReportIssues, gatekeeperIssues = nil, nil
+		delete(integrations, "kyverno")
+		delete(integrations, "gatekeeper")
+	}()
+	delete(integrations, "kyverno")
+	delete(integrations, "gatekeeper")
+
+	reports := map[string]string{
+		"policyreports":        `{"items":[{"metadata":{"namespace":"default"},"results":[{"policy":"require-labels","result":"fail"}]}]}`,
+		"clusterpolicyreports": `{"items":[]}`,
+	}
+	kyverno := crdAPI(t, "wgpolicyk8s.io/v1alpha2", reports)
+	if issues := checkPolicyReports(context.Background(), kyverno); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the policy violation", issues)
+	}
+	// Listing only the cluster reports would clear the namespaced violation
+	reports["policyreports"] = ""
+	if issues := checkPolicyReports(context.Background(), kyverno); len(issues) != 1 || issues[0].Key != "policy/require-labels" {
+		t.Errorf("issues while a report list fails = %+v, want the last result kept", issues)
+	}
+
+	constraints := map[string]string{"k8srequiredlabels": `{"items":[{"kind":"K8sRequiredLabels","metadata":{"name":"owner"},"status":{"totalViolations":3}}]}`}
+	gatekeeper := crdAPI(t, "constraints.gatekeeper.sh/v1beta1", constraints)
+	if issues := checkGatekeeperConstraints(context.Background(), gatekeeper); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the constraint violation", issues)
+	}
+	constraints["k8srequiredlabels"] = ""
+	if issues := checkGatekeeperConstraints(context.Background(), gatekeeper); len(issues) != 1 || issues[0].Key != "policy/K8sRequiredLabels/owner" {
+		t.Errorf("issues while the constraints can't be listed = %+v, want the last result kept", issues)
+	}
+
+	health := integrationsSnapshot()
+	if health["kyverno"].Failures != 1 || health["gatekeeper"].Failures != 1 {
+		t.Errorf("health = %+v, want one failure each for kyverno and gatekeeper", health)
+	}
+}
+
+func TestFailedStorageListKeepsFaultedVolumes(t *testing.T) {
+	defer func() {
+		longhornIssues, cephIssues = nil, nil
+		delete(integrations, "longhorn")
+		delete(integrations, "ceph")
+	}()
+	delete(integrations, "longhorn")
+	delete(integrations, "ceph")
+
+	volumes := map[string]string{"volumes": `{"items":[{"metadata":{"name":"pvc-1"},"status":{"robustness":"faulted"}}]}`}
+	longhorn := crdAPI(t, "longhorn.io/v1beta2", volumes)
+	if issues := checkLonghornVolumes(context.Background(), longhorn); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the faulted volume", issues)
+	}
+	volumes["volumes"] = ""
+	if issues := checkLonghornVolumes(context.Background(), longhorn); len(issues) != 1 || issues[0].Severity != "critical" {
+		t.Errorf("issues while the volumes can't be listed = %+v, want the faulted volume kept", issues)
+	}
+
+	clusters := map[string]string{"cephclusters": `{"items":[{"metadata":{"name":"ceph","namespace":"rook"},"status":{"ceph":{"health":"HEALTH_ERR"}}}]}`}
+	ceph := crdAPI(t, "ceph.rook.io/v1", clusters)
+	if issues := checkCephClusters(context.Background(), ceph); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the unhealthy cluster", issues)
+	}
+	clusters["cephclusters"] = ""
+	if issues := checkCephClusters(context.Background(), ceph); len(issues) != 1 || issues[0].Key != "ceph/rook/ceph" {
+		t.Errorf("issues while the clusters can't be listed = %+v, want the unhealthy cluster kept", issues)
+	}
+
+	health := integrationsSnapshot()
+	if health["longhorn"].Failures != 1 || health["ceph"].Failures != 1 {
+		t.Errorf("health = %+v, want one failure each for longhorn and ceph", health)
+	}
+}
+
+func TestFailedServiceListKeepsPendingLoadBalancers(t *testing.T) {
+	defer func() {
+		loadBalancerIssues = nil
+		delete(integrations, "loadbalancers")
+	}()
+	delete(integrations, "loadbalancers")
+
+	clientset := fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	})
+	if issues := checkLoadBalancers(context.Background(), clientset); len(issues) != 1 {
+		t.Fatalf("issues = %+v, want the pending LoadBalancer", issues)
+	}
+	clientset.PrependReactor("list", "services", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver timeout")
+	})
+	if issues := checkLoadBalancers(context.Background(), clientset); len(issues) != 1 || issues[0].Key != "service/default/web" {
+		t.Errorf("issues while the services can't be listed = %+v, want the last result kept", issues)
+	}
+	if h := integrationsSnapshot()["loadbalancers"]; h.Failures != 1 {
+		t.Errorf("loadbalancers = %+v, want the failure reported", h)
+	}
+}
+
+func TestCapacityPodsExcludedInSameCycle(t *testing.T) {
+	resetClusterGlobals(t)
+	t.Cleanup(func() {
+		capacityPods, capacityIssues = map[string]bool{}, nil
+		delete(integrations, "capacity")
+	})
+	registerChecker("capacity", 30*time.Second, checkCapacity)
+
+	now := metav1.NewTime(time.Now())
+	pod := v1.ObjectReference{Kind: "Pod", Namespace: "default", Name: "big"}
+	clientset := fake.NewSimpleClientset(
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "big", Namespace: "default"}, Status: v1.PodStatus{Phase: v1.PodPending}},
+		&v1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e1", Namespace: "default"}, InvolvedObject: pod, Type: v1.EventTypeWarning,
+			Reason: "FailedScheduling", Message: "0/3 nodes are available", LastTimestamp: now},
+		&v1.Event{ObjectMeta: metav1.ObjectMeta{Name: "e2", Namespace: "default"}, InvolvedObject: pod, Type: v1.EventTypeNormal,
+			Reason: "NotTriggerScaleUp", Message: "max node group size reached", LastTimestamp: now, Source: v1.EventSource{Component: "cluster-autoscaler"}},
+	)
+
+	// The first cycle already reports the pod only as a capacity issue
+	report := evaluateCluster(context.Background(), clientset)
+	if len(report.PodIssues) != 0 || len(report.EventIssues) != 0 {
+		t.Errorf("pod issues = %+v, event issues = %+v, want none for the capacity-blocked pod", report.PodIssues, report.EventIssues)
+	}
+	if len(report.CheckIssues) != 1 || report.CheckIssues[0].Key != "capacity/default/big" {
+		t.Errorf("check issues = %+v, want the capacity issue", report.CheckIssues)
+	}
+
+	// A failed event list keeps the capacity issue instead of clearing it
+	clientset.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver timeout")
+	})
+	if issues := checkCapacity(context.Background(), clientset); len(issues) != 1 || !capacityPods["default/big"] {
+		t.Errorf("issues while the events can't be listed = %+v, want the last result kept", issues)
+	}
+}
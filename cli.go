@@ -0,0 +1,212 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// cliCommands are the client subcommands; without one the binary runs the monitor
+var cliCommands = map[string]func(c *cliClient, args []string) error{
+	"status": cliStatus,
+	"issues": cliIssues,
+	"lights": cliLights,
+	"tui":    cliTUI,
+	"tray":   cliTray,
+}
+
+// cliClient queries the status API of a running instance
+type cliClient struct {
+	server string
+	token  string
+	color  bool
+	out    io.Writer
+}
+
+// runCLI runs a client subcommand of cliCommands and returns the exit code
+func runCLI(args []string) int {
+	command := cliCommands[args[0]]
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	c := &cliClient{out: os.Stdout}
+	flags.StringVar(&c.server, "server", cliEnv("CLUSTERBULB_SERVER", "http://localhost:8080"), "base URL of the clusterbulb HTTP server")
+	flags.StringVar(&c.token, "token", os.Getenv("CLUSTERBULB_TOKEN"), "API_TOKEN of the server")
+	noColor := flags.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colors")
+	var rest []string
+	if args[0] == "issues" {
+		// Filters are passed through to /api/v1/issues
+		for _, param := range []string{"type", "namespace", "kind", "severity", "reason"} {
+			flags.Func(param, "only issues with this "+param, func(v string) error {
+				rest = append(rest, param+"="+url.QueryEscape(v))
+				return nil
+			})
+		}
+	}
+	if args[0] == "tui" {
+		tuiFlags(flags)
+	}
+	if args[0] == "tray" {
+		flags.DurationVar(&trayInterval, "interval", trayInterval, "refresh interval")
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	c.server = strings.TrimRight(c.server, "/")
+	c.color = !*noColor
+
+	// Without a monitor to attach to, the terminal UI runs the checks itself
+	if args[0] == "tui" {
+		attach := os.Getenv("CLUSTERBULB_SERVER") != ""
+		flags.Visit(func(f *flag.Flag) { attach = attach || f.Name == "server" })
+		if !attach {
+			tuiEngine = c
+			runMonitor()
+			return 0
+		}
+	}
+
+	if err := command(c, rest); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+// cliEnv returns the environment variable or def when it is unset
+func cliEnv(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// get fetches an API path and decodes the JSON response into v
+func (c *cliClient) get(path string, v interface{}) error {
+	req, err := http.NewRequest("GET", c.server+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	return httpGetJSON("clusterbulb", req, v)
+}
+
+// paint colors text with the bulb color of the signal using 24-bit ANSI colors
+func (c *cliClient) paint(signal Signal, text string) string {
+	color, ok := stateColor(signal)
+	if !c.color || !ok {
+		return text
+	}
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", color[0], color[1], color[2], text)
+}
+
+// cliStatus prints the cluster state with one colored dot per signal and the issue counts
+func cliStatus(c *cliClient, _ []string) error {
+	var report HealthReport
+	if err := c.get("/api/v1/report", &report); err != nil {
+		return err
+	}
+
+	var parts []string
+	for _, s := range strings.Split(report.ClusterState, "|") {
+		parts = append(parts, c.paint(Signal(s), "● "+s))
+	}
+	fmt.Fprintln(c.out, strings.Join(parts, "  "))
+
+	fmt.Fprintf(c.out, "Issues: %d  Open PRs: %d", report.TotalIssues, report.OpenPRCount)
+	if report.CIState != "" {
+		fmt.Fprintf(c.out, "  CI: %s", report.CIState)
+	}
+	if report.Score > 0 {
+		fmt.Fprintf(c.out, "  Score: %d", report.Score)
+	}
+	if report.GitHubQuota != nil {
+		fmt.Fprintf(c.out, "  GitHub quota: %d", report.GitHubQuota.Remaining)
+	}
+	fmt.Fprintf(c.out, "\nChecked %s ago\n", time.Since(report.Timestamp).Round(time.Second))
+
+	for _, comp := range report.Components {
+		fmt.Fprintf(c.out, "%s %s: %s\n", c.paint(Signal(strings.Split(comp.State, "|")[0]), "●"), comp.Name, comp.Status)
+	}
+
+	// Integrations show when they last succeeded, so a stale PR light can be told apart from no PRs
+	names := slices.Sorted(maps.Keys(report.Integrations))
+	for _, name := range names {
+		h := report.Integrations[name]
+		line := fmt.Sprintf("%s: last success %s", name, ago(h.LastSuccess))
+		if h.LastError != "" {
+			line += fmt.Sprintf(", last error %s: %s", ago(h.LastFailure), h.LastError)
+		}
+		if h.Degraded {
+			line = c.paint(SignalIssuesDetected, line+" (degraded)")
+		}
+		fmt.Fprintln(c.out, line)
+	}
+	return nil
+}
+
+// cliIssues prints the active issues as a table with a dot in the color of each issue's state
+func cliIssues(c *cliClient, filters []string) error {
+	path := "/api/v1/issues"
+	if len(filters) > 0 {
+		path += "?" + strings.Join(filters, "&")
+	}
+	var issues []Issue
+	if err := c.get(path, &issues); err != nil {
+		return err
+	}
+	if len(issues) == 0 {
+		fmt.Fprintln(c.out, c.paint(SignalHealthy, "No active issues"))
+		return nil
+	}
+
+	// Colors are added in front of the aligned lines, tabwriter would count the escape codes as text
+	var table strings.Builder
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAMESPACE\tNAME\tREASON\tAGE\tMESSAGE")
+	for _, issue := range issues {
+		age := "-"
+		if !issue.FirstSeen.IsZero() {
+			age = time.Since(issue.FirstSeen).Round(time.Second).String()
+		}
+		name := issue.Name
+		if name == "" {
+			name = issue.Key
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", issue.Type, orDash(issue.Namespace), name, orDash(issue.Reason), age, issue.Message)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	fmt.Fprintln(c.out, "  "+lines[0])
+	for i, line := range lines[1:] {
+		fmt.Fprintln(c.out, c.paint(issueState(issues[i]), "●")+" "+line)
+	}
+	return nil
+}
+
+// ago formats the time since t, or "never" for the zero time
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
+
+// orDash returns "-" for empty table cells
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cliTestServer serves a fixed report and issues and the transitions of the history, rejecting
+// requests without the token
+func cliTestServer(t *testing.T, report HealthReport, issues []Issue) (*httptest.Server, *[]string) {
+	t.Helper()
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/report":
+			json.NewEncoder(w).Encode(report)
+		case "/api/v1/issues":
+			queries = append(queries, r.URL.RawQuery)
+			json.NewEncoder(w).Encode(issues)
+		case "/api/v1/transitions":
+			transitionsHandler(w, r)
+		case "/api/v1/recheck":
+			recheckHandler(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &queries
+}
+
+func TestCLIStatus(t *testing.T) {
+	srv, _ := cliTestServer(t, HealthReport{
+		Timestamp:    time.Now(),
+		ClusterState: "pull_requests_open|issues_detected",
+		TotalIssues:  3,
+		OpenPRCount:  1,
+		CIState:      "failure",
+		Components:   []ComponentHealth{{Name: "media", State: "issues_detected", Status: "outage"}},
+		Integrations: map[string]IntegrationHealth{"github": {Degraded: true, Failures: 5, LastError: "rate limited", LastFailure: time.Now()}},
+	}, nil)
+
+	var out strings.Builder
+	c := &cliClient{server: srv.URL, token: "secret", out: &out}
+	if err := cliStatus(c, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"● pull_requests_open  ● issues_detected",
+		"Issues: 3  Open PRs: 1  CI: failure",
+		"● media: outage",
+		"github: last success never, last error 0s ago: rate limited (degraded)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+
+	c.token = "wrong"
+	if err := cliStatus(c, nil); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("status with a wrong token: %v, want 401", err)
+	}
+}
+
+func TestCLIIssues(t *testing.T) {
+	srv, queries := cliTestServer(t, HealthReport{}, []Issue{
+		{Key: "pod/prod/api", Type: "Pod", Namespace: "prod", Name: "api", Reason: "CrashLoopBackOff", Message: "Pod prod/api is crash looping", FirstSeen: time.Now().Add(-time.Minute)},
+		{Key: "egress", Type: "Egress", Message: "No egress"},
+	})
+
+	var out strings.Builder
+	c := &cliClient{server: srv.URL, token: "secret", color: true, out: &out}
+	if err := cliIssues(c, []string{"namespace=prod", "type=Pod"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(*queries) != 1 || (*queries)[0] != "namespace=prod&type=Pod" {
+		t.Errorf("queries = %v, want the filters passed through", *queries)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], "TYPE") {
+		t.Fatalf("table:\n%s", out.String())
+	}
+	if !strings.Contains(lines[1], "\x1b[38;2;") || !strings.Contains(lines[1], "prod") || !strings.Contains(lines[1], "CrashLoopBackOff") {
+		t.Errorf("pod line = %q, want a colored dot, the namespace and the reason", lines[1])
+	}
+	if !strings.Contains(lines[2], "Egress  -") || !strings.Contains(lines[2], "egress") {
+		t.Errorf("egress line = %q, want dashes for empty cells and the key as name", lines[2])
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var nodeClockSkewSeconds = 5 // os.Getenv("NODE_CLOCK_SKEW_SECONDS") // flag nodes whose clock is off by more than this
+
+// nodeLeaseRenewInterval is how often kubelets renew their node lease (25% of the 40s lease duration)
+const nodeLeaseRenewInterval = 10 * time.Second
+
+// checkClockSkew compares the renew time of each node lease, which the kubelet stamps with its
+// own clock, against the monitor's clock. A renewal from the future means the node clock is ahead;
+// one older than the renew interval, on a lease that hasn't expired yet, means it is behind.
+func checkClockSkew(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	leases, err := clientset.CoordinationV1().Leases("kube-node-lease").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching node leases: %v", err)
+		return nil
+	}
+
+	threshold := time.Duration(nodeClockSkewSeconds) * time.Second
+	now := time.Now()
+	var issues []Issue
+	for _, lease := range leases.Items {
+		if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		age := now.Sub(lease.Spec.RenewTime.Time)
+		duration := time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+
+		var skew time.Duration
+		switch {
+		case age < -threshold:
+			skew = -age
+		case age > nodeLeaseRenewInterval+threshold && age < duration:
+			skew = age - nodeLeaseRenewInterval
+		default:
+			// In sync, or an expired lease which the node checks already report as NotReady
+			continue
+		}
+		direction := "ahead"
+		if age > 0 {
+			direction = "behind"
+		}
+		message := fmt.Sprintf("Node %s clock is about %s %s", lease.Name, skew.Round(time.Second), direction)
+		issues = append(issues, Issue{Key: "clockskew/" + lease.Name, Type: "ClockSkew", Severity: "warning", Message: message, Timestamp: now})
+	}
+	return issues
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// Component is a named part of what the cluster runs, e.g. the media stack, made of namespaces,
+// labelled pods and workloads. Components have their own health, shown in the report, the status
+// page, notifications and optionally a light.
+type Component struct {
+	issueGroup `yaml:",inline"`
+
+	status string
+}
+
+// ComponentHealth is the health of a component in the report
+type ComponentHealth struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	Status string `json:"status"` // operational, degraded or outage
+}
+
+// validateComponents checks the names, patterns, selectors and lights of the components
+func validateComponents() configProblems {
+	var problems configProblems
+	seen := map[string]bool{}
+	for i := range config.Components {
+		config.Components[i].validate(&problems, i, "component", seen)
+	}
+	return problems
+}
+
+// componentStatus returns operational, degraded on warnings or outage on critical issues and
+// nodes down, from the actionable issues of a component
+func componentStatus(issues []Issue) string {
+	status := "operational"
+	for _, issue := range issues {
+		if !isActionable(issue) {
+			continue
+		}
+		if issue.Severity == "critical" || issue.Type == "Node" {
+			return "outage"
+		}
+		status = "degraded"
+	}
+	return status
+}
+
+// updateComponents composes the state and status of every component from its share of the issues
+func updateComponents(nodeIssues, podIssues, eventIssues, checkIssues []Issue) {
+	for i := range config.Components {
+		c := &config.Components[i]
+		state := composeState(c.inputs(nodeIssues, podIssues, eventIssues, checkIssues))
+		status := componentStatus(c.own(slices.Concat(nodeIssues, podIssues, eventIssues, checkIssues)))
+		if c.status != "" && status != c.status {
+			log.Printf("Component %s: %s -> %s", c.Name, c.status, status)
+		}
+		c.state, c.status = state, status
+	}
+}
+
+// componentHealth returns the health of every component for the report
+func componentHealth() []ComponentHealth {
+	var health []ComponentHealth
+	for _, c := range config.Components {
+		health = append(health, ComponentHealth{Name: c.Name, State: c.state.String(), Status: c.status})
+	}
+	return health
+}
+
+// componentLines lists the components that aren't operational for a notification
+func componentLines(health []ComponentHealth) []string {
+	var lines []string
+	for _, h := range health {
+		if h.Status != "operational" {
+			lines = append(lines, fmt.Sprintf("%s: %s", h.Name, h.Status))
+		}
+	}
+	return lines
+}
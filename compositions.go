@@ -0,0 +1,116 @@
+package main
+
+import (
+	"cmp"
+	"path"
+	"slices"
+)
+
+// CompositionRule replaces a combination of active states with a state of its own, e.g. a
+// dedicated color while CI is failing and the cluster has issues at the same time
+type CompositionRule struct {
+	Name     string   `yaml:"name"`
+	Match    []string `yaml:"match"`    // states that must all be active, or patterns such as pull_requests_*
+	State    string   `yaml:"state"`    // state shown first instead of the matched states
+	Color    string   `yaml:"color"`    // r,g,b, required when the state is not a built-in state
+	Priority int      `yaml:"priority"` // rules are applied highest priority first, ties in config order
+	Keep     bool     `yaml:"keep"`     // keep the matched states in the blink cycle after the composed state
+}
+
+// validateCompositions checks every rule; the colors of new states are only registered by
+// applyCompositionColors once the whole config is valid
+func validateCompositions() configProblems {
+	var problems configProblems
+	for i, rule := range config.Compositions {
+		if len(rule.Match) == 0 || rule.State == "" {
+			problems.add(i, "composition %q: match and state are required", rule.Name)
+		}
+		for _, pattern := range rule.Match {
+			if _, err := path.Match(pattern, ""); err != nil {
+				problems.add(i, "composition %q: invalid pattern %q", rule.Name, pattern)
+			}
+		}
+		if rule.Color != "" {
+			if _, err := parseRGB(rule.Color); err != nil {
+				problems.add(i, "composition %q: invalid color %q", rule.Name, rule.Color)
+				continue
+			}
+		}
+		if rule.State != "" && !stateKnown(Signal(rule.State)) {
+			problems.add(i, "composition %q: state %q needs a color", rule.Name, rule.State)
+		}
+	}
+	return problems
+}
+
+// composedColor returns the color a composition of the config gives to state
+func composedColor(state Signal) ([3]int, bool) {
+	for _, rule := range config.Compositions {
+		if Signal(rule.State) != state || rule.Color == "" {
+			continue
+		}
+		if color, err := parseRGB(rule.Color); err == nil {
+			return color, true
+		}
+	}
+	return [3]int{}, false
+}
+
+// stateKnown reports whether a state has a color, built in or given by a composition, so
+// sections such as schedules can refer to composed states before their colors are applied
+func stateKnown(state Signal) bool {
+	if _, ok := stateColor(state); ok {
+		return true
+	}
+	_, ok := composedColor(state)
+	return ok
+}
+
+// applyCompositionColors registers the colors of the composed states of a loaded config
+func applyCompositionColors() {
+	for _, rule := range config.Compositions {
+		if color, ok := composedColor(Signal(rule.State)); ok {
+			setStateColor(Signal(rule.State), color)
+		}
+	}
+}
+
+// composedState applies the configured compositions to the state
+func composedState(state ClusterState) ClusterState {
+	return applyCompositions(state, config.Compositions)
+}
+
+// applyCompositions replaces the states matched by each rule with the rule's state, highest
+// priority first; later rules see the result, so they can match a composed state as well
+func applyCompositions(state ClusterState, rules []CompositionRule) ClusterState {
+	rules = slices.Clone(rules)
+	slices.SortStableFunc(rules, func(a, b CompositionRule) int { return cmp.Compare(b.Priority, a.Priority) })
+
+	for _, rule := range rules {
+		var matched []Signal
+		for _, pattern := range rule.Match {
+			found := false
+			for _, s := range state {
+				if ok, _ := path.Match(pattern, string(s)); ok {
+					found = true
+					if !slices.Contains(matched, s) {
+						matched = append(matched, s)
+					}
+				}
+			}
+			if !found {
+				matched = nil
+				break
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+
+		rest := slices.DeleteFunc(slices.Clone(state), func(s Signal) bool {
+			return s == Signal(rule.State) || (!rule.Keep && slices.Contains(matched, s))
+		})
+		state = append(ClusterState{Signal(rule.State)}, rest...)
+	}
+	return state
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"go.yaml.in/yaml/v3"
+)
+
+var configFile = "" // os.Getenv("CONFIG_FILE") // e.g. /etc/clusterbulb/config.yaml
+
+// Config holds the settings that are too structured for environment variables
+type Config struct {
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	Probes     ProbesConfig     `yaml:"probes"`
+	Scoring    ScoringConfig    `yaml:"scoring"`
+	Schedules  []ScheduleRule   `yaml:"schedules"`
+
+	Compositions []CompositionRule `yaml:"compositions"`
+	Brightness   BrightnessConfig  `yaml:"brightness"`
+	Profiles     []Profile         `yaml:"profiles"`
+	HASensors    HASensorsConfig   `yaml:"ha_sensors"`
+	Runbooks     []Runbook         `yaml:"runbooks"`
+	Components   []Component       `yaml:"components"`
+	StatusPage   StatusPageConfig  `yaml:"status_page"`
+	Nanoleaf     NanoleafConfig    `yaml:"nanoleaf"`
+}
+
+// config is the loaded CONFIG_FILE, empty when no file is configured
+var config Config
+
+// loadConfig reads and parses the YAML config file at path. Unknown keys, values of the wrong
+// type and invalid settings are all reported at once with their line numbers.
+func loadConfig(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	configKeys = nil
+	if len(root.Content) > 0 && root.Content[0].Kind == yaml.MappingNode {
+		for i, key := range root.Content[0].Content {
+			if i%2 == 0 {
+				configKeys = append(configKeys, key.Value)
+			}
+		}
+	}
+
+	var problems configErrors
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&config); err != nil && err != io.EOF {
+		// Type errors leave the rest decoded, so the validators still run
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+		problems = append(problems, typeErr.Errors...)
+	}
+	problems = append(problems, validateConfig(&root)...)
+	if len(problems) > 0 {
+		return problems
+	}
+	applyCompositionColors()
+	for _, warning := range configOverlaps() {
+		log.Printf("Config warning: %s", warning)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.yaml.in/yaml/v3"
+)
+
+func TestLoadConfigReportsAllProblems(t *testing.T) {
+	defer func() { config = Config{} }()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	data := `compositions:
+  - name: ok
+    match: [ci_failed]
+    state: ci_failing
+  - name: broken
+    match: [ci_failed, cluster_issues]
+    state: on_fire
+    color: 300,0,0
+schedules:
+  - name: nights
+    from: "25:00"
+runbooks:
+  - url: https://wiki/any
+  - type: Pod
+    url: wiki/pod
+brightnes:
+  min: 10
+probes:
+  targets:
+    - name: api
+      url: https://api.example.com
+    - name: db
+      tcp: db
+      severity: fatal
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := loadConfig(path)
+	if err == nil {
+		t.Fatal("invalid config loaded")
+	}
+	for _, want := range []string{
+		"7 problem(s)",
+		`line 16: field brightnes not found`,
+		`line 5: compositions: composition "broken": invalid color "300,0,0"`,
+		`line 10: schedules: schedule "nights": invalid time "25:00"`,
+		`line 13: runbooks: runbook 1 needs a type or reason`,
+		`line 14: runbooks: runbook 2 has an invalid url "wiki/pod"`,
+		`line 22: probes: probe "db": tcp must be host:port, got "db"`,
+		`line 22: probes: probe "db": unknown severity "fatal"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+}
+
+func TestCompositionColorsAppliedOnlyWhenConfigLoads(t *testing.T) {
+	defer func() {
+		config = Config{}
+		delete(haStateColors, "on_fire")
+	}()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	composition := `compositions:
+  - name: fire
+    match: [ci_failing, issues_detected]
+    state: on_fire
+    color: 255,80,0
+schedules:
+  - name: quiet
+    hide: [on_fire]
+`
+	if err := os.WriteFile(path, []byte(composition+"    from: \"25:00\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(path); err == nil {
+		t.Fatal("invalid config loaded")
+	}
+	if _, ok := haStateColors["on_fire"]; ok {
+		t.Error("color of on_fire registered by a config that failed to load")
+	}
+
+	if err := os.WriteFile(path, []byte(composition), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if color := haStateColors["on_fire"]; color != [3]int{255, 80, 0} {
+		t.Errorf("on_fire color = %v, want 255,80,0", color)
+	}
+}
+
+func TestConfigOverlaps(t *testing.T) {
+	defer func() { config = Config{} }()
+	config.Components = []Component{
+		{issueGroup: issueGroup{Name: "media", Namespaces: []string{"media-*"}}},
+		{issueGroup: issueGroup{Name: "jellyfin", Namespaces: []string{"media-jellyfin"}}},
+		{issueGroup: issueGroup{Name: "db", Namespaces: []string{"postgres"}}},
+	}
+	warnings := configOverlaps()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"media" and "jellyfin"`) {
+		t.Errorf("overlaps = %v", warnings)
+	}
+}
+
+func TestValidatePrometheusAndProbes(t *testing.T) {
+	defer func() { config = Config{} }()
+	config.Prometheus = PrometheusConfig{URL: "http://prometheus:9090", Queries: []PromQLCheck{{Name: "disk", Query: "node_disk_free", Operator: ">="}}}
+	config.Probes = ProbesConfig{Targets: []ProbeTarget{{Name: "web", URL: "https://example.com"}, {Name: "db", TCP: "db:5432"}}}
+	if err := validatePrometheus(); err != nil {
+		t.Error(err)
+	}
+	if err := validateProbes(); err != nil {
+		t.Error(err)
+	}
+
+	for _, q := range []PromQLCheck{
+		{Name: "disk", Query: "node_disk_free", Operator: "=>"},
+		{Name: "disk", Operator: ">"},
+		{Query: "up", Operator: ">"},
+	} {
+		config.Prometheus.Queries = []PromQLCheck{q}
+		if validatePrometheus() == nil {
+			t.Errorf("query %+v passed validation", q)
+		}
+	}
+	for _, p := range []ProbeTarget{
+		{Name: "web", URL: "example.com"},
+		{Name: "db", TCP: "db"},
+		{Name: "none"},
+	} {
+		config.Probes.Targets = []ProbeTarget{p}
+		if validateProbes() == nil {
+			t.Errorf("probe %+v passed validation", p)
+		}
+	}
+}
+
+func TestExampleConfigLoads(t *testing.T) {
+	defer func() { config = Config{} }()
+	var cm struct {
+		Data map[string]string `yaml:"data"`
+	}
+	data, err := os.ReadFile("clusterbulb-config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := yaml.Unmarshal(data, &cm); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(cm.Data["config.yaml"]), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(path); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestStatusPageComponentsMigrate(t *testing.T) {
+	defer func() { config = Config{} }()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	legacy := `status_page:
+  title: Lab
+  components:
+    - name: Media
+      namespaces: [media]
+      workloads: [deployment/jellyfin]
+profiles:
+  - name: team-a
+    namespaces: [team-a-*]
+    selector: app.kubernetes.io/part-of=team-a
+    light: light.team_a
+`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Components) != 1 || config.Components[0].Name != "Media" || config.Components[0].Workloads[0] != "deployment/jellyfin" || len(config.StatusPage.Components) != 0 {
+		t.Errorf("components = %+v, want the status page components moved", config.Components)
+	}
+	if p := config.Profiles[0]; p.Light != "light.team_a" || p.selector == nil {
+		t.Errorf("profile = %+v, want the shared group settings decoded", p)
+	}
+
+	config = Config{}
+	both := legacy + `components:
+  - name: Infra
+    namespaces: [kube-system]
+`
+	if err := os.WriteFile(path, []byte(both), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadConfig(path); err == nil || !strings.Contains(err.Error(), "line 4: status_page: status_page.components moved to the top-level components") {
+		t.Errorf("err = %v, want the duplicate components reported", err)
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// configValidators check the sections of the config file, in dependency order
+var configValidators = []struct {
+	key      string
+	validate func() configProblems
+}{
+	{"prometheus", validatePrometheus},
+	{"probes", validateProbes},
+	{"scoring", validateScoring},
+	{"compositions", validateCompositions},
+	{"brightness", validateBrightness},
+	{"profiles", validateProfiles},
+	{"ha_sensors", validateHASensors},
+	{"runbooks", validateRunbooks},
+	{"status_page", migrateStatusPageComponents},
+	{"components", validateComponents},
+	{"status_page", validateStatusPage},
+	{"nanoleaf", validateNanoleaf},
+	{"schedules", validateSchedules},
+}
+
+// configProblem is a problem of a config section at the entry it is about: item is the index
+// in the list of the section, or of its list field when set, e.g. the targets of the probes,
+// and -1 for the section or field itself
+type configProblem struct {
+	field string
+	item  int
+	msg   string
+}
+
+// configProblems are the problems a validator found in its section, nil when it is valid
+type configProblems []configProblem
+
+// add records a problem of the item of the section's list, -1 for the section itself
+func (p *configProblems) add(item int, format string, args ...interface{}) {
+	p.addField("", item, format, args...)
+}
+
+// addField records a problem of the item of a list field of the section, -1 for the field itself
+func (p *configProblems) addField(field string, item int, format string, args ...interface{}) {
+	*p = append(*p, configProblem{field: field, item: item, msg: fmt.Sprintf(format, args...)})
+}
+
+func (p configProblems) Error() string {
+	msgs := make([]string, len(p))
+	for i, problem := range p {
+		msgs[i] = problem.msg
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// configErrors are all problems of a config file, reported together before starting
+type configErrors []string
+
+func (e configErrors) Error() string {
+	return fmt.Sprintf("%d problem(s):\n  %s", len(e), strings.Join(e, "\n  "))
+}
+
+// validateConfig runs every validator and returns all their problems with the line of the
+// offending entry
+func validateConfig(root *yaml.Node) []string {
+	var problems []string
+	for _, v := range configValidators {
+		for _, problem := range v.validate() {
+			problems = append(problems, fmt.Sprintf("line %d: %s: %s", configErrorLine(root, v.key, problem), v.key, problem.msg))
+		}
+	}
+	return problems
+}
+
+// configSection returns the value node of a key of a mapping or document, or nil
+func configSection(root *yaml.Node, key string) *yaml.Node {
+	doc := root
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		doc = doc.Content[0]
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == key {
+			return doc.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// configErrorLine returns the line of the list item, list field or section a problem is about,
+// or 0 when the section isn't in the file, e.g. after a migration
+func configErrorLine(root *yaml.Node, key string, problem configProblem) int {
+	node := configSection(root, key)
+	if node == nil {
+		return 0
+	}
+	if problem.field != "" {
+		field := configSection(node, problem.field)
+		if field == nil {
+			return node.Line
+		}
+		node = field
+	}
+	if node.Kind == yaml.SequenceNode && problem.item >= 0 && problem.item < len(node.Content) {
+		return node.Content[problem.item].Line
+	}
+	return node.Line
+}
+
+// configOverlaps warns about namespace patterns of different components or profiles covering
+// the same namespaces, which is allowed but often a copy-paste mistake
+func configOverlaps() []string {
+	var warnings []string
+	check := func(kind string, names []string, patterns [][]string) {
+		for i := range names {
+			for j := i + 1; j < len(names); j++ {
+				for _, a := range patterns[i] {
+					for _, b := range patterns[j] {
+						if patternsOverlap(a, b) {
+							warnings = append(warnings, fmt.Sprintf("%ss %q and %q both match namespaces %q and %q", kind, names[i], names[j], a, b))
+						}
+					}
+				}
+			}
+		}
+	}
+	var names []string
+	var patterns [][]string
+	for _, c := range config.Components {
+		names, patterns = append(names, c.Name), append(patterns, c.Namespaces)
+	}
+	check("component", names, patterns)
+	names, patterns = nil, nil
+	for _, p := range config.Profiles {
+		names, patterns = append(names, p.Name), append(patterns, p.Namespaces)
+	}
+	check("profile", names, patterns)
+	return warnings
+}
+
+// patternsOverlap reports whether two namespace patterns are equal or one matches the other
+// taken literally, e.g. media-* and media-jellyfin
+func patternsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ab, _ := path.Match(a, b)
+	ba, _ := path.Match(b, a)
+	return ab || ba
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Setting is one effective setting with where it came from, for the startup log and
+// /api/v1/config
+type Setting struct {
+	Name   string `json:"name"`   // env var, or config file key prefixed with "config:"
+	Value  string `json:"value"`  // secrets are redacted
+	Source string `json:"source"` // env, file or default
+}
+
+// envSettings maps the env vars to the variables they end up in
+var envSettings = map[string]any{
+	"ALERTMANAGER_MATCHERS":       &alertmanagerMatchers,
+	"ALERTMANAGER_URL":            &alertmanagerUrl,
+	"API_TOKEN":                   &apiToken,
+	"ARGO_ROLLOUTS_DASHBOARD_URL": &argoRolloutsDashboardUrl,
+	"BITBUCKET_APP_PASSWORD":      &bitbucketAppPassword,
+	"BITBUCKET_REPO":              &bitbucketRepo,
+	"BITBUCKET_TOKEN":             &bitbucketToken,
+	"BITBUCKET_USERNAME":          &bitbucketUsername,
+	"BITBUCKET_WORKSPACE":         &bitbucketWorkspace,
+	"BULB_COUNT_MAX":              &bulbCountMax,
+	"BULB_COUNT_MODE":             &bulbCountMode,
+	"BUSYLIGHT_BLINK_STATES":      &busylightBlinkStates,
+	"BUSYLIGHT_DEVICE":            &busylightDevice,
+	"CAPACITY_SEVERITY":           &capacitySeverity,
+	"CHECK_TIMEOUT_SECONDS":       &checkTimeout,
+	"CI_BRANCH":                   &ciBranch,
+	"CLUSTER_BOOT_MINUTES":        &clusterBootWindow,
+	"CONFIG_FILE":                 &configFile,
+	"CRITICAL_WORKLOADS":          &criticalWorkloads,
+	"CSR_PENDING_MINUTES":         &csrPendingMinutes,
+	"DEPRECATED_API_SCAN":         &deprecatedAPIScan,
+	"DNS_CHECK_EXTERNAL_NAME":     &dnsCheckExternalName,
+	"DNS_CHECK_LATENCY_MS":        &dnsCheckLatencyMs,
+	"DNS_CHECK_NAME":              &dnsCheckName,
+	"EGRESS_CHECK_URL":            &egressCheckUrl,
+	"ETCD_BACKUP_MAX_AGE_HOURS":   &etcdBackupMaxAgeHours,
+	"ETCD_BACKUP_PATH":            &etcdBackupPath,
+	"EVENT_STORM_THRESHOLD":       &eventStormThreshold,
+	"FALCO_ALERT_TTL_MINUTES":     &falcoAlertTTL,
+	"FALCO_WEBHOOK_TOKEN":         &falcoWebhookToken,
+	"GH_API_URL":                  &ghApiUrl,
+	"GH_ISSUE_LABEL":              &ghIssueLabel,
+	"GH_ISSUE_LABEL_STATE":        &ghIssueLabelState,
+	"GH_ORG":                      &ghOrg,
+	"GH_OWNER":                    &ghOwner,
+	"GH_PR_BOTS_SEPARATE":         &ghPRBotsSeparate,
+	"GH_PR_BOT_AUTHORS":           &ghPRBotAuthors,
+	"GH_PR_CHECK_INTERVAL":        &ghPRCheckInterval,
+	"GH_PR_CHECK_MERGEABLE":       &ghPRCheckMergeable,
+	"GH_PR_ESCALATE_AGE_DAYS":     &ghPREscalateAgeDays,
+	"GH_PR_ESCALATE_COUNT":        &ghPREscalateCount,
+	"GH_PR_IGNORED_MODE":          &ghPRIgnoredMode,
+	"GH_PR_IGNORE_AUTHORS":        &ghPRIgnoreAuthors,
+	"GH_PR_IGNORE_DRAFTS":         &ghPRIgnoreDrafts,
+	"GH_PR_IGNORE_LABELS":         &ghPRIgnoreLabels,
+	"GH_REPO":                     &ghRepo,
+	"GH_REPO_GLOB":                &ghRepoGlob,
+	"GH_REPO_TOPIC":               &ghRepoTopic,
+	"GH_REVIEWER":                 &ghReviewer,
+	"GH_SECURITY_ALERTS":          &ghSecurityAlerts,
+	"GH_SECURITY_MIN_SEVERITY":    &ghSecurityMinSeverity,
+	"GH_TICKET_AFTER_MINUTES":     &ghTicketAfter,
+	"GH_TICKET_LABEL":             &ghTicketLabel,
+	"GH_TICKET_REPO":              &ghTicketRepo,
+	"GH_TOKEN":                    &ghToken,
+	"GH_USE_GRAPHQL":              &ghUseGraphQL,
+	"GH_WEBHOOK_SECRET":           &ghWebhookSecret,
+	"GITEA_OWNER":                 &giteaOwner,
+	"GITEA_REPO":                  &giteaRepo,
+	"GITEA_TOKEN":                 &giteaToken,
+	"GITEA_URL":                   &giteaUrl,
+	"GPU_EXPECTED_COUNT":          &gpuExpectedCount,
+	"GPU_NODE_SELECTOR":           &gpuNodeSelector,
+	"GRPC_LISTEN_ADDR":            &grpcListenAddr,
+	"HA_BREATHE_STATES":           &haBreatheStates,
+	"HA_LIGHT_AREA":               &haLightArea,
+	"HA_LIGHT_BRIGHTNESS":         &haLightBrightness,
+	"HA_LIGHT_ENTITY_ID":          &haLightEntityId,
+	"HA_LIGHT_TRANSITION":         &haLightTransition,
+	"HA_MEDIA_PLAYER_ENTITY_ID":   &haMediaPlayerEntityId,
+	"HA_MUTE_ENTITY_ID":           &haMuteEntityId,
+	"HA_MUTE_STATE":               &haMuteState,
+	"HA_OFF_WHEN_HEALTHY":         &haOffWhenHealthy,
+	"HA_SOUND_URL":                &haSoundUrl,
+	"HA_SOUND_VOLUME":             &haSoundVolume,
+	"HA_STATE_RGBW":               &haStateRGBW,
+	"HA_STATE_TARGETS":            &haStateTargets,
+	"HA_TOKEN":                    &haToken,
+	"HA_URL":                      &haUrl,
+	"HISTORY_FILE":                &historyFile,
+	"HISTORY_RETENTION_DAYS":      &historyRetentionDays,
+	"HTTP_LISTEN_ADDR":            &httpListenAddr,
+	"HTTP_TIMEOUTS":               &httpTimeouts,
+	"INTEGRATION_DEGRADED_STATE":  &integrationDegradedState,
+	"INTEGRATION_FAILURE_LIMIT":   &integrationFailureLimit,
+	"ISSUE_MAX_ENTRIES":           &issueMaxEntries,
+	"ISSUE_TTL_MINUTES":           &issueTTLMinutes,
+	"JIRA_AFTER_MINUTES":          &jiraAfter,
+	"JIRA_API_TOKEN":              &jiraApiToken,
+	"JIRA_DONE_TRANSITION":        &jiraDoneTransition,
+	"JIRA_ISSUE_TYPE":             &jiraIssueType,
+	"JIRA_PROJECT":                &jiraProject,
+	"JIRA_URL":                    &jiraUrl,
+	"JIRA_USER":                   &jiraUser,
+	"K8S_EOL_URL":                 &k8sEOLUrl,
+	"K8S_MAX_SKEW":                &k8sMaxSkew,
+	"KUBECONFIG":                  &kubeconfig,
+	"KUBE_BENCH_CONFIGMAP":        &kubeBenchConfigMap,
+	"KUBE_BENCH_CONFIGMAP_KEY":    &kubeBenchConfigMapKey,
+	"KUBE_BENCH_JOB":              &kubeBenchJob,
+	"LAMETRIC_API_KEY":            &lametricApiKey,
+	"LAMETRIC_ICON":               &lametricIcon,
+	"LAMETRIC_URL":                &lametricUrl,
+	"LB_PENDING_GRACE_MINUTES":    &lbPendingGraceMinutes,
+	"LIGHT_DRIVER":                &lightDriver,
+	"NAMESPACE_LIGHTS":            &namespaceLights,
+	"NANOLEAF_TOKEN":              &nanoleafToken,
+	"NODE_CLOCK_SKEW_SECONDS":     &nodeClockSkewSeconds,
+	"NODE_TEMP_THRESHOLD":         &nodeTempThreshold,
+	"NOTIFY_DRIVER":               &notifyDriver,
+	"NTFY_ATTACH_PRIORITY":        &ntfyAttachPriority,
+	"NTFY_ATTACH_REPORT":          &ntfyAttachReport,
+	"NTFY_INTERVAL_MINUTES":       &ntfyInterval,
+	"NTFY_RECOVERIES":             &ntfyRecoveries,
+	"NTFY_STATE_CHANGES":          &ntfyStateChanges,
+	"NTFY_TOKEN":                  &ntfyToken,
+	"NTFY_TOPIC":                  &ntfyTopic,
+	"NTFY_URL":                    &ntfyUrl,
+	"OPENCOST_URL":                &opencostUrl,
+	"PIXOO_URL":                   &pixooUrl,
+	"POLICY_MIN_SEVERITY":         &policyMinSeverity,
+	"PREEMPTION_THRESHOLD":        &preemptionThreshold,
+	"PREEMPTION_WINDOW_MINUTES":   &preemptionWindowMinutes,
+	"PROVISIONING_CHECK":          &provisioningCheck,
+	"PROVISIONING_MAX_EFFICIENCY": &provisioningMaxEfficiency,
+	"PROVISIONING_MIN_EFFICIENCY": &provisioningMinEfficiency,
+	"QUIET_HOURS":                 &quietHours,
+	"REBOOT_REQUIRED_ANNOTATION":  &rebootRequiredAnnotation,
+	"REBOOT_REQUIRED_STATE":       &rebootRequiredState,
+	"RECORDING_FILE":              &recordingFile,
+	"REGISTRY_CHECK_HOSTS":        &registryCheckHosts,
+	"SCM_PROVIDER":                &scmProviderName,
+	"SLACK_SIGNING_SECRET":        &slackSigningSecret,
+	"SLO_BUDGET_LOW_PERCENT":      &sloBudgetLowPercent,
+	"SLO_TARGET":                  &sloTarget,
+	"SMART_CHECK":                 &smartCheck,
+	"SNOOZE_MINUTES":              &snoozeMinutes,
+	"SOUND_INTERVAL_MINUTES":      &soundInterval,
+	"SOUND_STATES":                &soundStates,
+	"SPOT_NODE_LABELS":            &spotNodeLabels,
+	"SPOT_TERMINATION_SIGNALS":    &spotTerminationSignals,
+	"STREAMDECK_DEVICE":           &streamDeckDevice,
+	"STREAMDECK_KEYS":             &streamDeckKeys,
+	"TELEGRAM_CHAT_IDS":           &telegramChatIds,
+	"TELEGRAM_WEBHOOK_SECRET":     &telegramWebhookSecret,
+	"TLS_CA_FILE":                 &tlsCAFile,
+	"TLS_INSECURE_SKIP_VERIFY":    &tlsInsecureSkipVerify,
+	"TOKEN_RELOAD_MINUTES":        &tokenReloadInterval,
+	"TRIVY_CRITICAL_THRESHOLD":    &trivyCriticalThreshold,
+	"TRIVY_STATE":                 &trivyState,
+	"WARMUP_MINUTES":              &warmupDuration,
+	"WARMUP_ON_CLUSTER_BOOT":      &warmupOnClusterBoot,
+	"WARNING_EVENTS_STATE":        &warningEventsState,
+	"ZONE_OUTAGE_MINUTES":         &zoneOutageWindow,
+}
+
+// envColorSettings maps the HA_COLOR_* env vars to their state
+var envColorSettings = map[string]Signal{
+	"HA_COLOR_BOOTING":           SignalBooting,
+	"HA_COLOR_INCIDENTS":         SignalIncidentsOpen,
+	"HA_COLOR_PRS_BLOCKED":       SignalPullRequestsBlocked,
+	"HA_COLOR_PRS_BOTS":          SignalDependencyUpdatesOpen,
+	"HA_COLOR_PRS_ESCALATED":     SignalPullRequestsEscalated,
+	"HA_COLOR_PRS_IGNORED":       SignalPullRequestsIgnored,
+	"HA_COLOR_REBOOT_REQUIRED":   SignalRebootRequired,
+	"HA_COLOR_SECURITY_ALERTS":   SignalSecurityAlertsOpen,
+	"HA_COLOR_SLO_BUDGET_LOW":    SignalSLOBudgetLow,
+	"HA_COLOR_SPOT_INTERRUPTION": SignalSpotInterruption,
+	"HA_COLOR_STARTING":          SignalStarting,
+	"HA_COLOR_VULNERABILITIES":   SignalVulnerabilitiesFound,
+	"HA_COLOR_WARNING_EVENTS":    SignalWarningEvents,
+}
+
+// envPassthroughSettings are read from the environment where they are used, e.g. the proxies by
+// net/http and the token file paths by addTokenFile, rather than kept in a variable
+var envPassthroughSettings = []string{
+	"GH_TOKEN_FILE",
+	"HA_TOKEN_FILE",
+	"HTTPS_PROXY",
+	"HTTP_PROXY",
+	"NO_PROXY",
+	"NTFY_TOKEN_FILE",
+}
+
+// configKeys are the top-level keys present in CONFIG_FILE, set by loadConfig
+var configKeys []string
+
+var secretSetting = regexp.MustCompile(`(TOKEN|SECRET|PASSWORD|API_KEY)$`)
+
+// effectiveSettings returns every env setting and config file section with its current value
+func effectiveSettings() []Setting {
+	var settings []Setting
+	for name, v := range envSettings {
+		value := ""
+		if token, ok := v.(*secretToken); ok {
+			value = token.Get()
+		} else {
+			value = fmt.Sprint(reflect.ValueOf(v).Elem().Interface())
+		}
+		settings = append(settings, Setting{Name: name, Value: value, Source: envSource(name)})
+	}
+	for _, name := range envPassthroughSettings {
+		settings = append(settings, Setting{Name: name, Value: os.Getenv(name), Source: envSource(name)})
+	}
+	colors := stateColors()
+	for name, state := range envColorSettings {
+		c := colors[state]
+		settings = append(settings, Setting{Name: name, Value: fmt.Sprintf("%d,%d,%d", c[0], c[1], c[2]), Source: envSource(name)})
+	}
+	for i := range settings {
+		settings[i].Value = redactSetting(settings[i].Name, settings[i].Value)
+	}
+
+	sections := reflect.ValueOf(config)
+	for i := range sections.NumField() {
+		key := strings.Split(sections.Type().Field(i).Tag.Get("yaml"), ",")[0]
+		source := "default"
+		if slices.Contains(configKeys, key) {
+			source = "file"
+		}
+		settings = append(settings, Setting{Name: "config:" + key, Value: flowYAML(sections.Field(i).Interface()), Source: source})
+	}
+	slices.SortFunc(settings, func(a, b Setting) int { return strings.Compare(a.Name, b.Name) })
+	return settings
+}
+
+// envSource tells whether a setting was set in the environment, read from a *_FILE token file
+// or left at its default
+func envSource(name string) string {
+	for _, t := range tokenFiles {
+		if t.name == name {
+			return "file"
+		}
+	}
+	if os.Getenv(name) != "" {
+		return "env"
+	}
+	return "default"
+}
+
+// redactSetting hides secrets and the passwords of URLs, keeping whether they are set
+func redactSetting(name, value string) string {
+	if secretSetting.MatchString(name) && value != "" {
+		return "<redacted>"
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return value
+}
+
+// flowYAML renders a config file section on one line, e.g. {url: http://prometheus:9090, interval: 60},
+// redacting the values of secret keys such as api_key like the env settings
+func flowYAML(v any) string {
+	var node yaml.Node
+	if err := node.Encode(v); err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	var flow func(n *yaml.Node, name string)
+	flow = func(n *yaml.Node, name string) {
+		n.Style = yaml.FlowStyle
+		if n.Kind == yaml.ScalarNode {
+			n.Value = redactSetting(name, n.Value)
+		}
+		for i, c := range n.Content {
+			key := ""
+			if n.Kind == yaml.MappingNode && i%2 == 1 {
+				key = strings.ToUpper(n.Content[i-1].Value)
+			}
+			flow(c, key)
+		}
+	}
+	flow(&node, "")
+	out, err := yaml.Marshal(&node)
+	if err != nil {
+		return fmt.Sprintf("<%v>", err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// logSettings logs the settings that were set at startup; the defaults are listed by
+// /api/v1/config
+func logSettings() {
+	defaults := 0
+	for _, s := range effectiveSettings() {
+		if s.Source == "default" {
+			defaults++
+			continue
+		}
+		log.Printf("Setting %s=%s (%s)", s.Name, s.Value, s.Source)
+	}
+	log.Printf("%d other settings at their defaults, see /api/v1/config", defaults)
+}
+
+// configHandler lists the effective settings with their sources
+func configHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(effectiveSettings()); err != nil {
+		log.Printf("Error encoding settings: %v", err)
+	}
+}
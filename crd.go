@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// listCustomResources lists a custom resource across all namespaces and decodes the
+// list into v. It returns false without an error when the CRD is not installed, so
+// integrations for operators can be enabled automatically.
+func listCustomResources(ctx context.Context, clientset kubernetes.Interface, groupVersion, resource string, v interface{}) (bool, error) {
+	resources, err := clientset.Discovery().ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to discover %s: %w", groupVersion, err)
+	}
+	if !slices.ContainsFunc(resources.APIResources, func(r metav1.APIResource) bool { return r.Name == resource }) {
+		return false, nil
+	}
+
+	return true, getCustomResources(ctx, clientset, groupVersion, resource, v)
+}
+
+// getCustomResources lists a custom resource known to exist and decodes the list into v
+func getCustomResources(ctx context.Context, clientset kubernetes.Interface, groupVersion, resource string, v interface{}) error {
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/apis", groupVersion, resource).DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", resource, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", resource, err)
+	}
+	return nil
+}
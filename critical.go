@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var criticalWorkloads []string                     // os.Getenv("CRITICAL_WORKLOADS") // comma-separated namespace/name, e.g. kube-system/coredns
+var criticalWorkloadsDegraded = map[string]Issue{} // workloads degraded on the last run, to notify only once
+
+// checkCriticalWorkloads verifies every allowlisted Deployment, StatefulSet or DaemonSet has all
+// replicas ready, raising a critical issue and an immediate notification when one degrades.
+// Workloads scaled to zero are fine; a workload that can't be fetched keeps its last state.
+func checkCriticalWorkloads(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var issues []Issue
+	degraded := map[string]Issue{}
+	for _, workload := range criticalWorkloads {
+		namespace, name, ok := strings.Cut(workload, "/")
+		if !ok {
+			continue
+		}
+		kind, ready, desired, err := workloadReplicas(ctx, clientset, namespace, name)
+		if err != nil {
+			log.Printf("Error fetching critical workload %s: %v", workload, err)
+			if issue, ok := criticalWorkloadsDegraded[workload]; ok {
+				degraded[workload] = issue
+				issues = append(issues, issue)
+			}
+			continue
+		}
+
+		var message string
+		switch {
+		case desired < 0:
+			message = fmt.Sprintf("Critical workload %s not found", workload)
+		case ready < desired:
+			message = fmt.Sprintf("Critical workload %s is degraded: %d/%d ready", workload, ready, desired)
+		default:
+			continue
+		}
+		issue := Issue{Key: "critical/" + workload, Type: "CriticalWorkload", Severity: "critical", Message: message, Timestamp: time.Now(),
+			Namespace: namespace, Kind: kind, Name: name}
+		if kind != "" {
+			issue.Owner = strings.ToLower(kind) + "/" + name
+		}
+		degraded[workload] = issue
+		issues = append(issues, issue)
+
+		// Notify right away instead of waiting for the bulb to be noticed
+		if _, ok := criticalWorkloadsDegraded[workload]; !ok {
+			ntfyOpts := NtfyOptions{
+				Title:    fmt.Sprintf("Critical workload degraded: %s", workload),
+				Priority: 5, // (required)
+				Click:    runbookFor(issue),
+				Active: func() bool {
+					_, ok := criticalWorkloadsDegraded[workload]
+					return ok
+				},
+			}
+			if err := SendNtfyAlert(message, ntfyOpts); err != nil {
+				log.Printf("Error sending ntfy alert: %v", err)
+			}
+		}
+	}
+	criticalWorkloadsDegraded = degraded
+	return issues
+}
+
+// workloadReplicas returns the kind and the ready and desired replicas of the Deployment,
+// StatefulSet or DaemonSet with the given name, or a desired count of -1 when none exists
+func workloadReplicas(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (string, int32, int32, error) {
+	deploy, err := clientset.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		desired := int32(1)
+		if deploy.Spec.Replicas != nil {
+			desired = *deploy.Spec.Replicas
+		}
+		return "Deployment", deploy.Status.ReadyReplicas, desired, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", 0, 0, err
+	}
+
+	sts, err := clientset.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		desired := int32(1)
+		if sts.Spec.Replicas != nil {
+			desired = *sts.Spec.Replicas
+		}
+		return "StatefulSet", sts.Status.ReadyReplicas, desired, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", 0, 0, err
+	}
+
+	ds, err := clientset.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return "DaemonSet", ds.Status.NumberReady, ds.Status.DesiredNumberScheduled, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", 0, 0, err
+	}
+	return "", 0, -1, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCriticalWorkloads(t *testing.T) {
+	defer func(w []string) { criticalWorkloads, criticalWorkloadsDegraded = w, map[string]Issue{} }(criticalWorkloads)
+	criticalWorkloads = []string{"kube-system/coredns", "media/jellyfin"}
+	deploy := func(namespace, name string, desired, ready int32) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec:       appsv1.DeploymentSpec{Replicas: &desired},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: ready},
+		}
+	}
+
+	clientset := fake.NewSimpleClientset(deploy("kube-system", "coredns", 2, 1), deploy("media", "jellyfin", 0, 0))
+	issues := checkCriticalWorkloads(context.Background(), clientset)
+	if len(issues) != 1 || issues[0].Key != "critical/kube-system/coredns" {
+		t.Fatalf("issues = %v, want only the degraded coredns (jellyfin is scaled to zero)", issues)
+	}
+
+	// An API error keeps the degraded workload instead of clearing it
+	clientset.PrependReactor("get", "deployments", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver timeout")
+	})
+	issues = checkCriticalWorkloads(context.Background(), clientset)
+	if len(issues) != 1 || issues[0].Key != "critical/kube-system/coredns" {
+		t.Errorf("issues after an API error = %v, want coredns kept", issues)
+	}
+}
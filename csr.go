@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var csrPendingMinutes = 15 // os.Getenv("CSR_PENDING_MINUTES") // flag CSRs unapproved or unissued for longer than this
+
+// checkCertificateSigningRequests flags CSRs stuck unapproved or approved but never issued, which
+// otherwise only surface when kubelet serving certificates expire and node metrics break
+func checkCertificateSigningRequests(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	csrs, err := clientset.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching certificate signing requests: %v", err)
+		return nil
+	}
+
+	threshold := time.Duration(csrPendingMinutes) * time.Minute
+	var issues []Issue
+	for _, csr := range csrs.Items {
+		age := time.Since(csr.CreationTimestamp.Time)
+		if age < threshold || len(csr.Status.Certificate) > 0 {
+			continue
+		}
+
+		approved, denied := false, false
+		for _, cond := range csr.Status.Conditions {
+			switch cond.Type {
+			case certificatesv1.CertificateApproved:
+				approved = true
+			case certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+				denied = true
+			}
+		}
+		if denied {
+			continue
+		}
+		state := "pending approval"
+		if approved {
+			state = "approved but not issued"
+		}
+		message := fmt.Sprintf("CSR %s from %s (%s) is %s for %s", csr.Name, csr.Spec.Username, csr.Spec.SignerName, state, age.Round(time.Minute))
+		issues = append(issues, Issue{Key: "csr/" + csr.Name, Type: "CertificateSigningRequest", Severity: "warning", Message: message, Timestamp: time.Now()})
+	}
+	return issues
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var deprecatedAPIScan = false // os.Getenv("DEPRECATED_API_SCAN") == "true" // requires get on the /metrics non-resource URL
+
+var deprecationMu sync.Mutex
+var deprecationWarnings = map[string]time.Time{} // apiserver warnings seen on our own requests, by text
+
+// deprecationWarningHandler collects the apiserver warnings (mostly deprecations) returned for
+// the monitor's own requests instead of logging them
+type deprecationWarningHandler struct{}
+
+func (deprecationWarningHandler) HandleWarningHeader(code int, agent string, text string) {
+	if code != 299 || text == "" {
+		return
+	}
+	deprecationMu.Lock()
+	deprecationWarnings[text] = time.Now()
+	deprecationMu.Unlock()
+}
+
+// checkDeprecations reports apiserver deprecation warnings seen during the last day, and with
+// DEPRECATED_API_SCAN any deprecated API still requested by a client in the cluster
+func checkDeprecations(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var issues []Issue
+	deprecationMu.Lock()
+	for text, seen := range deprecationWarnings {
+		if time.Since(seen) > 24*time.Hour {
+			delete(deprecationWarnings, text)
+			continue
+		}
+		issues = append(issues, Issue{Key: "deprecation/" + text, Type: "Deprecation", Severity: "info", Message: text, Timestamp: seen})
+	}
+	deprecationMu.Unlock()
+
+	if deprecatedAPIScan {
+		issues = append(issues, scanDeprecatedAPIs(ctx, clientset)...)
+	}
+	return issues
+}
+
+// scanDeprecatedAPIs reads the apiserver_requested_deprecated_apis metric, which lists every
+// deprecated API version requested since the apiserver started. APIs removed in the next
+// minor release are warnings, the rest are info.
+func scanDeprecatedAPIs(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		log.Printf("Error fetching server version: %v", err)
+		return nil
+	}
+	serverMinor, err := strconv.Atoi(strings.TrimRight(info.Minor, "+"))
+	if err != nil {
+		log.Printf("Error parsing server version %q: %v", info.GitVersion, err)
+		return nil
+	}
+
+	body, err := clientset.Discovery().RESTClient().Get().AbsPath("/metrics").DoRaw(ctx)
+	if err != nil {
+		log.Printf("Error fetching apiserver metrics: %v", err)
+		return nil
+	}
+
+	var issues []Issue
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "apiserver_requested_deprecated_apis{") {
+			continue
+		}
+		labels := parseMetricLabels(line)
+		api := labels["resource"] + "." + labels["version"] + "." + labels["group"]
+		if labels["group"] == "" {
+			api = labels["resource"] + "." + labels["version"]
+		}
+
+		severity := "info"
+		message := fmt.Sprintf("Deprecated API %s is still in use", api)
+		if removed := labels["removed_release"]; removed != "" {
+			message += " (removed in " + removed + ")"
+			if minor, ok := minorVersion(removed); ok && minor <= serverMinor+1 {
+				severity = "warning"
+			}
+		}
+		issues = append(issues, Issue{Key: "deprecation/api/" + api, Type: "Deprecation", Severity: severity, Message: message, Timestamp: time.Now()})
+	}
+	return issues
+}
+
+// parseMetricLabels returns the labels of a Prometheus text format sample line
+func parseMetricLabels(line string) map[string]string {
+	labels := map[string]string{}
+	start, end := strings.Index(line, "{"), strings.LastIndex(line, "}")
+	if start < 0 || end < start {
+		return labels
+	}
+	for _, pair := range strings.Split(line[start+1:end], ",") {
+		if k, v, ok := strings.Cut(pair, "="); ok {
+			labels[k] = strings.Trim(v, `"`)
+		}
+	}
+	return labels
+}
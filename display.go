@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Display is an output that shows more than the bulb color, e.g. a pixel clock or a desk light
+type Display interface {
+	// Name returns the display name used in logs
+	Name() string
+	// Show renders the view; it is only called when the view changed
+	Show(ctx context.Context, view DisplayView) error
+}
+
+// DisplayView is what displays render: the primary signal with its color, a count and a short message
+type DisplayView struct {
+	Signal  Signal
+	Color   [3]int
+	Count   int    // active issues, or open PRs when there are none
+	Message string // e.g. "pull requests open | issues detected"
+}
+
+// displayRefresher is implemented by displays that switch off unless they are refreshed
+type displayRefresher interface {
+	Refresh() error
+}
+
+var displays []Display
+var displayLastViews = map[string]DisplayView{} // last view each display showed, by name
+
+// currentDisplayView returns the view of the cluster state, preferring issues_detected as primary signal
+func currentDisplayView() DisplayView {
+	shown := shownState()
+	signals := shown.Signals()
+	signal := signals[0]
+	if shown.Has(SignalIssuesDetected) {
+		signal = SignalIssuesDetected
+	}
+
+	count := activeIssueCount
+	if count == 0 {
+		count = prCount
+	}
+	var parts []string
+	for _, s := range signals {
+		parts = append(parts, strings.ReplaceAll(string(s), "_", " "))
+	}
+	color, _ := stateColor(signal)
+	return DisplayView{Signal: signal, Color: color, Count: count, Message: strings.Join(parts, " | ")}
+}
+
+// updateDisplays pushes the current view to every display that doesn't show it yet; a display
+// that failed is retried on the next update
+func updateDisplays(ctx context.Context) {
+	if len(displays) == 0 {
+		return
+	}
+	view := currentDisplayView()
+	for _, d := range displays {
+		if last, ok := displayLastViews[d.Name()]; ok && last == view {
+			if r, ok := d.(displayRefresher); ok {
+				if err := r.Refresh(); err != nil {
+					log.Printf("Error refreshing %s display: %v", d.Name(), err)
+				}
+			}
+			continue
+		}
+		if err := d.Show(ctx, view); err != nil {
+			log.Printf("Error updating %s display: %v", d.Name(), err)
+			continue
+		}
+		displayLastViews[d.Name()] = view
+	}
+}
+
+// postJSON sends v as JSON to url with the client of the integration
+func postJSON(ctx context.Context, integration string, url string, v interface{}, setHeaders func(*http.Request)) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if setHeaders != nil {
+		setHeaders(req)
+	}
+
+	resp, err := httpClient(integration).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned unexpected status: %s", integration, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// flakyDisplay fails its first Show
+type flakyDisplay struct{ shows, fails int }
+
+func (d *flakyDisplay) Name() string { return "flaky" }
+
+func (d *flakyDisplay) Show(ctx context.Context, view DisplayView) error {
+	d.shows++
+	if d.fails > 0 {
+		d.fails--
+		return errors.New("unreachable")
+	}
+	return nil
+}
+
+func TestFailedDisplayIsRetried(t *testing.T) {
+	d := &flakyDisplay{fails: 1}
+	displays, displayLastViews = []Display{d}, map[string]DisplayView{}
+	defer func() { displays, displayLastViews = nil, map[string]DisplayView{} }()
+
+	for range 3 {
+		updateDisplays(context.Background())
+	}
+	if d.shows != 2 {
+		t.Errorf("shown %d times, want a retry after the failure and nothing once shown", d.shows)
+	}
+}
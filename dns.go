@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var dnsCheckName = ""         // os.Getenv("DNS_CHECK_NAME") // in-cluster name, e.g. kubernetes.default.svc.cluster.local, empty disables
+var dnsCheckExternalName = "" // os.Getenv("DNS_CHECK_EXTERNAL_NAME") // optional external name, e.g. github.com
+var dnsCheckLatencyMs = 500   // os.Getenv("DNS_CHECK_LATENCY_MS") // warn when resolution is slower than this
+
+// checkDNS resolves the configured names through the cluster DNS (the pod's resolv.conf)
+// and flags failures or slow lookups, which often don't show up as unhealthy pods
+func checkDNS(ctx context.Context, _ kubernetes.Interface) []Issue {
+	var issues []Issue
+	for _, name := range []string{dnsCheckName, dnsCheckExternalName} {
+		if name == "" {
+			continue
+		}
+		key := "dns/" + name
+
+		lookupCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		start := time.Now()
+		_, err := net.DefaultResolver.LookupHost(lookupCtx, name)
+		latency := time.Since(start)
+		cancel()
+
+		switch {
+		case err != nil:
+			issues = append(issues, Issue{Key: key, Type: "DNS", Severity: "critical", Message: fmt.Sprintf("DNS resolution of %s failed: %v", name, err), Timestamp: time.Now()})
+		case latency > time.Duration(dnsCheckLatencyMs)*time.Millisecond:
+			issues = append(issues, Issue{Key: key, Type: "DNS", Severity: "warning", Message: fmt.Sprintf("DNS resolution of %s took %s (threshold %dms)", name, latency.Round(time.Millisecond), dnsCheckLatencyMs), Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
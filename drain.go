@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// cordonedNodes holds the nodes marked unschedulable in the last node check
+var cordonedNodes = map[string]bool{}
+
+// drainEvictionReasons are the event reasons of pods being evicted from a node
+var drainEvictionReasons = []string{"Evicted", "Killing"}
+
+// collapseDrains replaces the pod and event issues caused by a drain with one info issue per
+// draining node. A node is draining while it is cordoned and its pods are terminating or being
+// evicted; pending pods waiting to be rescheduled are left out while a drain is in progress.
+func collapseDrains(podIssues, eventIssues []Issue) (drains, pods, events []Issue) {
+	evictions := map[string]int{}
+	for _, issue := range podIssues {
+		if cordonedNodes[issue.Node] && issue.Reason == "Terminating" {
+			evictions[issue.Node]++
+		}
+	}
+	for _, issue := range eventIssues {
+		if cordonedNodes[issue.Node] && slices.Contains(drainEvictionReasons, issue.Reason) {
+			evictions[issue.Node]++
+		}
+	}
+	if len(evictions) == 0 {
+		return nil, podIssues, eventIssues
+	}
+
+	nodes := make([]string, 0, len(evictions))
+	for node := range evictions {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	for _, node := range nodes {
+		msg := fmt.Sprintf("Node %s is draining (%d pods being evicted)", node, evictions[node])
+		drains = append(drains, Issue{Key: "node/" + node + "/draining", Type: "NodeDraining", Severity: "info", Message: msg, Timestamp: time.Now(),
+			Kind: "Node", Name: node, Reason: "Draining", Count: evictions[node]})
+	}
+
+	pods = slices.DeleteFunc(slices.Clone(podIssues), func(issue Issue) bool {
+		return evictions[issue.Node] > 0 || (issue.Node == "" && issue.Reason == "Pending")
+	})
+	events = slices.DeleteFunc(slices.Clone(eventIssues), func(issue Issue) bool {
+		return evictions[issue.Node] > 0
+	})
+	return drains, pods, events
+}
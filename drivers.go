@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+var lightDriver = "homeassistant" // os.Getenv("LIGHT_DRIVER") // homeassistant, noop or recording
+var notifyDriver = "ntfy"         // os.Getenv("NOTIFY_DRIVER") // ntfy, noop or recording
+var recordingFile = ""            // os.Getenv("RECORDING_FILE") // JSON lines file the recording drivers append to
+
+// recordingMax bounds the commands kept in memory for /api/v1/recording
+const recordingMax = 1000
+
+// RecordedCommand is one light or notifier command captured by a recording driver
+type RecordedCommand struct {
+	Time    time.Time   `json:"time"`
+	Driver  string      `json:"driver"` // light or notify
+	Action  string      `json:"action"` // e.g. light.turn_on or send
+	Payload interface{} `json:"payload"`
+}
+
+var recordingMu sync.Mutex
+var recording []RecordedCommand
+
+// validDriver reports whether name is the real driver or one of the built-in test drivers
+func validDriver(name, real string) bool {
+	return name == real || name == "noop" || name == "recording"
+}
+
+// haConfigured reports whether Home Assistant service calls can be made, which the test
+// drivers always allow so the light logic runs without an instance
+func haConfigured() bool {
+	return lightDriver != "homeassistant" || (haToken.Get() != "" && haUrl != "")
+}
+
+// recordCommand keeps the command for /api/v1/recording and appends it to RECORDING_FILE
+func recordCommand(driver, action string, payload interface{}) {
+	cmd := RecordedCommand{Time: time.Now(), Driver: driver, Action: action, Payload: payload}
+
+	recordingMu.Lock()
+	defer recordingMu.Unlock()
+	recording = append(recording, cmd)
+	if len(recording) > recordingMax {
+		recording = recording[len(recording)-recordingMax:]
+	}
+
+	if recordingFile == "" {
+		return
+	}
+	line, err := json.Marshal(cmd)
+	if err != nil {
+		log.Printf("Error marshaling recorded command: %v", err)
+		return
+	}
+	f, err := os.OpenFile(recordingFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Printf("Error opening recording file: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		log.Printf("Error writing recording file: %v", err)
+	}
+}
+
+// recordingHandler serves the recorded commands as JSON; DELETE clears them between test steps
+func recordingHandler(w http.ResponseWriter, r *http.Request) {
+	recordingMu.Lock()
+	defer recordingMu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		commands := recording
+		if commands == nil {
+			commands = []RecordedCommand{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(commands)
+	case http.MethodDelete:
+		recording = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordingDrivers(t *testing.T) {
+	lightDriver, notifyDriver = "recording", "recording"
+	haLightEntityId = "light.clusterbulb"
+	recording = nil
+	defer func() {
+		lightDriver, notifyDriver = "homeassistant", "ntfy"
+		haLightEntityId = ""
+		recording = nil
+	}()
+
+	haSetBulbColors(context.Background(), haLightEntityId, "rgb_color", []int{255, 0, 0}, 128, 0)
+	haTurnOffBulb(context.Background())
+	if err := SendNtfyAlert("Node node-2 is not ready", NtfyOptions{Title: "Cluster issues", Priority: 4}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ driver, action string }{
+		{"light", "light.turn_on"},
+		{"light", "light.turn_off"},
+		{"notify", "send"},
+	}
+	if len(recording) != len(want) {
+		t.Fatalf("recorded %d commands, want %d: %+v", len(recording), len(want), recording)
+	}
+	for i, w := range want {
+		if recording[i].Driver != w.driver || recording[i].Action != w.action {
+			t.Errorf("command %d = %s %s, want %s %s", i, recording[i].Driver, recording[i].Action, w.driver, w.action)
+		}
+	}
+	if payload := recording[0].Payload.(map[string]interface{}); payload["entity_id"] != "light.clusterbulb" || payload["brightness"] != 128 {
+		t.Errorf("light.turn_on payload = %v", payload)
+	}
+
+	// noop drops commands without recording them
+	lightDriver, notifyDriver = "noop", "noop"
+	haTurnOffBulb(context.Background())
+	SendNtfyAlert("dropped", NtfyOptions{Priority: 3})
+	if len(recording) != len(want) {
+		t.Errorf("noop drivers recorded %d commands", len(recording)-len(want))
+	}
+}
+
+func TestOffWhenHealthy(t *testing.T) {
+	lightDriver, haLightEntityId, haOffWhenHealthy = "recording", "light.clusterbulb", true
+	recording = nil
+	defer func() {
+		lightDriver, haLightEntityId, haOffWhenHealthy = "homeassistant", "", false
+		recording = nil
+	}()
+
+	haShowSignal(context.Background(), SignalHealthy)
+	haShowSignal(context.Background(), SignalPullRequestsOpen)
+	if len(recording) != 2 || recording[0].Action != "light.turn_off" || recording[1].Action != "light.turn_on" {
+		t.Errorf("recorded %+v, want turn_off for healthy and turn_on for open PRs", recording)
+	}
+}
+
+func TestSoundTransition(t *testing.T) {
+	lightDriver, haMediaPlayerEntityId, haSoundUrl = "recording", "media_player.kitchen", "media-source://alert.mp3"
+	recording = nil
+	defer func() {
+		lightDriver, haMediaPlayerEntityId, haSoundUrl = "homeassistant", "", ""
+		recording, quietHours = nil, nil
+		delete(notifyLastSent, "sound")
+	}()
+
+	raise := Transition{To: ClusterState{SignalIssuesDetected}, Added: []Signal{SignalIssuesDetected}}
+	soundTransition(Transition{To: ClusterState{SignalPullRequestsOpen}, Added: []Signal{SignalPullRequestsOpen}})
+	soundTransition(raise)
+	soundTransition(raise) // rate limited
+	if len(recording) != 1 || recording[0].Action != "media_player.play_media" {
+		t.Fatalf("recorded %+v, want one play_media call", recording)
+	}
+
+	delete(notifyLastSent, "sound")
+	quietHours, _ = parseQuietHours("00:00-24:00")
+	soundTransition(raise)
+	if len(recording) != 1 {
+		t.Errorf("played a sound during quiet hours")
+	}
+}
+
+func TestNtfyRateLimited(t *testing.T) {
+	notifyDriver, ntfyInterval = "recording", time.Hour
+	recording = nil
+	defer func() {
+		notifyDriver, ntfyInterval, recording = "ntfy", 0, nil
+		delete(notifyLastSent, "ntfy/cluster-state")
+		delete(notifyLastSent, "ntfy/CI failing: main")
+	}()
+
+	raise := Transition{From: ClusterState{SignalHealthy}, To: ClusterState{SignalIssuesDetected}, Added: []Signal{SignalIssuesDetected}}
+	ntfyTransition(raise)
+	ntfyTransition(Transition{From: raise.To, To: raise.From, Removed: raise.Added}) // rate limited
+	SendNtfyAlert("CI is failing", NtfyOptions{Title: "CI failing: main", Priority: 4})
+	SendNtfyAlert("CI is failing", NtfyOptions{Title: "CI failing: main", Priority: 4}) // rate limited
+	SendNtfyAlert("Pod crashing", NtfyOptions{Title: "CI failing: main", Priority: 5})
+	if len(recording) != 3 {
+		t.Errorf("recorded %d notifications, want one per subject plus the urgent one: %+v", len(recording), recording)
+	}
+}
+
+func TestLightArea(t *testing.T) {
+	var turnedOn map[string]interface{}
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/template":
+			var body struct{ Template string }
+			json.NewDecoder(r.Body).Decode(&body)
+			if !strings.Contains(body.Template, `area_entities("shelf")`) {
+				t.Errorf("unexpected template %s", body.Template)
+			}
+			w.Write([]byte(`["light.shelf_left", "light.shelf_right"]`))
+		case "/api/services/light/turn_on":
+			json.NewDecoder(r.Body).Decode(&turnedOn)
+		}
+	}))
+	defer ha.Close()
+	haUrl, haLightArea, haColorMode = ha.URL, "shelf", "rgb_color"
+	haToken.Set("token")
+	defer func() {
+		haUrl, haLightArea, haColorMode, haLightMembers = "", "", "", nil
+		haToken.Set("")
+		haLightMembersCheckedAt = time.Time{}
+	}()
+
+	haShowSignal(context.Background(), SignalPullRequestsOpen)
+	if turnedOn["entity_id"] != "light.shelf_left, light.shelf_right" {
+		t.Errorf("light.turn_on payload = %v", turnedOn)
+	}
+}
+
+func TestReplayMissedCommands(t *testing.T) {
+	down := true
+	var received []map[string]interface{}
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload)
+	}))
+	defer ha.Close()
+	haUrl = ha.URL
+	haToken.Set("token")
+	defer func() {
+		haUrl = ""
+		haToken.Set("")
+		haPending = map[string]*haPendingCommand{}
+		delete(integrations, "homeassistant")
+	}()
+
+	ctx := context.Background()
+	haSetBulbColors(ctx, "light.a", "rgb_color", []int{255, 0, 0}, 255, 0)
+	haSetBulbColors(ctx, "light.a", "rgb_color", []int{0, 255, 0}, 255, 0)
+	haSetBulbColors(ctx, "light.b", "rgb_color", []int{0, 0, 255}, 255, 0)
+	haCallService(ctx, "media_player", "play_media", map[string]interface{}{"entity_id": "media_player.kitchen"})
+	if len(haPending) != 2 {
+		t.Fatalf("queued %d commands, want one per light", len(haPending))
+	}
+	metrics := metricsText(t)
+	for _, want := range []string{
+		"clusterbulb_ha_pending_commands 2",
+		`clusterbulb_integration_up{integration="homeassistant"} 0`,
+		`clusterbulb_integration_failures_total{integration="homeassistant",reason="ha_unreachable"}`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("metrics lack %s", want)
+		}
+	}
+
+	down = false
+	haReplayPending(ctx)
+	if len(received) != 2 || len(haPending) != 0 {
+		t.Fatalf("replayed %v, %d still pending", received, len(haPending))
+	}
+	if !strings.Contains(metricsText(t), `clusterbulb_integration_up{integration="homeassistant"} 1`) {
+		t.Error("homeassistant not up after the replay")
+	}
+	for _, p := range received {
+		if p["entity_id"] == "light.a" && fmt.Sprint(p["rgb_color"]) != "[0 255 0]" {
+			t.Errorf("light.a replayed %v, want the latest color", p["rgb_color"])
+		}
+	}
+}
+
+func TestReplaySkipsSupersededCommands(t *testing.T) {
+	down := true
+	var received []map[string]interface{}
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.Method != http.MethodPost {
+			fmt.Fprint(w, `{"state":"on"}`)
+			return
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received = append(received, payload)
+	}))
+	defer ha.Close()
+	haUrl, haLightEntityId = ha.URL, "light.clusterbulb"
+	haToken.Set("token")
+	clusterState, haLastColorState = ClusterState{SignalHealthy}, ""
+	defer func() {
+		haUrl, haLightEntityId = "", ""
+		haToken.Set("")
+		clusterState, haLastColorState = nil, ""
+		haPending = map[string]*haPendingCommand{}
+		delete(integrations, "homeassistant")
+	}()
+
+	ctx := context.Background()
+	haSetBulbColors(ctx, "light.clusterbulb", "rgb_color", []int{255, 0, 0}, 255, 0)
+	haSetBulbColors(ctx, "light.shelf", "rgb_color", []int{0, 0, 255}, 255, 0)
+
+	// The bulb's current color goes out first and supersedes its queued red, the shelf catches up after
+	down = false
+	haUpdateBulb(ctx)
+	if len(received) != 2 || received[0]["entity_id"] != "light.clusterbulb" || received[1]["entity_id"] != "light.shelf" {
+		t.Fatalf("received %v, want the bulb's current color then the shelf's replay", received)
+	}
+	if color := fmt.Sprint(received[0]["rgb_color"]); color == "[255 0 0]" {
+		t.Errorf("bulb got the stale color %s", color)
+	}
+	if len(haPending) != 0 {
+		t.Errorf("%d commands still pending", len(haPending))
+	}
+}
+
+func metricsText(t *testing.T) string {
+	w := httptest.NewRecorder()
+	metricsHandler(w, httptest.NewRequest("GET", "/metrics", nil))
+	return w.Body.String()
+}
+
+func TestFailedSceneActivationIsRetried(t *testing.T) {
+	fail := true
+	activations := 0
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		activations++
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ha.Close()
+	haUrl = ha.URL
+	haToken.Set("token")
+	haStateTargets[SignalIssuesDetected] = "scene.red_alert"
+	defer func() {
+		haUrl, haLastTarget = "", ""
+		haToken.Set("")
+		delete(haStateTargets, SignalIssuesDetected)
+		delete(integrations, "homeassistant")
+	}()
+
+	ctx := context.Background()
+	haShowSignal(ctx, SignalIssuesDetected)
+	if haLastTarget != "" {
+		t.Fatalf("failed activation recorded as %q", haLastTarget)
+	}
+	fail = false
+	haShowSignal(ctx, SignalIssuesDetected)
+	haShowSignal(ctx, SignalIssuesDetected)
+	if haLastTarget != "scene.red_alert" || activations != 2 {
+		t.Errorf("last target %q after %d activations, want scene.red_alert after 2", haLastTarget, activations)
+	}
+}
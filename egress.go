@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var egressCheckUrl = "" // os.Getenv("EGRESS_CHECK_URL") // e.g. https://www.google.com/generate_204, empty disables
+
+// checkEgress sends a HEAD request to EGRESS_CHECK_URL and raises a warning when the
+// cluster can't reach the internet; GitHub and ntfy errors are usually explained by this
+func checkEgress(ctx context.Context, _ kubernetes.Interface) []Issue {
+	failed := func(format string, args ...interface{}) []Issue {
+		message := fmt.Sprintf("Egress check to %s failed: %s (GitHub and ntfy calls will fail too)", egressCheckUrl, fmt.Sprintf(format, args...))
+		return []Issue{{Key: "egress", Type: "Egress", Severity: "warning", Message: message, Timestamp: time.Now()}}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", egressCheckUrl, nil)
+	if err != nil {
+		return failed("%v", err)
+	}
+	resp, err := httpClient("egress").Do(req)
+	if err != nil {
+		return failed("%v", err)
+	}
+	resp.Body.Close()
+
+	// Any response proves connectivity, except gateway errors from an egress proxy
+	if resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusGatewayTimeout {
+		return failed("%s", resp.Status)
+	}
+	return nil
+}
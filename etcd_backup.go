@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var etcdBackupPath = ""        // os.Getenv("ETCD_BACKUP_PATH") // directory of snapshot files, e.g. a hostPath mount of /var/lib/rancher/k3s/server/db/snapshots
+var etcdBackupMaxAgeHours = 24 // os.Getenv("ETCD_BACKUP_MAX_AGE_HOURS") // warn when the newest snapshot is older than this
+
+// ETCDSnapshotFileList represents the k3s/RKE2 ETCDSnapshotFile CRs of the cluster
+type ETCDSnapshotFileList struct {
+	Items []struct {
+		Spec struct {
+			SnapshotName string `json:"snapshotName"`
+			Location     string `json:"location"`
+		} `json:"spec"`
+		Status struct {
+			CreationTime *time.Time `json:"creationTime"`
+			ReadyToUse   *bool      `json:"readyToUse"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// checkEtcdBackups warns when the newest etcd snapshot is older than ETCD_BACKUP_MAX_AGE_HOURS.
+// Snapshots are read from k3s/RKE2 ETCDSnapshotFile CRs when installed, and from ETCD_BACKUP_PATH
+// when set; the check is skipped when neither source exists.
+func checkEtcdBackups(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var newest time.Time
+	var newestName string
+	sources := 0
+
+	var snapshots ETCDSnapshotFileList
+	found, err := listCustomResources(ctx, clientset, "k3s.cattle.io/v1", "etcdsnapshotfiles", &snapshots)
+	if err != nil {
+		log.Printf("Error fetching etcd snapshots: %v", err)
+	}
+	if found {
+		sources++
+		for _, s := range snapshots.Items {
+			if s.Status.CreationTime == nil || (s.Status.ReadyToUse != nil && !*s.Status.ReadyToUse) {
+				continue
+			}
+			if s.Status.CreationTime.After(newest) {
+				newest, newestName = *s.Status.CreationTime, s.Spec.SnapshotName
+			}
+		}
+	}
+
+	if etcdBackupPath != "" {
+		sources++
+		entries, err := os.ReadDir(etcdBackupPath)
+		if err != nil {
+			log.Printf("Error reading %s: %v", etcdBackupPath, err)
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || info.IsDir() {
+				continue
+			}
+			if info.ModTime().After(newest) {
+				newest, newestName = info.ModTime(), filepath.Join(etcdBackupPath, entry.Name())
+			}
+		}
+	}
+
+	if sources == 0 {
+		return nil
+	}
+
+	maxAge := time.Duration(etcdBackupMaxAgeHours) * time.Hour
+	var message string
+	switch {
+	case newest.IsZero():
+		message = "No etcd snapshots found"
+	case time.Since(newest) > maxAge:
+		message = fmt.Sprintf("Newest etcd snapshot %s is %s old (max %dh)", newestName, time.Since(newest).Round(time.Minute), etcdBackupMaxAgeHours)
+	default:
+		return nil
+	}
+	return []Issue{{Key: "etcd/backup", Type: "Backup", Severity: "warning", Message: message, Timestamp: time.Now()}}
+}
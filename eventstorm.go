@@ -0,0 +1,40 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var eventStormThreshold = 50 // os.Getenv("EVENT_STORM_THRESHOLD") // warning events per cycle collapsed into one issue, 0 disables
+
+// collapseEventStorm replaces the event issues of a cycle with a single summary issue when there
+// are more than EVENT_STORM_THRESHOLD of them, keeping the report and notifications readable
+func collapseEventStorm(issues []Issue) []Issue {
+	if eventStormThreshold <= 0 || len(issues) <= eventStormThreshold {
+		return issues
+	}
+
+	namespaces := map[string]bool{}
+	reasons := map[string]int{}
+	for _, issue := range issues {
+		namespaces[issue.Namespace] = true
+		reasons[issue.Reason]++
+	}
+	top := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		top = append(top, reason)
+	}
+	slices.SortFunc(top, func(a, b string) int {
+		return cmp.Or(cmp.Compare(reasons[b], reasons[a]), cmp.Compare(a, b))
+	})
+	var parts []string
+	for _, reason := range top[:min(len(top), 3)] {
+		parts = append(parts, fmt.Sprintf("%s %d", reason, reasons[reason]))
+	}
+
+	msg := fmt.Sprintf("Event storm: %d warnings across %d namespaces (top reasons: %s)", len(issues), len(namespaces), strings.Join(parts, ", "))
+	return []Issue{{Key: "event-storm", Type: "EventStorm", Message: msg, Timestamp: time.Now(), Reason: "EventStorm", Count: len(issues)}}
+}
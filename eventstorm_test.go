@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestCollapseEventStorm(t *testing.T) {
+	defer func(v int) { eventStormThreshold = v }(eventStormThreshold)
+	eventStormThreshold = 4
+
+	issue := func(ns, reason string) Issue {
+		return Issue{Key: ns + "/x:" + reason, Type: "Event", Namespace: ns, Reason: reason}
+	}
+	few := []Issue{issue("a", "BackOff"), issue("b", "BackOff")}
+	if got := collapseEventStorm(few); len(got) != 2 {
+		t.Errorf("collapsed %d events below the threshold", len(few))
+	}
+
+	storm := append(few, issue("a", "BackOff"), issue("c", "FailedMount"), issue("c", "FailedMount"), issue("a", "Unhealthy"), issue("b", "Evicted"))
+	got := collapseEventStorm(storm)
+	if len(got) != 1 {
+		t.Fatalf("got %d issues, want one event storm issue", len(got))
+	}
+	want := "Event storm: 7 warnings across 3 namespaces (top reasons: BackOff 3, FailedMount 2, Evicted 1)"
+	if got[0].Message != want || got[0].Count != 7 {
+		t.Errorf("event storm issue = %q (count %d), want %q", got[0].Message, got[0].Count, want)
+	}
+}
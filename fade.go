@@ -0,0 +1,24 @@
+package main
+
+import (
+	"slices"
+)
+
+var haLightTransition = 0.0      // os.Getenv("HA_LIGHT_TRANSITION") // seconds to fade between colors, 0 snaps
+var haBreatheStates = []Signal{} // os.Getenv("HA_BREATHE_STATES") // e.g. warning_events
+
+// haBreatheStages are the brightness fractions a breathing state steps through, one per bulb update
+var haBreatheStages = []float64{1, 0.75, 0.5, 0.25, 0.5, 0.75}
+var haBreatheStep = 0
+
+// haBrightness returns the brightness and transition for the next update of the signal;
+// breathing states step through haBreatheStages and fade for at least one update interval
+func haBrightness(signal Signal) (int, float64) {
+	brightness := scaledBrightness(signal)
+	if brightness == 0 || !slices.Contains(haBreatheStates, signal) || slices.Contains(scheduleSteady, signal) {
+		return brightness, haLightTransition
+	}
+	stage := haBreatheStages[haBreatheStep%len(haBreatheStages)]
+	haBreatheStep++
+	return max(1, int(float64(brightness)*stage)), max(1, haLightTransition)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var falcoWebhookToken = ""           // os.Getenv("FALCO_WEBHOOK_TOKEN") // enables the /webhooks/falco endpoint
+var falcoAlertTTL = 15 * time.Minute // os.Getenv("FALCO_ALERT_TTL_MINUTES") // alerts clear when their rule doesn't fire again within this time
+
+// falcoSeverities maps Falco rule priorities to issue severities
+var falcoSeverities = map[string]string{
+	"emergency":     "critical",
+	"alert":         "critical",
+	"critical":      "critical",
+	"error":         "warning",
+	"warning":       "warning",
+	"notice":        "info",
+	"informational": "info",
+	"info":          "info",
+	"debug":         "info",
+}
+
+// falcoEvent holds the fields of interest of a Falco http_output alert
+type falcoEvent struct {
+	Output       string                 `json:"output"`
+	Priority     string                 `json:"priority"`
+	Rule         string                 `json:"rule"`
+	Time         time.Time              `json:"time"`
+	Hostname     string                 `json:"hostname"`
+	OutputFields map[string]interface{} `json:"output_fields"`
+}
+
+var falcoMu sync.Mutex
+var falcoAlerts = map[string]*Issue{}
+
+// falcoWebhookHandler receives Falco alerts sent by http_output. Falco can't set headers, so the
+// token is also accepted as the token query parameter, e.g. http://clusterbulb:8080/webhooks/falco?token=...
+func falcoWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(falcoWebhookToken)) != 1 {
+		log.Printf("Rejected Falco alert with invalid token from %s", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	var event falcoEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Rule == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	recordFalcoEvent(event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// recordFalcoEvent turns the alert into an issue per rule and pod (or host), notifying right away
+// the first time a critical rule fires
+func recordFalcoEvent(event falcoEvent) {
+	severity, ok := falcoSeverities[strings.ToLower(event.Priority)]
+	if !ok {
+		severity = "warning"
+	}
+	namespace, _ := event.OutputFields["k8s.ns.name"].(string)
+	pod, _ := event.OutputFields["k8s.pod.name"].(string)
+	target := event.Hostname
+	if pod != "" {
+		target = namespace + "/" + pod
+	}
+	key := fmt.Sprintf("falco/%s/%s", strings.ReplaceAll(strings.ToLower(event.Rule), " ", "-"), target)
+
+	falcoMu.Lock()
+	issue, seen := falcoAlerts[key]
+	if !seen {
+		issue = &Issue{Key: key, Type: "FalcoAlert", Severity: severity, Namespace: namespace, Name: pod, Reason: event.Rule}
+		if pod != "" {
+			issue.Kind = "Pod"
+		}
+		falcoAlerts[key] = issue
+	}
+	issue.Message = fmt.Sprintf("Falco %s: %s", event.Priority, event.Output)
+	issue.Timestamp = time.Now()
+	issue.Count++
+	falcoMu.Unlock()
+
+	log.Printf("Falco alert %s (%s) on %s", event.Rule, event.Priority, target)
+	if seen || severity != "critical" {
+		return
+	}
+	ntfyOpts := NtfyOptions{
+		Title:    fmt.Sprintf("Falco: %s", event.Rule),
+		Priority: 5, // (required)
+		Tags:     "rotating_light",
+		Active: func() bool {
+			falcoMu.Lock()
+			defer falcoMu.Unlock()
+			_, ok := falcoAlerts[key]
+			return ok
+		},
+	}
+	if err := SendNtfyAlert(event.Output, ntfyOpts); err != nil {
+		log.Printf("Error sending ntfy alert: %v", err)
+	}
+	requestClusterRecheck()
+}
+
+// checkFalco returns the Falco alerts that fired within FALCO_ALERT_TTL_MINUTES
+func checkFalco(_ context.Context, _ kubernetes.Interface) []Issue {
+	falcoMu.Lock()
+	defer falcoMu.Unlock()
+	var issues []Issue
+	for key, issue := range falcoAlerts {
+		if time.Since(issue.Timestamp) > falcoAlertTTL {
+			delete(falcoAlerts, key)
+			continue
+		}
+		issues = append(issues, *issue)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFalcoWebhook(t *testing.T) {
+	falcoWebhookToken, notifyDriver = "secret", "noop"
+	defer func() {
+		falcoWebhookToken, notifyDriver = "", "ntfy"
+		falcoAlerts = map[string]*Issue{}
+	}()
+
+	alert := `{"output":"Shell spawned in container","priority":"Critical","rule":"Terminal shell in container",
+		"output_fields":{"k8s.ns.name":"prod","k8s.pod.name":"api-0"}}`
+	for _, tt := range []struct {
+		token string
+		want  int
+	}{
+		{"wrong", http.StatusUnauthorized},
+		{"secret", http.StatusNoContent},
+		{"secret", http.StatusNoContent},
+	} {
+		w := httptest.NewRecorder()
+		falcoWebhookHandler(w, httptest.NewRequest(http.MethodPost, "/webhooks/falco?token="+tt.token, strings.NewReader(alert)))
+		if w.Code != tt.want {
+			t.Errorf("token %s: status %d, want %d", tt.token, w.Code, tt.want)
+		}
+	}
+
+	issues := checkFalco(nil, nil)
+	if len(issues) != 1 {
+		t.Fatalf("got %d issues, want 1", len(issues))
+	}
+	if got := issues[0]; got.Key != "falco/terminal-shell-in-container/prod/api-0" || got.Severity != "critical" || got.Count != 2 {
+		t.Errorf("issue = %+v", got)
+	}
+}
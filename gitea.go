@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var giteaUrl = ""   // os.Getenv("GITEA_URL") // e.g. https://gitea.example.com
+var giteaOwner = "" // os.Getenv("GITEA_OWNER")
+var giteaRepo = ""  // os.Getenv("GITEA_REPO")
+var giteaToken = "" // os.Getenv("GITEA_TOKEN") // optional for public repositories
+
+// giteaProvider polls a Gitea or Forgejo instance for open pull requests. The
+// Gitea API mirrors GitHub's pull request payload, so it decodes into PullRequest directly.
+type giteaProvider struct{}
+
+// CombinedStatus represents the combined commit status of a ref
+type CombinedStatus struct {
+	State      string `json:"state"` // pending, success, error, failure, warning
+	TotalCount int    `json:"total_count"`
+}
+
+func (giteaProvider) Name() string {
+	return "Gitea"
+}
+
+func (giteaProvider) OpenPullRequests(ctx context.Context) ([]PullRequest, error) {
+	var prs []PullRequest
+	url := fmt.Sprintf("%s/api/v1/repos/%s/%s/pulls?state=open&limit=50", strings.TrimRight(giteaUrl, "/"), giteaOwner, giteaRepo)
+	// Gitea paginates like GitHub, with a Link header to the next page
+	for url != "" {
+		var page []PullRequest
+		next, err := giteaGetPage(ctx, url, &page)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, page...)
+		url = next
+	}
+	return prs, nil
+}
+
+func (giteaProvider) CIStatus(ctx context.Context, branch string) (string, error) {
+	var status CombinedStatus
+	url := fmt.Sprintf("%s/api/v1/repos/%s/%s/commits/%s/status", strings.TrimRight(giteaUrl, "/"), giteaOwner, giteaRepo, url.PathEscape(branch))
+	if err := giteaGet(ctx, url, &status); err != nil {
+		return "", err
+	}
+
+	if status.TotalCount == 0 {
+		return "", nil
+	}
+	switch status.State {
+	case "failure", "error":
+		return "failure", nil
+	case "pending":
+		return "pending", nil
+	default:
+		return "success", nil
+	}
+}
+
+func (giteaProvider) MergeStatus(ctx context.Context, pr PullRequest) (string, error) {
+	// Gitea reports mergeability directly in the pull request list
+	if pr.Mergeable != nil && !*pr.Mergeable {
+		return "conflict", nil
+	}
+	return "", nil
+}
+
+// giteaGet performs an authenticated Gitea API GET request and decodes the JSON response into v
+func giteaGet(ctx context.Context, url string, v interface{}) error {
+	_, err := giteaGetPage(ctx, url, v)
+	return err
+}
+
+// giteaGetPage performs an authenticated Gitea API GET request, decodes the JSON response into v,
+// and returns the URL of the next page if there is one
+func giteaGetPage(ctx context.Context, url string, v interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if giteaToken != "" {
+		req.Header.Set("Authorization", "token "+giteaToken)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient("gitea").Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Gitea API returned status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nextPageURL(resp.Header.Get("Link")), nil
+}
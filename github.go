@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ghApiUrl = "https://api.github.com" // os.Getenv("GH_API_URL") // e.g. https://github.example.com/api/v3 for GitHub Enterprise Server
+
+// githubProvider polls GitHub for open pull requests, either for a single
+// GH_OWNER/GH_REPO or across GH_ORG
+type githubProvider struct{}
+
+// CheckRunsResult represents a GitHub check runs response for a commit
+type CheckRunsResult struct {
+	TotalCount int `json:"total_count"`
+	CheckRuns  []struct {
+		Name       string `json:"name"`
+		Status     string `json:"status"`     // queued, in_progress, completed
+		Conclusion string `json:"conclusion"` // success, failure, neutral, cancelled, skipped, timed_out, action_required
+	} `json:"check_runs"`
+}
+
+func (githubProvider) Name() string {
+	return "GitHub"
+}
+
+func (githubProvider) OpenPullRequests(ctx context.Context) ([]PullRequest, error) {
+	var prs []PullRequest
+	if ghUseGraphQL {
+		var err error
+		if prs, err = ghGraphQLPullRequests(ctx); err != nil {
+			return nil, err
+		}
+	} else if ghOrg != "" {
+		var err error
+		if prs, err = ghSearchOrgPullRequests(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		url := fmt.Sprintf("%s/repos/%s/%s/pulls?state=open&per_page=100", ghApiUrl, ghOwner, ghRepo)
+		var err error
+		if prs, err = ghGetAll[PullRequest](ctx, url); err != nil {
+			return nil, err
+		}
+	}
+
+	// Personal review light: only PRs waiting on GH_REVIEWER count
+	if ghReviewer != "" {
+		return ghReviewerPullRequests(ctx, prs)
+	}
+	return prs, nil
+}
+
+func (githubProvider) CIStatus(ctx context.Context, branch string) (string, error) {
+	// CI status is tracked per repository, so there is nothing to report when scanning an org
+	if ghOrg != "" {
+		return "", nil
+	}
+
+	return ghCheckRunsState(ctx, fmt.Sprintf("%s/repos/%s/%s", ghApiUrl, ghOwner, ghRepo), branch)
+}
+
+func (githubProvider) MergeStatus(ctx context.Context, pr PullRequest) (string, error) {
+	// GraphQL results already carry mergeability and the head commit's check rollup
+	if pr.fromGraphQL {
+		switch {
+		case pr.Mergeable != nil && !*pr.Mergeable:
+			return "conflict", nil
+		case pr.ChecksState == "FAILURE" || pr.ChecksState == "ERROR":
+			return "blocked", nil
+		}
+		return "", nil
+	}
+
+	var detail PullRequestDetail
+	if err := ghGet(ctx, pr.apiURL(), &detail); err != nil {
+		return "", err
+	}
+
+	switch detail.MergeableState {
+	case "dirty":
+		return "conflict", nil
+	case "blocked":
+		// Blocked also covers PRs that are simply awaiting review, so only
+		// report it when the head commit has failing checks
+		state, err := ghCheckRunsState(ctx, pr.repoAPIURL(), detail.Head.SHA)
+		if err != nil {
+			return "", err
+		}
+		if state == "failure" {
+			return "blocked", nil
+		}
+	}
+	return "", nil
+}
+
+// ghCheckRunsState summarizes the check runs of a ref in the repository at repoURL
+// as "success", "failure", "pending", or "" when there are none
+func ghCheckRunsState(ctx context.Context, repoURL string, ref string) (string, error) {
+	var result CheckRunsResult
+	if err := ghGet(ctx, fmt.Sprintf("%s/commits/%s/check-runs", repoURL, url.PathEscape(ref)), &result); err != nil {
+		return "", err
+	}
+
+	state := ""
+	for _, run := range result.CheckRuns {
+		switch {
+		case run.Status != "completed":
+			if state == "" || state == "success" {
+				state = "pending"
+			}
+		case run.Conclusion == "failure" || run.Conclusion == "timed_out" || run.Conclusion == "cancelled" || run.Conclusion == "action_required":
+			return "failure", nil
+		default:
+			if state == "" {
+				state = "success"
+			}
+		}
+	}
+	return state, nil
+}
+
+// ghGet performs an authenticated GitHub API GET request and decodes the JSON response into v
+func ghGet(ctx context.Context, url string, v interface{}) error {
+	_, err := ghGetPage(ctx, url, v)
+	return err
+}
+
+// ghGetAll follows the Link headers of a paginated GitHub list endpoint and returns every item
+func ghGetAll[T any](ctx context.Context, url string) ([]T, error) {
+	var all []T
+	for url != "" {
+		var page []T
+		next, err := ghGetPage(ctx, url, &page)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		url = next
+	}
+	return all, nil
+}
+
+// ghGetPage performs an authenticated GitHub API GET request, decodes the JSON response into v,
+// and returns the URL of the next page if there is one. Requests are skipped while rate limited,
+// and use ETags so unchanged responses don't consume quota.
+func ghGetPage(ctx context.Context, url string, v interface{}) (string, error) {
+	if time.Now().Before(ghRateLimitReset) {
+		return "", fmt.Errorf("%w, retrying after %s", ErrGitHubRateLimited, ghRateLimitReset.Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set Authorization header if token is provided (recommended)
+	if ghToken.Get() != "" {
+		req.Header.Set("Authorization", "token "+ghToken.Get())
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	cached, hasCached := ghETagCache[url]
+	if hasCached {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := httpClient("github").Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	ghUpdateRateLimit(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && hasCached:
+		cached.LastUsed = time.Now()
+		ghETagCache[url] = cached
+		return cached.Next, json.Unmarshal(cached.Body, v)
+	case resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && time.Now().Before(ghRateLimitReset)):
+		return "", fmt.Errorf("%w, retrying after %s", ErrGitHubRateLimited, ghRateLimitReset.Format(time.RFC3339))
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	next := nextPageURL(resp.Header.Get("Link"))
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		ghCacheResponse(url, etag, body, next)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return next, nil
+}
+
+// nextPageURL extracts the rel="next" URL from a Link header, e.g.
+// <https://api.github.com/repositories/1/pulls?page=2>; rel="next", <...>; rel="last"
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if ok && strings.Contains(params, `rel="next"`) {
+			return strings.Trim(strings.TrimSpace(target), "<>")
+		}
+	}
+	return ""
+}
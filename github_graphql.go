@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ghUseGraphQL = false // os.Getenv("GH_USE_GRAPHQL") == "true" // requires GH_TOKEN
+
+// ghPullRequestsQuery fetches open PRs together with their review, mergeability
+// and check state, so no follow-up REST calls are needed per PR
+const ghPullRequestsQuery = `query($q: String!, $cursor: String) {
+  search(query: $q, type: ISSUE, first: 100, after: $cursor) {
+    issueCount
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ... on PullRequest {
+        number
+        title
+        url
+        isDraft
+        createdAt
+        updatedAt
+        author { login }
+        repository { name repositoryTopics(first: 20) { nodes { topic { name } } } }
+        labels(first: 20) { nodes { name } }
+        mergeable
+        reviewDecision
+        reviewRequests(first: 20) { nodes { requestedReviewer { ... on User { login } } } }
+        commits(last: 1) { nodes { commit { oid statusCheckRollup { state } } } }
+      }
+    }
+  }
+}`
+
+// ghGraphQLSearchResult represents the response of ghPullRequestsQuery
+type ghGraphQLSearchResult struct {
+	Data struct {
+		Search struct {
+			IssueCount int `json:"issueCount"`
+			PageInfo   struct {
+				HasNextPage bool   `json:"hasNextPage"`
+				EndCursor   string `json:"endCursor"`
+			} `json:"pageInfo"`
+			Nodes []struct {
+				Number     int       `json:"number"`
+				Title      string    `json:"title"`
+				URL        string    `json:"url"`
+				IsDraft    bool      `json:"isDraft"`
+				CreatedAt  time.Time `json:"createdAt"`
+				UpdatedAt  time.Time `json:"updatedAt"`
+				Author     User      `json:"author"`
+				Repository struct {
+					Name             string `json:"name"`
+					RepositoryTopics struct {
+						Nodes []ghRepositoryTopic `json:"nodes"`
+					} `json:"repositoryTopics"`
+				} `json:"repository"`
+				Labels struct {
+					Nodes []Label `json:"nodes"`
+				} `json:"labels"`
+				Mergeable      string `json:"mergeable"`      // MERGEABLE, CONFLICTING, UNKNOWN
+				ReviewDecision string `json:"reviewDecision"` // APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED
+				ReviewRequests struct {
+					Nodes []struct {
+						RequestedReviewer User `json:"requestedReviewer"`
+					} `json:"nodes"`
+				} `json:"reviewRequests"`
+				Commits struct {
+					Nodes []struct {
+						Commit struct {
+							OID               string `json:"oid"`
+							StatusCheckRollup *struct {
+								State string `json:"state"` // SUCCESS, FAILURE, ERROR, PENDING, EXPECTED
+							} `json:"statusCheckRollup"`
+						} `json:"commit"`
+					} `json:"nodes"`
+				} `json:"commits"`
+			} `json:"nodes"`
+		} `json:"search"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// ghRepositoryTopic represents a topic attached to a GitHub repository
+type ghRepositoryTopic struct {
+	Topic struct {
+		Name string `json:"name"`
+	} `json:"topic"`
+}
+
+// ghGraphQLURL returns the GraphQL endpoint for GH_API_URL; GitHub Enterprise Server
+// serves it at /api/graphql rather than under /api/v3
+func ghGraphQLURL() string {
+	if base, ok := strings.CutSuffix(ghApiUrl, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+	return ghApiUrl + "/graphql"
+}
+
+// ghGraphQLPullRequests fetches the open PRs of GH_OWNER/GH_REPO, or of every repo in GH_ORG,
+// in as few requests as possible (one per 100 PRs)
+func ghGraphQLPullRequests(ctx context.Context) ([]PullRequest, error) {
+	q := fmt.Sprintf("is:pr is:open repo:%s/%s", ghOwner, ghRepo)
+	if ghOrg != "" {
+		q = fmt.Sprintf("is:pr is:open org:%s", ghOrg)
+	}
+
+	var prs []PullRequest
+	cursor := ""
+	for {
+		vars := map[string]interface{}{"q": q}
+		if cursor != "" {
+			vars["cursor"] = cursor
+		}
+		var result ghGraphQLSearchResult
+		if err := ghGraphQL(ctx, ghPullRequestsQuery, vars, &result); err != nil {
+			return nil, err
+		}
+		if len(result.Errors) > 0 {
+			return nil, fmt.Errorf("GitHub GraphQL API returned error: %s", result.Errors[0].Message)
+		}
+
+		for _, n := range result.Data.Search.Nodes {
+			// Search may return non-PR nodes as empty objects
+			if n.Number == 0 {
+				continue
+			}
+			if ghOrg != "" && !ghRepoSelected(n.Repository.Name, n.Repository.RepositoryTopics.Nodes) {
+				continue
+			}
+
+			pr := PullRequest{
+				Number:         n.Number,
+				Title:          n.Title,
+				User:           n.Author,
+				State:          "open",
+				Draft:          n.IsDraft,
+				Labels:         n.Labels.Nodes,
+				HTMLURL:        n.URL,
+				CreatedAt:      n.CreatedAt,
+				UpdatedAt:      n.UpdatedAt,
+				ReviewDecision: n.ReviewDecision,
+				fromGraphQL:    true,
+			}
+			// Keep keys identical to the REST path: only org scans are qualified with the repo name
+			if ghOrg != "" {
+				pr.RepositoryURL = fmt.Sprintf("%s/repos/%s/%s", ghApiUrl, ghOrg, n.Repository.Name)
+			}
+			for _, r := range n.ReviewRequests.Nodes {
+				if r.RequestedReviewer.Login != "" {
+					pr.RequestedReviewers = append(pr.RequestedReviewers, r.RequestedReviewer)
+				}
+			}
+			if n.Mergeable != "UNKNOWN" {
+				mergeable := n.Mergeable == "MERGEABLE"
+				pr.Mergeable = &mergeable
+			}
+			if len(n.Commits.Nodes) > 0 {
+				commit := n.Commits.Nodes[0].Commit
+				pr.Head.SHA = commit.OID
+				if commit.StatusCheckRollup != nil {
+					pr.ChecksState = commit.StatusCheckRollup.State
+				}
+			}
+			prs = append(prs, pr)
+		}
+
+		if !result.Data.Search.PageInfo.HasNextPage {
+			break
+		}
+		cursor = result.Data.Search.PageInfo.EndCursor
+	}
+	return prs, nil
+}
+
+// ghRepoSelected applies GH_REPO_TOPIC and GH_REPO_GLOB to a repository found by an org scan
+func ghRepoSelected(name string, topics []ghRepositoryTopic) bool {
+	if ghRepoTopic != "" && !slices.ContainsFunc(topics, func(t ghRepositoryTopic) bool { return t.Topic.Name == ghRepoTopic }) {
+		return false
+	}
+	if ghRepoGlob != "" {
+		if ok, _ := path.Match(ghRepoGlob, name); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ghGraphQL sends a GraphQL query to GitHub and decodes the response into v
+func ghGraphQL(ctx context.Context, query string, vars map[string]interface{}, v interface{}) error {
+	if ghToken.Get() == "" {
+		return fmt.Errorf("GitHub GraphQL API requires GH_TOKEN")
+	}
+	if time.Now().Before(ghRateLimitReset) {
+		return fmt.Errorf("%w, retrying after %s", ErrGitHubRateLimited, ghRateLimitReset.Format(time.RFC3339))
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("failed to marshal query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", ghGraphQLURL(), bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "bearer "+ghToken.Get())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient("github-graphql").Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	ghUpdateRateLimit(resp)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub GraphQL API returned status: %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"time"
+)
+
+var ghIssueLabel = ""                        // os.Getenv("GH_ISSUE_LABEL") // e.g. "incident", empty disables
+var ghIssueLabelState = SignalIssuesDetected // os.Getenv("GH_ISSUE_LABEL_STATE") // bulb state shown while labeled issues are open
+var ghIssueState = "none"                    // "open" when labeled issues are open
+var incidents = []Issue{}                    // latest labeled GitHub issues for the health report
+
+// GitHubIssue represents a GitHub issue as returned by the issues and search APIs
+type GitHubIssue struct {
+	Number        int       `json:"number"`
+	Title         string    `json:"title"`
+	HTMLURL       string    `json:"html_url"`
+	RepositoryURL string    `json:"repository_url"`
+	PullRequest   *struct{} `json:"pull_request"` // set when the issue is a PR
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ghLabeledIssuesCheck raises an issue for every open GitHub issue carrying GH_ISSUE_LABEL,
+// so a manually filed incident ticket can drive the bulb
+func ghLabeledIssuesCheck(ctx context.Context) {
+
+	// Ensure the label and a repo or org are configured otherwise skip
+	if ghIssueLabel == "" || (ghOrg == "" && (ghOwner == "" || ghRepo == "")) || !integrationReady("github-issues") {
+		return
+	}
+
+	var ghIssues []GitHubIssue
+	if ghOrg != "" {
+		q := fmt.Sprintf("is:issue is:open org:%s label:%q", ghOrg, ghIssueLabel)
+		next := ghApiUrl + "/search/issues?per_page=100&q=" + url.QueryEscape(q)
+		for next != "" {
+			var result struct {
+				Items []GitHubIssue `json:"items"`
+			}
+			var err error
+			if next, err = ghGetPage(ctx, next, &result); err != nil {
+				HandleError("github-issues", "Error checking labeled issues:", err)
+				return
+			}
+			ghIssues = append(ghIssues, result.Items...)
+		}
+	} else {
+		var err error
+		u := fmt.Sprintf("%s/repos/%s/%s/issues?state=open&per_page=100&labels=%s", ghApiUrl, ghOwner, ghRepo, url.QueryEscape(ghIssueLabel))
+		if ghIssues, err = ghGetAll[GitHubIssue](ctx, u); err != nil {
+			HandleError("github-issues", "Error checking labeled issues:", err)
+			return
+		}
+	}
+
+	integrationOK("github-issues")
+
+	var issues []Issue
+	for _, i := range ghIssues {
+		// The issues endpoint also lists pull requests
+		if i.PullRequest != nil {
+			continue
+		}
+		key := fmt.Sprintf("issue/%d", i.Number)
+		if ghOrg != "" {
+			key = fmt.Sprintf("issue/%s/%d", path.Base(i.RepositoryURL), i.Number)
+		}
+		issues = append(issues, Issue{Key: key, Type: "GitHubIssue", Severity: "critical", Message: i.Title, Timestamp: time.Now()})
+	}
+
+	// Notify when a labeled issue is first opened
+	if len(issues) > 0 && ghIssueState == "none" {
+		ntfyOpts := NtfyOptions{
+			Title:    fmt.Sprintf("%s issues: %d", ghIssueLabel, len(issues)),
+			Priority: 4, // (required)
+			Key:      "incidents",
+			Active:   func() bool { return ghIssueState != "none" },
+		}
+		if err := SendNtfyAlert(fmt.Sprintf("#%s %s", issues[0].Key, issues[0].Message), ntfyOpts); err != nil {
+			log.Printf("Error sending ntfy alert: %v", err)
+		}
+	}
+
+	ghIssueState = "none"
+	if len(issues) > 0 {
+		ghIssueState = "open"
+	}
+	incidents = issues
+}
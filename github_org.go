@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+)
+
+// SearchIssuesResult represents a GitHub issue search response
+type SearchIssuesResult struct {
+	TotalCount int           `json:"total_count"`
+	Items      []PullRequest `json:"items"`
+}
+
+// SearchReposResult represents a GitHub repository search response
+type SearchReposResult struct {
+	TotalCount int `json:"total_count"`
+	Items      []struct {
+		Name string `json:"name"`
+	} `json:"items"`
+}
+
+// ghSearchOrgPullRequests finds open pull requests across all repositories in
+// GH_ORG using the search API, optionally restricted by GH_REPO_TOPIC and GH_REPO_GLOB
+func ghSearchOrgPullRequests(ctx context.Context) ([]PullRequest, error) {
+
+	// Resolve the set of repositories carrying the topic, if one is configured
+	var topicRepos map[string]bool
+	if ghRepoTopic != "" {
+		q := fmt.Sprintf("org:%s topic:%s", ghOrg, ghRepoTopic)
+		topicRepos = make(map[string]bool)
+		next := ghApiUrl + "/search/repositories?per_page=100&q=" + url.QueryEscape(q)
+		for next != "" {
+			var repos SearchReposResult
+			var err error
+			if next, err = ghGetPage(ctx, next, &repos); err != nil {
+				return nil, err
+			}
+			for _, r := range repos.Items {
+				topicRepos[r.Name] = true
+			}
+		}
+	}
+
+	// Follow the Link headers so orgs with more than 100 open PRs are counted accurately
+	q := fmt.Sprintf("is:pr is:open org:%s", ghOrg)
+	var items []PullRequest
+	next := ghApiUrl + "/search/issues?per_page=100&q=" + url.QueryEscape(q)
+	for next != "" {
+		var result SearchIssuesResult
+		var err error
+		if next, err = ghGetPage(ctx, next, &result); err != nil {
+			return nil, err
+		}
+		items = append(items, result.Items...)
+	}
+
+	var prs []PullRequest
+	for _, pr := range items {
+		repo := path.Base(pr.RepositoryURL)
+		if topicRepos != nil && !topicRepos[repo] {
+			continue
+		}
+		if ghRepoGlob != "" {
+			if ok, _ := path.Match(ghRepoGlob, repo); !ok {
+				continue
+			}
+		}
+		prs = append(prs, pr)
+	}
+	return prs, nil
+}
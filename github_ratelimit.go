@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var ghRateLimitRemaining = -1  // X-RateLimit-Remaining of the last response, -1 when unknown
+var ghRateLimitReset time.Time // no GitHub requests are sent before this time
+var ghETagCache = make(map[string]ghCachedResponse)
+
+// ghCachedResponse is the last successful response for a URL, replayed when GitHub answers 304 Not Modified
+type ghCachedResponse struct {
+	ETag     string
+	Body     []byte
+	Next     string // next page URL from the Link header
+	LastUsed time.Time
+}
+
+// GitHubQuota is the GitHub API rate limit as of the last response
+type GitHubQuota struct {
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset,omitzero"` // no requests are sent before this time
+}
+
+// ghQuota returns the GitHub API rate limit, or nil before the first GitHub response
+func ghQuota() *GitHubQuota {
+	if ghRateLimitRemaining < 0 {
+		return nil
+	}
+	return &GitHubQuota{Remaining: ghRateLimitRemaining, Reset: ghRateLimitReset}
+}
+
+// ghUpdateRateLimit records the rate limit headers of a GitHub response and
+// schedules a back off when the quota is exhausted or a Retry-After is sent
+func ghUpdateRateLimit(resp *http.Response) {
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		ghRateLimitRemaining = v
+	}
+
+	// Secondary rate limits send Retry-After, which takes precedence over the primary reset
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if v, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			ghRateLimitReset = time.Now().Add(time.Duration(v) * time.Second)
+			return
+		}
+	}
+
+	if ghRateLimitRemaining == 0 {
+		if v, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			ghRateLimitReset = time.Unix(v, 0)
+		}
+	}
+}
+
+// ghCacheResponse stores a response body by URL for conditional requests and
+// drops entries that have not been used for a day (e.g. closed PRs)
+func ghCacheResponse(url string, etag string, body []byte, next string) {
+	for k, cached := range ghETagCache {
+		if time.Since(cached.LastUsed) > 24*time.Hour {
+			delete(ghETagCache, k)
+		}
+	}
+	ghETagCache[url] = ghCachedResponse{ETag: etag, Body: body, Next: next, LastUsed: time.Now()}
+}
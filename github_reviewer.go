@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+var ghReviewer = "" // os.Getenv("GH_REVIEWER") // only PRs awaiting this user's review (or their approved, mergeable PRs) count
+
+// PullRequestDetail represents the fields of a single GitHub pull request that the list endpoints omit
+type PullRequestDetail struct {
+	RequestedReviewers []User `json:"requested_reviewers"`
+	Head               Ref    `json:"head"`
+	Mergeable          *bool  `json:"mergeable"`
+	MergeableState     string `json:"mergeable_state"` // clean, dirty, blocked, behind, unstable, draft, unknown
+}
+
+// Review represents a GitHub pull request review
+type Review struct {
+	User  User   `json:"user"`
+	State string `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED, PENDING
+}
+
+// repoAPIURL returns the GitHub API URL of the repository the pull request belongs to
+func (pr PullRequest) repoAPIURL() string {
+	if pr.RepositoryURL != "" {
+		return pr.RepositoryURL
+	}
+	return fmt.Sprintf("%s/repos/%s/%s", ghApiUrl, ghOwner, ghRepo)
+}
+
+// apiURL returns the GitHub API URL of the pull request
+func (pr PullRequest) apiURL() string {
+	return fmt.Sprintf("%s/pulls/%d", pr.repoAPIURL(), pr.Number)
+}
+
+// ghReviewerPullRequests keeps only the PRs where GH_REVIEWER's review is requested,
+// plus GH_REVIEWER's own PRs that are approved and ready to merge
+func ghReviewerPullRequests(ctx context.Context, prs []PullRequest) ([]PullRequest, error) {
+	var kept []PullRequest
+	for _, pr := range prs {
+		if strings.EqualFold(pr.User.Login, ghReviewer) {
+			ready, err := ghIsApprovedAndMergeable(ctx, pr)
+			if err != nil {
+				return nil, err
+			}
+			if ready {
+				kept = append(kept, pr)
+			}
+			continue
+		}
+
+		// Search results don't include requested reviewers, so fetch the full PR
+		if pr.RepositoryURL != "" && !pr.fromGraphQL {
+			var detail PullRequestDetail
+			if err := ghGet(ctx, pr.apiURL(), &detail); err != nil {
+				return nil, err
+			}
+			pr.RequestedReviewers = detail.RequestedReviewers
+		}
+		if slices.ContainsFunc(pr.RequestedReviewers, func(u User) bool { return strings.EqualFold(u.Login, ghReviewer) }) {
+			kept = append(kept, pr)
+		}
+	}
+	return kept, nil
+}
+
+// ghIsApprovedAndMergeable reports whether the PR has an approval, no outstanding
+// change requests, and GitHub considers it cleanly mergeable
+func ghIsApprovedAndMergeable(ctx context.Context, pr PullRequest) (bool, error) {
+	if pr.fromGraphQL {
+		checksOK := pr.ChecksState == "" || pr.ChecksState == "SUCCESS"
+		return pr.ReviewDecision == "APPROVED" && pr.Mergeable != nil && *pr.Mergeable && checksOK, nil
+	}
+
+	var detail PullRequestDetail
+	if err := ghGet(ctx, pr.apiURL(), &detail); err != nil {
+		return false, err
+	}
+	if detail.MergeableState != "clean" {
+		return false, nil
+	}
+
+	var reviews []Review
+	if err := ghGet(ctx, pr.apiURL()+"/reviews", &reviews); err != nil {
+		return false, err
+	}
+
+	// Only the latest review of each reviewer counts
+	latest := make(map[string]string)
+	for _, r := range reviews {
+		if r.State == "COMMENTED" || r.State == "PENDING" {
+			continue
+		}
+		latest[r.User.Login] = r.State
+	}
+	approved := false
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return false, nil
+		case "APPROVED":
+			approved = true
+		}
+	}
+	return approved, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ghSecurityAlerts = []string{}  // os.Getenv("GH_SECURITY_ALERTS") // comma-separated: dependabot, code-scanning
+var ghSecurityMinSeverity = "high" // os.Getenv("GH_SECURITY_MIN_SEVERITY") // critical, high, medium, low
+var ghSecurityState = "none"       // "open" when alerts at or above the minimum severity exist
+var securityAlerts = []Issue{}     // latest security alerts for the health report
+
+// ghSeverityRank orders Dependabot and code scanning severities, higher is more severe
+var ghSeverityRank = map[string]int{
+	"low": 1, "note": 1, "warning": 2, "medium": 2, "moderate": 2, "error": 3, "high": 3, "critical": 4,
+}
+
+// ghSeverityNames lists the severities of ghSeverityRank from the most severe, e.g. for error messages
+func ghSeverityNames() string {
+	names := slices.SortedFunc(maps.Keys(ghSeverityRank), func(a, b string) int {
+		return cmp.Or(ghSeverityRank[b]-ghSeverityRank[a], strings.Compare(a, b))
+	})
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
+
+// DependabotAlert represents a GitHub Dependabot alert
+type DependabotAlert struct {
+	Number           int    `json:"number"`
+	HTMLURL          string `json:"html_url"`
+	SecurityAdvisory struct {
+		Severity string `json:"severity"`
+		Summary  string `json:"summary"`
+	} `json:"security_advisory"`
+	Dependency struct {
+		Package struct {
+			Name string `json:"name"`
+		} `json:"package"`
+	} `json:"dependency"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"` // only set by the organization endpoint
+}
+
+// CodeScanningAlert represents a GitHub code scanning alert
+type CodeScanningAlert struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Rule    struct {
+		ID                    string `json:"id"`
+		Severity              string `json:"severity"`                // note, warning, error
+		SecuritySeverityLevel string `json:"security_severity_level"` // low, medium, high, critical
+		Description           string `json:"description"`
+	} `json:"rule"`
+	Repository struct {
+		Name string `json:"name"`
+	} `json:"repository"` // only set by the organization endpoint
+}
+
+// ghSecurityAlertsCheck polls Dependabot and/or code scanning alerts and raises
+// a warning issue for each open alert at or above GH_SECURITY_MIN_SEVERITY
+func ghSecurityAlertsCheck(ctx context.Context) {
+
+	// Ensure security alerts are enabled and a repo or org is configured otherwise skip
+	if len(ghSecurityAlerts) == 0 || (ghOrg == "" && (ghOwner == "" || ghRepo == "")) || !integrationReady("github-security") {
+		return
+	}
+
+	// Alerts are listed for the whole org when scanning an org, otherwise for the repo
+	base := fmt.Sprintf("%s/repos/%s/%s", ghApiUrl, ghOwner, ghRepo)
+	if ghOrg != "" {
+		base = fmt.Sprintf("%s/orgs/%s", ghApiUrl, ghOrg)
+	}
+
+	var issues []Issue
+	minRank := ghSeverityRank[ghSecurityMinSeverity]
+
+	if slices.Contains(ghSecurityAlerts, "dependabot") {
+		alerts, err := ghGetAll[DependabotAlert](ctx, base+"/dependabot/alerts?state=open&per_page=100")
+		if err != nil {
+			HandleError("github-security", "Error checking Dependabot alerts:", err)
+			return
+		}
+		for _, a := range alerts {
+			if ghSeverityRank[a.SecurityAdvisory.Severity] < minRank {
+				continue
+			}
+			issues = append(issues, Issue{
+				Key:       fmt.Sprintf("security/dependabot/%s%d", repoPrefix(a.Repository.Name), a.Number),
+				Type:      "SecurityAlert",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("[%s] %s: %s", a.SecurityAdvisory.Severity, a.Dependency.Package.Name, a.SecurityAdvisory.Summary),
+				Timestamp: time.Now(),
+			})
+		}
+	}
+
+	if slices.Contains(ghSecurityAlerts, "code-scanning") {
+		alerts, err := ghGetAll[CodeScanningAlert](ctx, base+"/code-scanning/alerts?state=open&per_page=100")
+		if err != nil {
+			HandleError("github-security", "Error checking code scanning alerts:", err)
+			return
+		}
+		for _, a := range alerts {
+			// Security rules carry a CVSS-style level, other rules only note/warning/error
+			severity := a.Rule.SecuritySeverityLevel
+			if severity == "" {
+				severity = a.Rule.Severity
+			}
+			if ghSeverityRank[severity] < minRank {
+				continue
+			}
+			issues = append(issues, Issue{
+				Key:       fmt.Sprintf("security/code-scanning/%s%d", repoPrefix(a.Repository.Name), a.Number),
+				Type:      "SecurityAlert",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("[%s] %s: %s", severity, a.Rule.ID, a.Rule.Description),
+				Timestamp: time.Now(),
+			})
+		}
+	}
+
+	integrationOK("github-security")
+
+	// Notify when security alerts first appear
+	if len(issues) > 0 && ghSecurityState == "none" {
+		ntfyOpts := NtfyOptions{
+			Title:    fmt.Sprintf("Security alerts: %d", len(issues)),
+			Priority: 4, // (required)
+			Tags:     "warning",
+			Key:      "security-alerts",
+			Active:   func() bool { return ghSecurityState != "none" },
+		}
+		if err := SendNtfyAlert(issues[0].Message, ntfyOpts); err != nil {
+			log.Printf("Error sending ntfy alert: %v", err)
+		}
+	}
+
+	ghSecurityState = "none"
+	if len(issues) > 0 {
+		ghSecurityState = "open"
+	}
+	securityAlerts = issues
+}
+
+// repoPrefix returns "name/" for alerts from organization endpoints so keys stay unique across repos
+func repoPrefix(name string) string {
+	if name == "" {
+		return ""
+	}
+	return name + "/"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityAlertsFollowPages(t *testing.T) {
+	defer func(u, owner, repo string, sources []string) {
+		ghApiUrl, ghOwner, ghRepo, ghSecurityAlerts = u, owner, repo, sources
+		ghSecurityState, securityAlerts = "none", []Issue{}
+		delete(integrations, "github-security")
+	}(ghApiUrl, ghOwner, ghRepo, ghSecurityAlerts)
+	defer func(d string) { notifyDriver, recording = d, nil }(notifyDriver)
+	notifyDriver = "recording"
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path + "?" + r.URL.Query().Get("page") {
+		case "/repos/homelab/cluster/dependabot/alerts?":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/homelab/cluster/dependabot/alerts?page=2>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[{"number":1,"security_advisory":{"severity":"low"}}]`)
+		case "/repos/homelab/cluster/dependabot/alerts?2":
+			fmt.Fprint(w, `[{"number":2,"security_advisory":{"severity":"critical","summary":"RCE"}}]`)
+		case "/repos/homelab/cluster/code-scanning/alerts?":
+			fmt.Fprint(w, `[{"number":3,"rule":{"id":"sql-injection","security_severity_level":"high"}}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ghApiUrl, ghOwner, ghRepo = srv.URL, "homelab", "cluster"
+	ghSecurityAlerts = []string{"dependabot", "code-scanning"}
+
+	ghSecurityAlertsCheck(context.Background())
+	if len(securityAlerts) != 2 || securityAlerts[0].Key != "security/dependabot/2" || securityAlerts[1].Key != "security/code-scanning/3" {
+		t.Errorf("alerts = %+v, want the critical alert of the second page and the code scanning alert", securityAlerts)
+	}
+	if ghSecurityState != "open" {
+		t.Errorf("state = %q, want open", ghSecurityState)
+	}
+}
+
+func TestSeverityNames(t *testing.T) {
+	if got, want := ghSeverityNames(), "critical, error, high, medium, moderate, warning, low or note"; got != want {
+		t.Errorf("names = %q, want %q", got, want)
+	}
+}
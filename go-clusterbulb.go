@@ -11,7 +11,8 @@
 //	Green: Healthy cluster
 //	Blue: Open GitHub pull requests
 //	Red: Detected issues in the cluster
-//	Blinking Red-Blue: Both open pull requests and detected issues
+//	Magenta: CI failing on the watched branch
+//	Blinking: Several of the above at once, cycling through each color
 //
 // Usage:
 // Deploy go-clusterbulb as a pod within your Kubernetes cluster with the
@@ -31,118 +32,1470 @@
 // - GH_REPO: GitHub repository name
 // - GH_TOKEN: (Optional/Recommended) GitHub Personal Access Token for authenticated API requests
 // - GH_PR_CHECK_INTERVAL: Interval in seconds to check for open pull requests (default 300 seconds)
+// - GH_API_URL: (Optional) GitHub API base URL (default https://api.github.com), set to https://<host>/api/v3 for GitHub Enterprise Server
+// - GH_USE_GRAPHQL: (Optional) Set to true to fetch PRs with their review and check state via the GraphQL API (requires GH_TOKEN)
+// - GH_REVIEWER: (Optional) Only PRs requesting this user's review, or their own approved and mergeable PRs, affect the bulb
+// - GH_PR_CHECK_MERGEABLE: (Optional) Set to true to flag PRs with merge conflicts or failing required checks
+// - HA_COLOR_PRS_BLOCKED: (Optional) r,g,b color for conflicting/blocked PRs (default 255,200,0)
+// - GH_SECURITY_ALERTS: (Optional) Comma-separated alert sources to monitor: dependabot, code-scanning
+// - GH_SECURITY_MIN_SEVERITY: (Optional) Minimum alert severity that raises an issue (default high)
+// - HA_COLOR_SECURITY_ALERTS: (Optional) r,g,b color for open security alerts (default 255,80,0)
+// - GH_ISSUE_LABEL: (Optional) Open GitHub issues with this label (e.g. incident) drive the bulb
+// - GH_ISSUE_LABEL_STATE: (Optional) Bulb state shown for labeled issues (default issues_detected, or incidents_open)
+// - HA_COLOR_INCIDENTS: (Optional) r,g,b color for the incidents_open state (default 255,0,80)
+// - GH_WEBHOOK_SECRET: (Optional) Enables the /webhooks/github endpoint, validating deliveries with this HMAC secret
+// - SLACK_SIGNING_SECRET: (Optional) Enables the /webhooks/slack endpoint for the /clusterbulb slash command
+// - TELEGRAM_WEBHOOK_SECRET: (Optional) Enables the /webhooks/telegram endpoint for bot commands, checked against the secret token header
+// - TELEGRAM_CHAT_IDS: (Optional) Comma-separated Telegram chat IDs allowed to send commands (default all)
+// - GH_PR_IGNORE_DRAFTS: (Optional) Set to true to ignore draft pull requests
+// - GH_PR_IGNORE_LABELS: (Optional) Comma-separated labels whose pull requests are ignored (e.g. wip,on-hold)
+// - GH_PR_IGNORE_AUTHORS: (Optional) Comma-separated authors whose pull requests are ignored (e.g. dependabot[bot])
+// - GH_PR_IGNORED_MODE: (Optional) exclude (default) drops ignored PRs, color shows them as HA_COLOR_PRS_IGNORED
+// - HA_COLOR_PRS_IGNORED: (Optional) r,g,b color for ignored PRs (default 100,0,255)
+// - GH_PR_BOTS_SEPARATE: (Optional) Set to true to show dependency update PRs as their own state
+// - GH_PR_BOT_AUTHORS: (Optional) Comma-separated bot authors (default dependabot[bot],renovate[bot],dependabot,renovate)
+// - HA_COLOR_PRS_BOTS: (Optional) r,g,b color for dependency update PRs (default 0,128,128)
+// - GH_PR_ESCALATE_COUNT: (Optional) Escalate the PR state when more than this many PRs are open
+// - GH_PR_ESCALATE_AGE_DAYS: (Optional) Escalate the PR state when any PR is older than this many days
+// - HA_COLOR_PRS_ESCALATED: (Optional) r,g,b color for the escalated PR state (default 0,255,255)
+// - CONFIG_FILE: (Optional) Path to the YAML config file for structured settings such as PromQL checks
+// - DNS_CHECK_NAME: (Optional) In-cluster name resolved through the cluster DNS (e.g. kubernetes.default.svc.cluster.local)
+// - DNS_CHECK_EXTERNAL_NAME: (Optional) External name resolved through the cluster DNS (e.g. github.com)
+// - DNS_CHECK_LATENCY_MS: (Optional) Warn when DNS resolution takes longer than this (default 500)
+// - EGRESS_CHECK_URL: (Optional) URL sent a HEAD request to verify outbound internet connectivity
+// - REGISTRY_CHECK_HOSTS: (Optional) Comma-separated container registries pinged on /v2/ (e.g. harbor.example.com)
+// - TRIVY_CRITICAL_THRESHOLD: (Optional) Warn when Trivy Operator reports more critical CVEs than this (default 0)
+// - TRIVY_STATE: (Optional) Bulb state shown for critical CVEs (default issues_detected, or vulnerabilities_found)
+// - HA_COLOR_VULNERABILITIES: (Optional) r,g,b color for the vulnerabilities_found state (default 200,0,120)
+// - CRITICAL_WORKLOADS: (Optional) Comma-separated namespace/name workloads that must always be healthy (e.g. kube-system/coredns)
+// - LB_PENDING_GRACE_MINUTES: (Optional) Minutes a LoadBalancer service may wait for an external address (default 5)
+// - CAPACITY_SEVERITY: (Optional) Severity of pods the autoscaler/Karpenter can't make room for: critical, warning (default) or info
+// - REBOOT_REQUIRED_ANNOTATION: (Optional) Node annotation marking a pending reboot (default weave.works/kured-most-recent-reboot-needed)
+// - REBOOT_REQUIRED_STATE: (Optional) Bulb state for nodes pending a reboot (e.g. reboot_required), by default they are only reported
+// - HA_COLOR_REBOOT_REQUIRED: (Optional) r,g,b color for the reboot_required state (default 255,255,255)
+// - K8S_EOL_URL: (Optional) endoflife.date compatible URL refreshing the built-in Kubernetes EOL table
+// - K8S_MAX_SKEW: (Optional) Minor versions a kubelet may lag behind the control plane (default 2)
+// - DEPRECATED_API_SCAN: (Optional) Set to true to report deprecated APIs still requested in the cluster (reads apiserver /metrics)
+// - NODE_CLOCK_SKEW_SECONDS: (Optional) Flag nodes whose clock is off by more than this many seconds (default 5)
+// - GPU_NODE_SELECTOR: (Optional) Label selector of nodes expected to have GPUs (default nvidia.com/gpu.present=true)
+// - GPU_EXPECTED_COUNT: (Optional) nvidia.com/gpu capacity each GPU node should advertise (default at least 1)
+// - ETCD_BACKUP_PATH: (Optional) Directory of etcd snapshot files (e.g. a hostPath mount) checked for the newest snapshot
+// - ETCD_BACKUP_MAX_AGE_HOURS: (Optional) Warn when the newest etcd snapshot is older than this (default 24)
+// - ARGO_ROLLOUTS_DASHBOARD_URL: (Optional) Argo Rollouts dashboard URL linked from rollout notifications
+// - SPOT_NODE_LABELS: (Optional) Comma-separated key=value labels identifying spot/preemptible nodes (AWS, Karpenter and GKE defaults)
+// - SPOT_TERMINATION_SIGNALS: (Optional) Comma-separated taint keys or condition types announcing an interruption
+// - HA_COLOR_SPOT_INTERRUPTION: (Optional) r,g,b color for the spot_interruption state (default 255,140,0)
+// - PREEMPTION_THRESHOLD: (Optional) Warn when more pods than this are preempted within the window (default 5)
+// - PREEMPTION_WINDOW_MINUTES: (Optional) Window for PREEMPTION_THRESHOLD in minutes (default 10)
+// - CSR_PENDING_MINUTES: (Optional) Flag CSRs unapproved or unissued for longer than this many minutes (default 15)
+// - POLICY_MIN_SEVERITY: (Optional) Minimum Kyverno/Gatekeeper violation severity that raises an issue (default medium)
+// - ALERTMANAGER_URL: (Optional) Alertmanager base URL; firing alerts become issues with the alert's severity
+// - ALERTMANAGER_MATCHERS: (Optional) Comma-separated label matchers to filter alerts (e.g. severity=~"critical|warning")
+// - HTTP_LISTEN_ADDR: (Optional) Listen address for the HTTP server (default :8080), serving /metrics
+// - GRPC_LISTEN_ADDR: (Optional) Listen address for the gRPC status and control API, e.g. :9090 (default disabled)
+// - API_TOKEN: (Optional) Bearer token required by the gRPC API and the /api/v1 endpoints
+// - NTFY_STATE_CHANGES: (Optional) Set to true to notify on every cluster state transition
+// - HISTORY_FILE: (Optional) JSON file on a persistent volume storing issue history for MTTR reporting
+// - HISTORY_RETENTION_DAYS: (Optional) Days of resolved issue history to keep (default 30)
+// - SLO_TARGET: (Optional) Availability target in percent (e.g. 99.5), shows slo_budget_low when the 30d error budget is nearly spent
+// - SLO_BUDGET_LOW_PERCENT: (Optional) Remaining error budget below which slo_budget_low is shown (default 10)
+// - HA_COLOR_SLO_BUDGET_LOW: (Optional) r,g,b color for the slo_budget_low state (default 75,0,130)
+// - HTTPS_PROXY / HTTP_PROXY / NO_PROXY: (Optional) Proxy settings honored by all outbound HTTP calls
+// - TLS_CA_FILE: (Optional) PEM bundle of additional CAs trusted for outbound HTTPS (e.g. a private CA for HA/ntfy/Gitea)
+// - TLS_INSECURE_SKIP_VERIFY: (Optional) Set to true to skip outbound TLS certificate verification (not recommended)
+// - HA_LIGHT_TRANSITION: (Optional) Seconds to fade between colors (default 0, no fade)
+// - HA_BREATHE_STATES: (Optional) Comma-separated states that pulse their brightness, e.g. warning_events
+// - HA_STATE_RGBW: (Optional) Semicolon-separated state=r,g,b,w colors for RGBW bulbs, e.g. reboot_required=0,0,0,255
+// - LAMETRIC_URL: (Optional) LaMetric Time local API URL (e.g. http://192.168.1.50:8080) to show the issue count and state
+// - LAMETRIC_API_KEY: (Optional) LaMetric device API key, required with LAMETRIC_URL
+// - LAMETRIC_ICON: (Optional) LaMetric icon id shown next to the count (e.g. i120)
+// - PIXOO_URL: (Optional) Divoom Pixoo 64 local API URL (e.g. http://192.168.1.60) to show the state color, count and state
+// - KUBECONFIG: (Optional) Kubeconfig path to run out of cluster, e.g. on a desk machine with a busylight
+// - BUSYLIGHT_DEVICE: (Optional) USB busylight (Luxafor, Kuando Busylight or blink(1)): auto, or a hidraw path such as /dev/hidraw3
+// - BUSYLIGHT_BLINK_STATES: (Optional) Comma-separated states the busylight blinks for (default issues_detected)
+// - STREAMDECK_DEVICE: (Optional) Elgato Stream Deck (Original V2, MK.2, XL): auto, or a hidraw path such as /dev/hidraw4
+// - STREAMDECK_KEYS: (Optional) Comma-separated key=role pairs, roles: state, ack, snooze, recheck (default 0=state,12=ack,13=snooze,14=recheck)
+// - SNOOZE_MINUTES: (Optional) Minutes the snooze action silences the bulb and ntfy (default 60)
+// - HA_MUTE_ENTITY_ID: (Optional) Home Assistant input_boolean or input_select that mutes the bulb and ntfy (e.g. input_boolean.clusterbulb_mute)
+// - HA_MUTE_STATE: (Optional) State of HA_MUTE_ENTITY_ID that means muted (default on, e.g. muted for an input_select)
+// - NAMESPACE_LIGHTS: (Optional) Comma-separated namespace=light pairs giving namespaces their own bulb, e.g. media=light.shelf_left
+// - INTEGRATION_FAILURE_LIMIT: (Optional) Consecutive failures before an integration is marked degraded and retried with backoff (default 5)
+// - CHECK_TIMEOUT_SECONDS: (Optional) Maximum duration of one cluster or SCM check cycle before it is cancelled (default 30)
+// - NTFY_ATTACH_REPORT: (Optional) Add the health report to critical notifications: inline, attach or auto (inline when small enough)
+// - NTFY_ATTACH_PRIORITY: (Optional) Minimum ntfy priority of notifications carrying the report (default 4)
+// - EVENT_STORM_THRESHOLD: (Optional) Warning events per cycle above which they are collapsed into one event storm issue (default 50, 0 disables)
+// - WARMUP_MINUTES: (Optional) Grace period after startup during which notifications are held back and the bulb shows the starting color (default 0, disabled)
+// - WARMUP_ON_CLUSTER_BOOT: (Optional) Restart the grace period when every node just became ready, e.g. after a power outage (true/false)
+// - HA_COLOR_STARTING: (Optional) r,g,b color for the starting state during the grace period (default 255,220,150)
+// - CLUSTER_BOOT_MINUTES: (Optional) Show the booting state instead of issues while most nodes and kube-system pods restarted within this time (default 0, disabled)
+// - HA_COLOR_BOOTING: (Optional) r,g,b color for the booting state (default 255,105,180)
+// - HA_OFF_WHEN_HEALTHY: (Optional) Keep the bulb off while the cluster is healthy, it only lights up for PRs, warnings and issues (true/false)
+// - HA_MEDIA_PLAYER_ENTITY_ID: (Optional) media_player entity that plays an alert sound when a critical state is raised
+// - HA_SOUND_URL: (Optional) Media content ID of the alert sound, e.g. media-source://media_source/local/alert.mp3
+// - HA_SOUND_VOLUME: (Optional) Volume between 0-1 set before playing, the player's volume is kept when unset
+// - SOUND_STATES: (Optional) Comma-separated states that play the sound when raised (default issues_detected)
+// - SOUND_INTERVAL_MINUTES: (Optional) Minimum time between two alert sounds (default 15)
+// - NTFY_INTERVAL_MINUTES: (Optional) Minimum time between two notifications about the same thing below urgent priority (default 0, disabled)
+// - QUIET_HOURS: (Optional) HH:MM-HH:MM window without alert sounds and ntfy notifications below urgent priority, e.g. 22:00-07:00
+// - NTFY_RECOVERIES: (Optional) Comma-separated recoveries to notify: prs_closed, prs_decreased, ci_recovered, or all
+// - PROVISIONING_CHECK: (Optional) Report an info issue when the cluster is significantly over- or under-provisioned (true/false)
+// - OPENCOST_URL: (Optional) OpenCost API used by the provisioning check, e.g. http://opencost.opencost:9003; metrics-server is used without it
+// - PROVISIONING_MIN_EFFICIENCY: (Optional) Percent of requested CPU and memory used below which the cluster is over-provisioned (default 30)
+// - PROVISIONING_MAX_EFFICIENCY: (Optional) Percent of requested CPU or memory used above which the cluster is under-provisioned (default 120)
+// - KUBE_BENCH_CONFIGMAP: (Optional) namespace/name of a ConfigMap with kube-bench JSON results; failed scored CIS controls become warnings
+// - KUBE_BENCH_CONFIGMAP_KEY: (Optional) Key of the results in the ConfigMap (default results.json)
+// - KUBE_BENCH_JOB: (Optional) namespace/label selector of kube-bench Job pods whose logs hold the JSON results, e.g. kube-bench/app=kube-bench
+// - FALCO_WEBHOOK_TOKEN: (Optional) Enables the /webhooks/falco endpoint for Falco http_output alerts, authenticated with this token
+// - FALCO_ALERT_TTL_MINUTES: (Optional) Falco alerts clear when their rule doesn't fire again within this time (default 15)
+// - NODE_TEMP_THRESHOLD: (Optional) Warn about nodes hotter than this many °C, from node-exporter metrics in the configured Prometheus
+// - SMART_CHECK: (Optional) Warn about disks failing their SMART self-assessment, from smartctl_exporter or smartmon metrics (true/false)
+// - GH_TICKET_REPO: (Optional) owner/repo in which GitHub issues are opened for long-lived critical issues and closed once they clear
+// - GH_TICKET_LABEL: (Optional) Label of those GitHub issues (default clusterbulb)
+// - GH_TICKET_AFTER_MINUTES: (Optional) How long a critical issue is active before a GitHub issue is opened (default 30)
+// - JIRA_URL: (Optional) Jira base URL; tickets are created for long-lived critical issues and transitioned once they clear
+// - JIRA_USER: (Optional) Jira account email
+// - JIRA_API_TOKEN: (Optional) Jira API token
+// - JIRA_PROJECT: (Optional) Jira project key the tickets are created in
+// - JIRA_ISSUE_TYPE: (Optional) Issue type of the tickets (default Task)
+// - JIRA_DONE_TRANSITION: (Optional) Name of the transition applied once the issue clears (default Done)
+// - JIRA_AFTER_MINUTES: (Optional) How long a critical issue is active before a Jira ticket is created (default 30)
+// - HA_LIGHT_AREA: (Optional) Home Assistant area whose lights all show the state, instead of HA_LIGHT_ENTITY_ID
+// - NANOLEAF_TOKEN: (Optional) Auth token of the Nanoleaf Open API, for the nanoleaf zones of the config file
+// - HA_TOKEN_FILE: (Optional) File holding HA_TOKEN, e.g. a mounted secret; re-read every TOKEN_RELOAD_MINUTES and on 401 responses
+// - GH_TOKEN_FILE: (Optional) File holding GH_TOKEN, re-read the same way
+// - NTFY_TOKEN: (Optional) Access token of a protected ntfy server
+// - NTFY_TOKEN_FILE: (Optional) File holding NTFY_TOKEN, re-read the same way
+// - TOKEN_RELOAD_MINUTES: (Optional) How often the token files are re-read (default 5)
+// - ZONE_OUTAGE_MINUTES: (Optional) How long every node of a zone, or every replica of a workload in a zone, must be down before it is a zone outage (default 5)
+// - LIGHT_DRIVER: (Optional) homeassistant (default), or noop/recording to run without a Home Assistant instance
+// - NOTIFY_DRIVER: (Optional) ntfy (default), or noop/recording to run without a ntfy server
+// - RECORDING_FILE: (Optional) JSON lines file the recording drivers append each command to
+// - INTEGRATION_DEGRADED_STATE: (Optional) Bulb state for degraded integrations (e.g. issues_detected), by default they are only reported
+// - HA_STATE_TARGETS: (Optional) Comma-separated state=entity pairs activating a scene or script instead of a color, e.g. issues_detected=scene.cluster_red
+// - WARNING_EVENTS_STATE: (Optional) Bulb state when only warning events are present (e.g. warning_events), by default issues_detected
+// - HA_COLOR_WARNING_EVENTS: (Optional) r,g,b color for the warning_events state (default 255,165,0)
+// - BULB_COUNT_MODE: (Optional) Blink the number of active issues or open PRs once a minute: issues or prs
+// - BULB_COUNT_MAX: (Optional) Maximum number of blinks per burst (default 10)
+// - HTTP_TIMEOUTS: (Optional) Comma-separated per-integration timeouts, e.g. github=20s,ntfy=5s,default=10s
+// - ISSUE_TTL_MINUTES: (Optional) Forget tracked issues that are not reported again within this time (default 60)
+// - ISSUE_MAX_ENTRIES: (Optional) Maximum number of tracked issues, least recently reported are evicted (default 5000)
+// - SCM_PROVIDER: (Optional) Source code host to poll: github (default), gitea/forgejo, or bitbucket
+// - CI_BRANCH: (Optional) Branch whose CI status is watched; CI checks are disabled when unset
+// - GITEA_URL, GITEA_OWNER, GITEA_REPO, GITEA_TOKEN: Gitea/Forgejo instance and repository
+// - BITBUCKET_WORKSPACE, BITBUCKET_REPO: Bitbucket Cloud repository
+// - BITBUCKET_USERNAME, BITBUCKET_APP_PASSWORD or BITBUCKET_TOKEN: Bitbucket Cloud credentials
+// - GH_ORG: (Optional) GitHub organization to scan for open pull requests across all of its repositories
+// - GH_REPO_TOPIC: (Optional) Only scan org repositories tagged with this topic
+// - GH_REPO_GLOB: (Optional) Only scan org repositories whose name matches this glob
 package main
 
 import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
+	"os/signal"
 	"os/user"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // Environment variables
-var haToken = ""               // os.Getenv("HA_TOKEN")
+var haToken secretToken        // os.Getenv("HA_TOKEN")
 var haUrl = ""                 // os.Getenv("HA_URL")
 var haLightEntityId = ""       // os.Getenv("HA_LIGHT_ENTITY_ID")
-var haLightBrightness = 255    // os.Getenv("HA_LIGHT_BRIGHTNESS") // 0-255
+var haLightBrightness = 255    // os.Getenv("HA_LIGHT_BRIGHTNESS") // 0-255, 0 keeps the bulb off
+var haOffWhenHealthy = false   // os.Getenv("HA_OFF_WHEN_HEALTHY") == "true" // switch the bulb off instead of showing green
 var ghOwner = ""               // os.Getenv("GH_OWNER")
 var ghRepo = ""                // os.Getenv("GH_REPO")
-var ghToken = ""               // os.Getenv("GH_TOKEN")
+var ghToken secretToken        // os.Getenv("GH_TOKEN")
 var ghPRCheckInterval = 5 * 60 // os.Getenv("GH_PR_CHECK_INTERVAL") // Seconds default:300
+var ghOrg = ""                 // os.Getenv("GH_ORG") // scan every repo in the org instead of GH_OWNER/GH_REPO
+var ghRepoTopic = ""           // os.Getenv("GH_REPO_TOPIC") // optional, only repos tagged with this topic
+var ghRepoGlob = ""            // os.Getenv("GH_REPO_GLOB") // optional, only repos matching this glob (e.g. "k8s-*")
+var kubeconfig = ""            // os.Getenv("KUBECONFIG") // out-of-cluster mode, e.g. on a desk machine
+
+var checkTimeout = 30 * time.Second // os.Getenv("CHECK_TIMEOUT_SECONDS") // upper bound for one cluster or SCM check cycle
+
+// appCtx is cancelled on shutdown; used by calls that are not tied to a check cycle such as ntfy alerts
+var appCtx = context.Background()
 
 // Variables to track known issues, cluster state, and HA bulb color state
 var knownIssues = make(map[string]time.Time)
-var clusterState = "healthy"
+var clusterState ClusterState
 var ghPRState = "none"
-var haLastColorState = "healthy"
+var haLastColorState = SignalHealthy
+var haNextColorState Signal // shown next instead of continuing the blink cycle
 var pullRequests = []Issue{}
 
+var warningEventsState = SignalIssuesDetected // os.Getenv("WARNING_EVENTS_STATE") // e.g. warning_events to tell event-only hiccups apart
+
 // Issue represents a detected cluster issue
 type Issue struct {
 	Key       string    `json:"key"`
 	Type      string    `json:"type"`
+	Severity  string    `json:"severity,omitempty"` // critical, warning, info
 	Message   string    `json:"message"`
 	Timestamp time.Time `json:"timestamp"`
+	Namespace string    `json:"namespace,omitempty"` // set for namespaced resources
+	Kind      string    `json:"kind,omitempty"`      // kind of the affected resource, e.g. Pod or Node
+	Name      string    `json:"name,omitempty"`      // name of the affected resource
+	Owner     string    `json:"owner,omitempty"`     // workload owning the resource, e.g. deployment/web
+	Node      string    `json:"node,omitempty"`      // node the pod runs on or the event was reported by
+	Reason    string    `json:"reason,omitempty"`    // machine readable cause, e.g. BackOff or NotReady
+	FirstSeen time.Time `json:"first_seen,omitzero"` // first check the issue was seen in, since it last cleared
+	LastSeen  time.Time `json:"last_seen,omitzero"`
+	Count     int       `json:"count,omitempty"`   // checks the issue was seen in, or the event count
+	PR        *PRDetail `json:"pr,omitempty"`      // only set for pull request issues
+	Runbook   string    `json:"runbook,omitempty"` // URL of the matching runbook from the config
+
+	labels map[string]string // labels of the affected pod, matched by component selectors
+}
+
+// PRDetail carries the pull request fields shown in the report and notifications
+type PRDetail struct {
+	Number    int       `json:"number"`
+	Author    string    `json:"author"`
+	URL       string    `json:"url,omitempty"`
+	Draft     bool      `json:"draft"`
+	CreatedAt time.Time `json:"created_at"`
+	Age       string    `json:"age"`
 }
 
 // HealthReport represents the overall cluster health summary
 type HealthReport struct {
-	Timestamp    time.Time `json:"timestamp"`
-	NodeIssues   []Issue   `json:"node_issues"`
-	PodIssues    []Issue   `json:"pod_issues"`
-	EventIssues  []Issue   `json:"event_issues"`
-	PullRequests []Issue   `json:"pull_requests"`
-	TotalIssues  int       `json:"total_issues"`
-	ClusterState string    `json:"cluster_state"`
+	Timestamp      time.Time  `json:"timestamp"`
+	NodeIssues     []Issue    `json:"node_issues"`
+	PodIssues      []Issue    `json:"pod_issues"`
+	EventIssues    []Issue    `json:"event_issues"`
+	CheckIssues    []Issue    `json:"check_issues,omitempty"`
+	PullRequests   []Issue    `json:"pull_requests"`
+	OpenPRCount    int        `json:"open_pr_count"`
+	SecurityAlerts []Issue    `json:"security_alerts,omitempty"`
+	Incidents      []Issue    `json:"incidents,omitempty"`
+	CVESummary     string     `json:"cve_summary,omitempty"`
+	TopOffenders   []Offender `json:"top_offenders,omitempty"` // issues grouped by owner workload and reason
+	Score          int        `json:"score,omitempty"`         // weighted issue score, with scoring configured
+	TotalIssues    int        `json:"total_issues"`
+	CIState        string     `json:"ci_state,omitempty"`
+	ClusterState   string     `json:"cluster_state"`
+
+	Integrations map[string]IntegrationHealth `json:"integrations,omitempty"` // last success and error of each integration
+	GitHubQuota  *GitHubQuota                 `json:"github_quota,omitempty"`
+	Profiles     map[string]string            `json:"profiles,omitempty"` // state of each profile
+	Components   []ComponentHealth            `json:"components,omitempty"`
 }
 
 // PullRequest represents a GitHub pull request
 type PullRequest struct {
-	Number    int       `json:"number"`
-	Title     string    `json:"title"`
-	User      User      `json:"user"`
-	State     string    `json:"state"`
-	HTMLURL   string    `json:"html_url"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Number             int       `json:"number"`
+	Title              string    `json:"title"`
+	User               User      `json:"user"`
+	State              string    `json:"state"`
+	Draft              bool      `json:"draft"`
+	Labels             []Label   `json:"labels"`
+	Head               Ref       `json:"head"`                // not set by the search API
+	Mergeable          *bool     `json:"mergeable"`           // only set by Gitea in list responses
+	RequestedReviewers []User    `json:"requested_reviewers"` // not set by the search API
+	HTMLURL            string    `json:"html_url"`
+	RepositoryURL      string    `json:"repository_url"` // only set by the search API
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+
+	// Only set by the GraphQL fetch path, which returns review and check state up front
+	ReviewDecision string `json:"-"` // APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED
+	ChecksState    string `json:"-"` // SUCCESS, FAILURE, ERROR, PENDING, EXPECTED
+	fromGraphQL    bool
+}
+
+// key returns the issue key for the pull request, qualified with the
+// repository name when the PR came from an organization-wide search
+func (pr PullRequest) key() string {
+	if pr.RepositoryURL != "" {
+		return fmt.Sprintf("pr/%s/%d", path.Base(pr.RepositoryURL), pr.Number)
+	}
+	return fmt.Sprintf("pr/%d", pr.Number)
 }
 
-// User represents a GitHub user
-type User struct {
-	Login string `json:"login"`
-}
+// detail returns the PR fields attached to its issue
+func (pr PullRequest) detail() *PRDetail {
+	return &PRDetail{
+		Number:    pr.Number,
+		Author:    pr.User.Login,
+		URL:       pr.HTMLURL,
+		Draft:     pr.Draft,
+		CreatedAt: pr.CreatedAt,
+		Age:       formatAge(time.Since(pr.CreatedAt)),
+	}
+}
+
+// link renders the PR as "#key title", as a Markdown link when its URL is known
+func (pr PullRequest) link() string {
+	if pr.HTMLURL == "" {
+		return fmt.Sprintf("#%s %s", pr.key(), pr.Title)
+	}
+	return fmt.Sprintf("[#%s %s](%s)", pr.key(), pr.Title, pr.HTMLURL)
+}
+
+// User represents a GitHub user
+type User struct {
+	Login string `json:"login"`
+}
+
+// Ref represents the head or base branch of a pull request
+type Ref struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// Label represents a GitHub issue/pull request label
+type Label struct {
+	Name string `json:"name"`
+}
+
+func main() {
+
+	// Client subcommands such as "status" query a running instance instead of monitoring
+	if len(os.Args) > 1 && cliCommands[os.Args[1]] != nil {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+	runMonitor()
+}
+
+// runMonitor reads the configuration from the environment and runs the checks until interrupted
+func runMonitor() {
+	// Prevent running as root/superuser
+	if isSuperUser() {
+		log.Fatalf("Running with superuser privileges is not permitted.")
+		os.Exit(1)
+	}
+
+	// Gather environment variables
+	ghOwner = os.Getenv("GH_OWNER")
+	ghRepo = os.Getenv("GH_REPO")
+	ghToken.Set(os.Getenv("GH_TOKEN")) // optional/recommended (GitHub API rate limits apply)
+	ghPRCheckIntervalStr := os.Getenv("GH_PR_CHECK_INTERVAL")
+	ghOrg = os.Getenv("GH_ORG")
+	if v := os.Getenv("GH_API_URL"); v != "" {
+		ghApiUrl = strings.TrimRight(v, "/")
+	}
+	ghRepoTopic = os.Getenv("GH_REPO_TOPIC")
+	ghRepoGlob = os.Getenv("GH_REPO_GLOB")
+	scmProviderName = os.Getenv("SCM_PROVIDER")
+	ciBranch = os.Getenv("CI_BRANCH")
+	giteaUrl = os.Getenv("GITEA_URL")
+	giteaOwner = os.Getenv("GITEA_OWNER")
+	giteaRepo = os.Getenv("GITEA_REPO")
+	giteaToken = os.Getenv("GITEA_TOKEN")
+	bitbucketWorkspace = os.Getenv("BITBUCKET_WORKSPACE")
+	bitbucketRepo = os.Getenv("BITBUCKET_REPO")
+	bitbucketUsername = os.Getenv("BITBUCKET_USERNAME")
+	bitbucketAppPassword = os.Getenv("BITBUCKET_APP_PASSWORD")
+	bitbucketToken = os.Getenv("BITBUCKET_TOKEN")
+	ghWebhookSecret = os.Getenv("GH_WEBHOOK_SECRET")
+	slackSigningSecret = os.Getenv("SLACK_SIGNING_SECRET")
+	telegramWebhookSecret = os.Getenv("TELEGRAM_WEBHOOK_SECRET")
+	telegramChatIdsStr := os.Getenv("TELEGRAM_CHAT_IDS")
+	ghReviewer = os.Getenv("GH_REVIEWER")
+	ghUseGraphQL = os.Getenv("GH_USE_GRAPHQL") == "true"
+	ghPRCheckMergeable = os.Getenv("GH_PR_CHECK_MERGEABLE") == "true"
+	haColorPRsBlockedStr := os.Getenv("HA_COLOR_PRS_BLOCKED")
+	ghSecurityAlerts = splitList(os.Getenv("GH_SECURITY_ALERTS"))
+	ghSecurityMinSeverityStr := os.Getenv("GH_SECURITY_MIN_SEVERITY")
+	haColorSecurityAlertsStr := os.Getenv("HA_COLOR_SECURITY_ALERTS")
+	ghIssueLabel = os.Getenv("GH_ISSUE_LABEL")
+	ghIssueLabelStateStr := os.Getenv("GH_ISSUE_LABEL_STATE")
+	haColorIncidentsStr := os.Getenv("HA_COLOR_INCIDENTS")
+	ghPRIgnoreDrafts = os.Getenv("GH_PR_IGNORE_DRAFTS") == "true"
+	ghPRIgnoreLabels = splitList(os.Getenv("GH_PR_IGNORE_LABELS"))
+	ghPRIgnoreAuthors = splitList(os.Getenv("GH_PR_IGNORE_AUTHORS"))
+	ghPRIgnoredModeStr := os.Getenv("GH_PR_IGNORED_MODE")
+	haColorPRsIgnoredStr := os.Getenv("HA_COLOR_PRS_IGNORED")
+	ghPRBotsSeparate = os.Getenv("GH_PR_BOTS_SEPARATE") == "true"
+	if v := os.Getenv("GH_PR_BOT_AUTHORS"); v != "" {
+		ghPRBotAuthors = splitList(v)
+	}
+	haColorPRsBotsStr := os.Getenv("HA_COLOR_PRS_BOTS")
+	ghPREscalateCountStr := os.Getenv("GH_PR_ESCALATE_COUNT")
+	ghPREscalateAgeDaysStr := os.Getenv("GH_PR_ESCALATE_AGE_DAYS")
+	haColorPRsEscalatedStr := os.Getenv("HA_COLOR_PRS_ESCALATED")
+	if v, ok := os.LookupEnv("HTTP_LISTEN_ADDR"); ok {
+		httpListenAddr = v
+	} else if tuiEngine != nil {
+		httpListenAddr = "" // the terminal UI serves the API to itself on a loopback port
+	}
+	grpcListenAddr = os.Getenv("GRPC_LISTEN_ADDR")
+	apiToken = os.Getenv("API_TOKEN")
+	haToken.Set(os.Getenv("HA_TOKEN"))
+	haTokenFile := os.Getenv("HA_TOKEN_FILE")
+	ghTokenFile := os.Getenv("GH_TOKEN_FILE")
+	ntfyUrl = os.Getenv("NTFY_URL")
+	ntfyTopic = os.Getenv("NTFY_TOPIC")
+	ntfyToken.Set(os.Getenv("NTFY_TOKEN"))
+	ntfyTokenFile := os.Getenv("NTFY_TOKEN_FILE")
+	tokenReloadStr := os.Getenv("TOKEN_RELOAD_MINUTES")
+	// Token files replace the env var tokens before anything checks for them
+	for _, t := range []struct {
+		name, path string
+		token      *secretToken
+	}{{"HA_TOKEN", haTokenFile, &haToken}, {"GH_TOKEN", ghTokenFile, &ghToken}, {"NTFY_TOKEN", ntfyTokenFile, &ntfyToken}} {
+		if err := addTokenFile(t.name, t.path, t.token); err != nil {
+			log.Printf("Invalid %s_FILE '%s': %v", t.name, t.path, err)
+			os.Exit(1)
+		}
+	}
+	haUrl = os.Getenv("HA_URL")
+	haLightEntityId = os.Getenv("HA_LIGHT_ENTITY_ID")
+	haLightArea = os.Getenv("HA_LIGHT_AREA")
+	haLightBrightnessStr := os.Getenv("HA_LIGHT_BRIGHTNESS")
+	configFile = os.Getenv("CONFIG_FILE")
+	dnsCheckName = os.Getenv("DNS_CHECK_NAME")
+	dnsCheckExternalName = os.Getenv("DNS_CHECK_EXTERNAL_NAME")
+	dnsCheckLatencyMsStr := os.Getenv("DNS_CHECK_LATENCY_MS")
+	egressCheckUrl = os.Getenv("EGRESS_CHECK_URL")
+	registryCheckHosts = splitList(os.Getenv("REGISTRY_CHECK_HOSTS"))
+	trivyCriticalThresholdStr := os.Getenv("TRIVY_CRITICAL_THRESHOLD")
+	trivyStateStr := os.Getenv("TRIVY_STATE")
+	haColorVulnerabilitiesStr := os.Getenv("HA_COLOR_VULNERABILITIES")
+	policyMinSeverityStr := os.Getenv("POLICY_MIN_SEVERITY")
+	lbPendingGraceMinutesStr := os.Getenv("LB_PENDING_GRACE_MINUTES")
+	capacitySeverityStr := os.Getenv("CAPACITY_SEVERITY")
+	if v := os.Getenv("REBOOT_REQUIRED_ANNOTATION"); v != "" {
+		rebootRequiredAnnotation = v
+	}
+	rebootRequiredStateStr := os.Getenv("REBOOT_REQUIRED_STATE")
+	haColorRebootRequiredStr := os.Getenv("HA_COLOR_REBOOT_REQUIRED")
+	k8sEOLUrl = os.Getenv("K8S_EOL_URL")
+	k8sMaxSkewStr := os.Getenv("K8S_MAX_SKEW")
+	deprecatedAPIScan = os.Getenv("DEPRECATED_API_SCAN") == "true"
+	nodeClockSkewSecondsStr := os.Getenv("NODE_CLOCK_SKEW_SECONDS")
+	if v := os.Getenv("GPU_NODE_SELECTOR"); v != "" {
+		gpuNodeSelector = v
+	}
+	gpuExpectedCountStr := os.Getenv("GPU_EXPECTED_COUNT")
+	criticalWorkloads = splitList(os.Getenv("CRITICAL_WORKLOADS"))
+	etcdBackupPath = os.Getenv("ETCD_BACKUP_PATH")
+	argoRolloutsDashboardUrl = os.Getenv("ARGO_ROLLOUTS_DASHBOARD_URL")
+	if v := os.Getenv("SPOT_NODE_LABELS"); v != "" {
+		spotNodeLabels = splitList(v)
+	}
+	if v := os.Getenv("SPOT_TERMINATION_SIGNALS"); v != "" {
+		spotTerminationSignals = splitList(v)
+	}
+	haColorSpotInterruptionStr := os.Getenv("HA_COLOR_SPOT_INTERRUPTION")
+	preemptionThresholdStr := os.Getenv("PREEMPTION_THRESHOLD")
+	preemptionWindowMinutesStr := os.Getenv("PREEMPTION_WINDOW_MINUTES")
+	csrPendingMinutesStr := os.Getenv("CSR_PENDING_MINUTES")
+	issueTTLMinutesStr := os.Getenv("ISSUE_TTL_MINUTES")
+	issueMaxEntriesStr := os.Getenv("ISSUE_MAX_ENTRIES")
+	ntfyStateChanges = os.Getenv("NTFY_STATE_CHANGES") == "true"
+	historyFile = os.Getenv("HISTORY_FILE")
+	historyRetentionDaysStr := os.Getenv("HISTORY_RETENTION_DAYS")
+	sloTargetStr := os.Getenv("SLO_TARGET")
+	tlsCAFile = os.Getenv("TLS_CA_FILE")
+	tlsInsecureSkipVerify = os.Getenv("TLS_INSECURE_SKIP_VERIFY") == "true"
+	httpTimeoutsStr := os.Getenv("HTTP_TIMEOUTS")
+	haStateTargetsStr := os.Getenv("HA_STATE_TARGETS")
+	haLightTransitionStr := os.Getenv("HA_LIGHT_TRANSITION")
+	haBreatheStatesStr := os.Getenv("HA_BREATHE_STATES")
+	haStateRGBWStr := os.Getenv("HA_STATE_RGBW")
+	lametricUrl = os.Getenv("LAMETRIC_URL")
+	nanoleafToken = os.Getenv("NANOLEAF_TOKEN")
+	lametricApiKey = os.Getenv("LAMETRIC_API_KEY")
+	lametricIcon = os.Getenv("LAMETRIC_ICON")
+	pixooUrl = os.Getenv("PIXOO_URL")
+	kubeconfig = os.Getenv("KUBECONFIG")
+	busylightDevice = os.Getenv("BUSYLIGHT_DEVICE")
+	busylightBlinkStatesStr := os.Getenv("BUSYLIGHT_BLINK_STATES")
+	streamDeckDevice = os.Getenv("STREAMDECK_DEVICE")
+	streamDeckKeysStr := os.Getenv("STREAMDECK_KEYS")
+	snoozeMinutesStr := os.Getenv("SNOOZE_MINUTES")
+	haMuteEntityId = os.Getenv("HA_MUTE_ENTITY_ID")
+	haMuteState = os.Getenv("HA_MUTE_STATE")
+	namespaceLightsStr := os.Getenv("NAMESPACE_LIGHTS")
+	integrationFailureLimitStr := os.Getenv("INTEGRATION_FAILURE_LIMIT")
+	integrationDegradedStateStr := os.Getenv("INTEGRATION_DEGRADED_STATE")
+	checkTimeoutStr := os.Getenv("CHECK_TIMEOUT_SECONDS")
+	ntfyAttachReportStr := os.Getenv("NTFY_ATTACH_REPORT")
+	ntfyAttachPriorityStr := os.Getenv("NTFY_ATTACH_PRIORITY")
+	eventStormThresholdStr := os.Getenv("EVENT_STORM_THRESHOLD")
+	warmupMinutesStr := os.Getenv("WARMUP_MINUTES")
+	warmupOnClusterBoot = os.Getenv("WARMUP_ON_CLUSTER_BOOT") == "true"
+	haColorStartingStr := os.Getenv("HA_COLOR_STARTING")
+	clusterBootMinutesStr := os.Getenv("CLUSTER_BOOT_MINUTES")
+	zoneOutageMinutesStr := os.Getenv("ZONE_OUTAGE_MINUTES")
+	haColorBootingStr := os.Getenv("HA_COLOR_BOOTING")
+	haOffWhenHealthy = os.Getenv("HA_OFF_WHEN_HEALTHY") == "true"
+	haMediaPlayerEntityId = os.Getenv("HA_MEDIA_PLAYER_ENTITY_ID")
+	haSoundUrl = os.Getenv("HA_SOUND_URL")
+	haSoundVolumeStr := os.Getenv("HA_SOUND_VOLUME")
+	soundStatesStr := os.Getenv("SOUND_STATES")
+	soundIntervalStr := os.Getenv("SOUND_INTERVAL_MINUTES")
+	ntfyIntervalStr := os.Getenv("NTFY_INTERVAL_MINUTES")
+	quietHoursStr := os.Getenv("QUIET_HOURS")
+	ntfyRecoveriesStr := os.Getenv("NTFY_RECOVERIES")
+	provisioningCheck = os.Getenv("PROVISIONING_CHECK") == "true"
+	opencostUrl = strings.TrimRight(os.Getenv("OPENCOST_URL"), "/")
+	provisioningMinEfficiencyStr := os.Getenv("PROVISIONING_MIN_EFFICIENCY")
+	provisioningMaxEfficiencyStr := os.Getenv("PROVISIONING_MAX_EFFICIENCY")
+	kubeBenchConfigMap = os.Getenv("KUBE_BENCH_CONFIGMAP")
+	if v := os.Getenv("KUBE_BENCH_CONFIGMAP_KEY"); v != "" {
+		kubeBenchConfigMapKey = v
+	}
+	kubeBenchJob = os.Getenv("KUBE_BENCH_JOB")
+	falcoWebhookToken = os.Getenv("FALCO_WEBHOOK_TOKEN")
+	falcoAlertTTLStr := os.Getenv("FALCO_ALERT_TTL_MINUTES")
+	nodeTempThresholdStr := os.Getenv("NODE_TEMP_THRESHOLD")
+	smartCheck = os.Getenv("SMART_CHECK") == "true"
+	ghTicketRepo = os.Getenv("GH_TICKET_REPO")
+	if v := os.Getenv("GH_TICKET_LABEL"); v != "" {
+		ghTicketLabel = v
+	}
+	ghTicketAfterStr := os.Getenv("GH_TICKET_AFTER_MINUTES")
+	jiraUrl = os.Getenv("JIRA_URL")
+	jiraUser = os.Getenv("JIRA_USER")
+	jiraApiToken = os.Getenv("JIRA_API_TOKEN")
+	jiraProject = os.Getenv("JIRA_PROJECT")
+	if v := os.Getenv("JIRA_ISSUE_TYPE"); v != "" {
+		jiraIssueType = v
+	}
+	if v := os.Getenv("JIRA_DONE_TRANSITION"); v != "" {
+		jiraDoneTransition = v
+	}
+	jiraAfterStr := os.Getenv("JIRA_AFTER_MINUTES")
+	lightDriverStr := os.Getenv("LIGHT_DRIVER")
+	notifyDriverStr := os.Getenv("NOTIFY_DRIVER")
+	recordingFile = os.Getenv("RECORDING_FILE")
+	warningEventsStateStr := os.Getenv("WARNING_EVENTS_STATE")
+	haColorWarningEventsStr := os.Getenv("HA_COLOR_WARNING_EVENTS")
+	bulbCountMode = os.Getenv("BULB_COUNT_MODE")
+	bulbCountMaxStr := os.Getenv("BULB_COUNT_MAX")
+	sloBudgetLowPercentStr := os.Getenv("SLO_BUDGET_LOW_PERCENT")
+	haColorSLOBudgetLowStr := os.Getenv("HA_COLOR_SLO_BUDGET_LOW")
+	etcdBackupMaxAgeHoursStr := os.Getenv("ETCD_BACKUP_MAX_AGE_HOURS")
+	alertmanagerUrl = os.Getenv("ALERTMANAGER_URL")
+	alertmanagerMatchers = splitList(os.Getenv("ALERTMANAGER_MATCHERS"))
+
+	// Parse GH_PR_CHECK_INTERVAL (seconds) with a default
+	ghPRCheckInterval = 300 // default seconds (5 minutes)
+	if ghPRCheckIntervalStr != "" {
+		if v, err := strconv.Atoi(ghPRCheckIntervalStr); err == nil && v > 0 {
+			ghPRCheckInterval = v
+		} else {
+			log.Printf("Invalid GH_PR_CHECK_INTERVAL '%s', using default %d", ghPRCheckIntervalStr, ghPRCheckInterval)
+			os.Exit(1)
+		}
+	}
+	// The GraphQL API does not allow anonymous access
+	if ghUseGraphQL && ghToken.Get() == "" {
+		log.Printf("GH_USE_GRAPHQL requires GH_TOKEN to be set")
+		os.Exit(1)
+	}
+	// Validate GH_REPO_GLOB so a bad pattern fails fast instead of matching nothing
+	if ghRepoGlob != "" {
+		if _, err := path.Match(ghRepoGlob, ""); err != nil {
+			log.Printf("Invalid GH_REPO_GLOB '%s': %v", ghRepoGlob, err)
+			os.Exit(1)
+		}
+	}
+	// Parse GH_PR_IGNORED_MODE and the optional color for ignored PRs
+	switch ghPRIgnoredModeStr {
+	case "", "exclude":
+	case "color":
+		ghPRIgnoredMode = "color"
+	default:
+		log.Printf("Invalid GH_PR_IGNORED_MODE '%s', expected exclude or color", ghPRIgnoredModeStr)
+		os.Exit(1)
+	}
+	if haColorPRsIgnoredStr != "" {
+		color, err := parseRGB(haColorPRsIgnoredStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_PRS_IGNORED '%s': %v", haColorPRsIgnoredStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalPullRequestsIgnored, color)
+	}
+	if haColorPRsBotsStr != "" {
+		color, err := parseRGB(haColorPRsBotsStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_PRS_BOTS '%s': %v", haColorPRsBotsStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalDependencyUpdatesOpen, color)
+	}
+	if haColorPRsBlockedStr != "" {
+		color, err := parseRGB(haColorPRsBlockedStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_PRS_BLOCKED '%s': %v", haColorPRsBlockedStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalPullRequestsBlocked, color)
+	}
+	// Validate the security alert sources, minimum severity and color
+	for _, source := range ghSecurityAlerts {
+		if source != "dependabot" && source != "code-scanning" {
+			log.Printf("Invalid GH_SECURITY_ALERTS source '%s', expected dependabot or code-scanning", source)
+			os.Exit(1)
+		}
+	}
+	if ghSecurityMinSeverityStr != "" {
+		if _, ok := ghSeverityRank[ghSecurityMinSeverityStr]; !ok {
+			log.Printf("Invalid GH_SECURITY_MIN_SEVERITY '%s', expected %s", ghSecurityMinSeverityStr, ghSeverityNames())
+			os.Exit(1)
+		}
+		ghSecurityMinSeverity = ghSecurityMinSeverityStr
+	}
+	if haColorSecurityAlertsStr != "" {
+		color, err := parseRGB(haColorSecurityAlertsStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_SECURITY_ALERTS '%s': %v", haColorSecurityAlertsStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalSecurityAlertsOpen, color)
+	}
+	// Validate the state and color used for labeled GitHub issues
+	if haColorIncidentsStr != "" {
+		color, err := parseRGB(haColorIncidentsStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_INCIDENTS '%s': %v", haColorIncidentsStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalIncidentsOpen, color)
+	}
+	if ghIssueLabelStateStr != "" {
+		state, err := parseProblemState(ghIssueLabelStateStr)
+		if err != nil {
+			log.Printf("Invalid GH_ISSUE_LABEL_STATE '%s': %v", ghIssueLabelStateStr, err)
+			os.Exit(1)
+		}
+		ghIssueLabelState = state
+	}
+	// Parse the PR escalation thresholds and color
+	if ghPREscalateCountStr != "" {
+		if v, err := strconv.Atoi(ghPREscalateCountStr); err == nil && v >= 0 {
+			ghPREscalateCount = v
+		} else {
+			log.Printf("Invalid GH_PR_ESCALATE_COUNT '%s'", ghPREscalateCountStr)
+			os.Exit(1)
+		}
+	}
+	if ghPREscalateAgeDaysStr != "" {
+		if v, err := strconv.Atoi(ghPREscalateAgeDaysStr); err == nil && v >= 0 {
+			ghPREscalateAgeDays = v
+		} else {
+			log.Printf("Invalid GH_PR_ESCALATE_AGE_DAYS '%s'", ghPREscalateAgeDaysStr)
+			os.Exit(1)
+		}
+	}
+	if haColorPRsEscalatedStr != "" {
+		color, err := parseRGB(haColorPRsEscalatedStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_PRS_ESCALATED '%s': %v", haColorPRsEscalatedStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalPullRequestsEscalated, color)
+	}
+	// Select the SCM provider used for pull request and CI checks
+	provider, err := newSCMProvider(scmProviderName)
+	if err != nil {
+		log.Printf("Invalid SCM_PROVIDER '%s': %v", scmProviderName, err)
+		os.Exit(1)
+	}
+	scmProvider = provider
+	// Parse HA_LIGHT_BRIGHTNESS and ensure it is a valid integer between 0-255
+	haLightBrightness = 255 // default brightness
+	if haLightBrightnessStr != "" {
+		if v, err := strconv.Atoi(haLightBrightnessStr); err == nil {
+			if v > 255 || v < 0 {
+				log.Printf("HA_LIGHT_BRIGHTNESS '%s' out of range (0-255)", haLightBrightnessStr)
+				os.Exit(1)
+			}
+			haLightBrightness = v
+		} else {
+			log.Printf("Invalid HA_LIGHT_BRIGHTNESS '%s', expected an integer between 0-255", haLightBrightnessStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse DNS_CHECK_LATENCY_MS (milliseconds) with a default
+	if dnsCheckLatencyMsStr != "" {
+		if v, err := strconv.Atoi(dnsCheckLatencyMsStr); err == nil && v > 0 {
+			dnsCheckLatencyMs = v
+		} else {
+			log.Printf("Invalid DNS_CHECK_LATENCY_MS '%s'", dnsCheckLatencyMsStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse the Trivy threshold and validate its state and color
+	if trivyCriticalThresholdStr != "" {
+		if v, err := strconv.Atoi(trivyCriticalThresholdStr); err == nil && v >= 0 {
+			trivyCriticalThreshold = v
+		} else {
+			log.Printf("Invalid TRIVY_CRITICAL_THRESHOLD '%s'", trivyCriticalThresholdStr)
+			os.Exit(1)
+		}
+	}
+	if haColorVulnerabilitiesStr != "" {
+		color, err := parseRGB(haColorVulnerabilitiesStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_VULNERABILITIES '%s': %v", haColorVulnerabilitiesStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalVulnerabilitiesFound, color)
+	}
+	if trivyStateStr != "" {
+		state, err := parseProblemState(trivyStateStr)
+		if err != nil {
+			log.Printf("Invalid TRIVY_STATE '%s': %v", trivyStateStr, err)
+			os.Exit(1)
+		}
+		trivyState = state
+	}
+	issueTypeStates["Vulnerability"] = trivyState
+	if policyMinSeverityStr != "" {
+		if _, ok := policySeverityRank[policyMinSeverityStr]; !ok {
+			log.Printf("Invalid POLICY_MIN_SEVERITY '%s', expected critical, high, medium, low or info", policyMinSeverityStr)
+			os.Exit(1)
+		}
+		policyMinSeverity = policyMinSeverityStr
+	}
+
+	// Parse LB_PENDING_GRACE_MINUTES with a default
+	if lbPendingGraceMinutesStr != "" {
+		if v, err := strconv.Atoi(lbPendingGraceMinutesStr); err == nil && v >= 0 {
+			lbPendingGraceMinutes = v
+		} else {
+			log.Printf("Invalid LB_PENDING_GRACE_MINUTES '%s'", lbPendingGraceMinutesStr)
+			os.Exit(1)
+		}
+	}
+
+	if capacitySeverityStr != "" {
+		if capacitySeverityStr != "critical" && capacitySeverityStr != "warning" && capacitySeverityStr != "info" {
+			log.Printf("Invalid CAPACITY_SEVERITY '%s', expected critical, warning or info", capacitySeverityStr)
+			os.Exit(1)
+		}
+		capacitySeverity = capacitySeverityStr
+	}
+
+	// Validate the state and color used for nodes pending a reboot
+	if haColorRebootRequiredStr != "" {
+		color, err := parseRGB(haColorRebootRequiredStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_REBOOT_REQUIRED '%s': %v", haColorRebootRequiredStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalRebootRequired, color)
+	}
+	if rebootRequiredStateStr != "" {
+		state, err := parseProblemState(rebootRequiredStateStr)
+		if err != nil {
+			log.Printf("Invalid REBOOT_REQUIRED_STATE '%s': %v", rebootRequiredStateStr, err)
+			os.Exit(1)
+		}
+		rebootRequiredState = state
+		issueTypeStates["RebootRequired"] = rebootRequiredState
+	}
+
+	// Parse K8S_MAX_SKEW with a default
+	if k8sMaxSkewStr != "" {
+		if v, err := strconv.Atoi(k8sMaxSkewStr); err == nil && v >= 0 {
+			k8sMaxSkew = v
+		} else {
+			log.Printf("Invalid K8S_MAX_SKEW '%s'", k8sMaxSkewStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse NODE_CLOCK_SKEW_SECONDS with a default
+	if nodeClockSkewSecondsStr != "" {
+		if v, err := strconv.Atoi(nodeClockSkewSecondsStr); err == nil && v > 0 {
+			nodeClockSkewSeconds = v
+		} else {
+			log.Printf("Invalid NODE_CLOCK_SKEW_SECONDS '%s'", nodeClockSkewSecondsStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse GPU_EXPECTED_COUNT
+	if gpuExpectedCountStr != "" {
+		if v, err := strconv.Atoi(gpuExpectedCountStr); err == nil && v >= 0 {
+			gpuExpectedCount = v
+		} else {
+			log.Printf("Invalid GPU_EXPECTED_COUNT '%s'", gpuExpectedCountStr)
+			os.Exit(1)
+		}
+	}
+
+	for _, workload := range criticalWorkloads {
+		if !strings.Contains(workload, "/") {
+			log.Printf("Invalid CRITICAL_WORKLOADS entry '%s', expected namespace/name", workload)
+			os.Exit(1)
+		}
+	}
+
+	// Parse ETCD_BACKUP_MAX_AGE_HOURS with a default
+	if etcdBackupMaxAgeHoursStr != "" {
+		if v, err := strconv.Atoi(etcdBackupMaxAgeHoursStr); err == nil && v > 0 {
+			etcdBackupMaxAgeHours = v
+		} else {
+			log.Printf("Invalid ETCD_BACKUP_MAX_AGE_HOURS '%s'", etcdBackupMaxAgeHoursStr)
+			os.Exit(1)
+		}
+	}
+
+	if haColorSpotInterruptionStr != "" {
+		color, err := parseRGB(haColorSpotInterruptionStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_SPOT_INTERRUPTION '%s': %v", haColorSpotInterruptionStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalSpotInterruption, color)
+	}
+	issueTypeStates["SpotInterruption"] = SignalSpotInterruption
+
+	// Parse the preemption spike threshold and window
+	if preemptionThresholdStr != "" {
+		if v, err := strconv.Atoi(preemptionThresholdStr); err == nil && v >= 0 {
+			preemptionThreshold = v
+		} else {
+			log.Printf("Invalid PREEMPTION_THRESHOLD '%s'", preemptionThresholdStr)
+			os.Exit(1)
+		}
+	}
+	if preemptionWindowMinutesStr != "" {
+		if v, err := strconv.Atoi(preemptionWindowMinutesStr); err == nil && v > 0 {
+			preemptionWindowMinutes = v
+		} else {
+			log.Printf("Invalid PREEMPTION_WINDOW_MINUTES '%s'", preemptionWindowMinutesStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse CSR_PENDING_MINUTES with a default
+	if csrPendingMinutesStr != "" {
+		if v, err := strconv.Atoi(csrPendingMinutesStr); err == nil && v > 0 {
+			csrPendingMinutes = v
+		} else {
+			log.Printf("Invalid CSR_PENDING_MINUTES '%s'", csrPendingMinutesStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse the issue TTL and size cap
+	if issueTTLMinutesStr != "" {
+		if v, err := strconv.Atoi(issueTTLMinutesStr); err == nil && v > 0 {
+			issueTTLMinutes = v
+		} else {
+			log.Printf("Invalid ISSUE_TTL_MINUTES '%s'", issueTTLMinutesStr)
+			os.Exit(1)
+		}
+	}
+	if issueMaxEntriesStr != "" {
+		if v, err := strconv.Atoi(issueMaxEntriesStr); err == nil && v > 0 {
+			issueMaxEntries = v
+		} else {
+			log.Printf("Invalid ISSUE_MAX_ENTRIES '%s'", issueMaxEntriesStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse the history retention and restore the persisted history
+	if historyRetentionDaysStr != "" {
+		if v, err := strconv.Atoi(historyRetentionDaysStr); err == nil && v > 0 {
+			historyRetentionDays = v
+		} else {
+			log.Printf("Invalid HISTORY_RETENTION_DAYS '%s'", historyRetentionDaysStr)
+			os.Exit(1)
+		}
+	}
+	if historyFile != "" {
+		if err := loadHistory(); err != nil {
+			log.Printf("Failed to load history file %s: %v", historyFile, err)
+			os.Exit(1)
+		}
+	}
+
+	// Parse the SLO target, budget threshold and color
+	if sloTargetStr != "" {
+		if v, err := strconv.ParseFloat(sloTargetStr, 64); err == nil && v > 0 && v < 100 {
+			sloTarget = v
+		} else {
+			log.Printf("Invalid SLO_TARGET '%s', expected a percentage such as 99.5", sloTargetStr)
+			os.Exit(1)
+		}
+	}
+	if sloBudgetLowPercentStr != "" {
+		if v, err := strconv.ParseFloat(sloBudgetLowPercentStr, 64); err == nil && v >= 0 && v <= 100 {
+			sloBudgetLowPercent = v
+		} else {
+			log.Printf("Invalid SLO_BUDGET_LOW_PERCENT '%s'", sloBudgetLowPercentStr)
+			os.Exit(1)
+		}
+	}
+	if haColorSLOBudgetLowStr != "" {
+		color, err := parseRGB(haColorSLOBudgetLowStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_SLO_BUDGET_LOW '%s': %v", haColorSLOBudgetLowStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalSLOBudgetLow, color)
+	}
+
+	// Apply proxy, TLS and timeout settings to outbound HTTP calls
+	if err := configureOutboundTLS(); err != nil {
+		log.Printf("Invalid TLS configuration: %v", err)
+		os.Exit(1)
+	}
+	if err := parseHTTPTimeouts(httpTimeoutsStr); err != nil {
+		log.Printf("Invalid HTTP_TIMEOUTS '%s': %v", httpTimeoutsStr, err)
+		os.Exit(1)
+	}
+
+	// Validate the optional blink count encoding
+	if bulbCountMode != "" && bulbCountMode != "issues" && bulbCountMode != "prs" {
+		log.Printf("Invalid BULB_COUNT_MODE '%s', expected issues or prs", bulbCountMode)
+		os.Exit(1)
+	}
+	if bulbCountMaxStr != "" {
+		if v, err := strconv.Atoi(bulbCountMaxStr); err == nil && v > 0 {
+			bulbCountMax = v
+		} else {
+			log.Printf("Invalid BULB_COUNT_MAX '%s'", bulbCountMaxStr)
+			os.Exit(1)
+		}
+	}
+
+	// Validate the state and color used when only warning events are present
+	if haColorWarningEventsStr != "" {
+		color, err := parseRGB(haColorWarningEventsStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_WARNING_EVENTS '%s': %v", haColorWarningEventsStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalWarningEvents, color)
+	}
+	if warningEventsStateStr != "" {
+		state, err := parseProblemState(warningEventsStateStr)
+		if err != nil {
+			log.Printf("Invalid WARNING_EVENTS_STATE '%s': %v", warningEventsStateStr, err)
+			os.Exit(1)
+		}
+		warningEventsState = state
+	}
+
+	// Parse the optional scene and script targets, after every state color is known
+	if err := parseHAStateTargets(haStateTargetsStr); err != nil {
+		log.Printf("Invalid HA_STATE_TARGETS '%s': %v", haStateTargetsStr, err)
+		os.Exit(1)
+	}
+
+	// Parse the fade duration and the breathing states
+	if haLightTransitionStr != "" {
+		if v, err := strconv.ParseFloat(haLightTransitionStr, 64); err == nil && v >= 0 {
+			haLightTransition = v
+		} else {
+			log.Printf("Invalid HA_LIGHT_TRANSITION '%s', expected seconds", haLightTransitionStr)
+			os.Exit(1)
+		}
+	}
+	for _, state := range splitList(haBreatheStatesStr) {
+		signal, err := parseProblemState(state)
+		if err != nil {
+			log.Printf("Invalid HA_BREATHE_STATES entry '%s': %v", state, err)
+			os.Exit(1)
+		}
+		haBreatheStates = append(haBreatheStates, signal)
+	}
+
+	if err := parseHAStateRGBW(haStateRGBWStr); err != nil {
+		log.Printf("Invalid HA_STATE_RGBW '%s': %v", haStateRGBWStr, err)
+		os.Exit(1)
+	}
+
+	// Register the optional displays
+	if lametricUrl != "" {
+		if lametricApiKey == "" {
+			log.Printf("LAMETRIC_API_KEY is required with LAMETRIC_URL")
+			os.Exit(1)
+		}
+		displays = append(displays, lametricDisplay{})
+	}
+	if pixooUrl != "" {
+		displays = append(displays, pixooDisplay{})
+	}
+	if busylightBlinkStatesStr != "" {
+		busylightBlinkStates = nil
+		for _, state := range splitList(busylightBlinkStatesStr) {
+			signal, err := parseProblemState(state)
+			if err != nil {
+				log.Printf("Invalid BUSYLIGHT_BLINK_STATES entry '%s': %v", state, err)
+				os.Exit(1)
+			}
+			busylightBlinkStates = append(busylightBlinkStates, signal)
+		}
+	}
+	if busylightDevice != "" {
+		busylight, err := newBusylightDisplay(busylightDevice)
+		if err != nil {
+			log.Printf("Invalid BUSYLIGHT_DEVICE '%s': %v", busylightDevice, err)
+			os.Exit(1)
+		}
+		log.Printf("Using %s at %s", busylight.Name(), busylight.path)
+		displays = append(displays, busylight)
+	}
+	if streamDeckKeysStr != "" {
+		if err := parseStreamDeckKeys(streamDeckKeysStr); err != nil {
+			log.Printf("Invalid STREAMDECK_KEYS '%s': %v", streamDeckKeysStr, err)
+			os.Exit(1)
+		}
+	}
+	if streamDeckDevice != "" {
+		deck, err := newStreamDeckDisplay(streamDeckDevice)
+		if err != nil {
+			log.Printf("Invalid STREAMDECK_DEVICE '%s': %v", streamDeckDevice, err)
+			os.Exit(1)
+		}
+		log.Printf("Using %s at %s", deck.Name(), deck.path)
+		displays = append(displays, deck)
+	}
+
+	if err := parseNamespaceLights(namespaceLightsStr); err != nil {
+		log.Printf("Invalid NAMESPACE_LIGHTS '%s': %v", namespaceLightsStr, err)
+		os.Exit(1)
+	}
+
+	// Parse SNOOZE_MINUTES with a default
+	if snoozeMinutesStr != "" {
+		if v, err := strconv.Atoi(snoozeMinutesStr); err == nil && v > 0 {
+			snoozeMinutes = v
+		} else {
+			log.Printf("Invalid SNOOZE_MINUTES '%s'", snoozeMinutesStr)
+			os.Exit(1)
+		}
+	}
+
+	// Parse the integration failure limit and the state of degraded integrations
+	if integrationFailureLimitStr != "" {
+		if v, err := strconv.Atoi(integrationFailureLimitStr); err == nil && v > 0 {
+			integrationFailureLimit = v
+		} else {
+			log.Printf("Invalid INTEGRATION_FAILURE_LIMIT '%s'", integrationFailureLimitStr)
+			os.Exit(1)
+		}
+	}
+	if integrationDegradedStateStr != "" {
+		state, err := parseProblemState(integrationDegradedStateStr)
+		if err != nil {
+			log.Printf("Invalid INTEGRATION_DEGRADED_STATE '%s': %v", integrationDegradedStateStr, err)
+			os.Exit(1)
+		}
+		integrationDegradedState = state
+		issueTypeStates["IntegrationDegraded"] = integrationDegradedState
+	}
+	for _, v := range splitList(telegramChatIdsStr) {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			log.Printf("Invalid TELEGRAM_CHAT_IDS entry '%s'", v)
+			os.Exit(1)
+		}
+		telegramChatIds = append(telegramChatIds, id)
+	}
+	if checkTimeoutStr != "" {
+		if v, err := strconv.Atoi(checkTimeoutStr); err == nil && v > 0 {
+			checkTimeout = time.Duration(v) * time.Second
+		} else {
+			log.Printf("Invalid CHECK_TIMEOUT_SECONDS '%s'", checkTimeoutStr)
+			os.Exit(1)
+		}
+	}
+	if ntfyAttachReportStr != "" {
+		if !slices.Contains([]string{"inline", "attach", "auto"}, ntfyAttachReportStr) {
+			log.Printf("Invalid NTFY_ATTACH_REPORT '%s', expected inline, attach or auto", ntfyAttachReportStr)
+			os.Exit(1)
+		}
+		ntfyAttachReport = ntfyAttachReportStr
+	}
+	if ntfyAttachPriorityStr != "" {
+		if v, err := strconv.Atoi(ntfyAttachPriorityStr); err == nil && v >= 1 && v <= 5 {
+			ntfyAttachPriority = v
+		} else {
+			log.Printf("Invalid NTFY_ATTACH_PRIORITY '%s', expected 1-5", ntfyAttachPriorityStr)
+			os.Exit(1)
+		}
+	}
+	if eventStormThresholdStr != "" {
+		if v, err := strconv.Atoi(eventStormThresholdStr); err == nil && v >= 0 {
+			eventStormThreshold = v
+		} else {
+			log.Printf("Invalid EVENT_STORM_THRESHOLD '%s'", eventStormThresholdStr)
+			os.Exit(1)
+		}
+	}
+	if warmupMinutesStr != "" {
+		if v, err := strconv.Atoi(warmupMinutesStr); err == nil && v >= 0 {
+			warmupDuration = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid WARMUP_MINUTES '%s'", warmupMinutesStr)
+			os.Exit(1)
+		}
+	}
+	if haColorStartingStr != "" {
+		color, err := parseRGB(haColorStartingStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_STARTING '%s': %v", haColorStartingStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalStarting, color)
+	}
+	if clusterBootMinutesStr != "" {
+		if v, err := strconv.Atoi(clusterBootMinutesStr); err == nil && v >= 0 {
+			clusterBootWindow = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid CLUSTER_BOOT_MINUTES '%s'", clusterBootMinutesStr)
+			os.Exit(1)
+		}
+	}
+	if zoneOutageMinutesStr != "" {
+		if v, err := strconv.Atoi(zoneOutageMinutesStr); err == nil && v >= 0 {
+			zoneOutageWindow = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid ZONE_OUTAGE_MINUTES '%s'", zoneOutageMinutesStr)
+			os.Exit(1)
+		}
+	}
+	if haColorBootingStr != "" {
+		color, err := parseRGB(haColorBootingStr)
+		if err != nil {
+			log.Printf("Invalid HA_COLOR_BOOTING '%s': %v", haColorBootingStr, err)
+			os.Exit(1)
+		}
+		setStateColor(SignalBooting, color)
+	}
+	if haMediaPlayerEntityId != "" && haSoundUrl == "" {
+		log.Printf("HA_SOUND_URL is required with HA_MEDIA_PLAYER_ENTITY_ID")
+		os.Exit(1)
+	}
+	if haSoundVolumeStr != "" {
+		if v, err := strconv.ParseFloat(haSoundVolumeStr, 64); err == nil && v >= 0 && v <= 1 {
+			haSoundVolume = v
+		} else {
+			log.Printf("Invalid HA_SOUND_VOLUME '%s', expected a number between 0-1", haSoundVolumeStr)
+			os.Exit(1)
+		}
+	}
+	if soundStatesStr != "" {
+		soundStates = nil
+		for _, state := range splitList(soundStatesStr) {
+			signal, err := parseProblemState(state)
+			if err != nil {
+				log.Printf("Invalid SOUND_STATES entry '%s': %v", state, err)
+				os.Exit(1)
+			}
+			soundStates = append(soundStates, signal)
+		}
+	}
+	if soundIntervalStr != "" {
+		if v, err := strconv.Atoi(soundIntervalStr); err == nil && v >= 0 {
+			soundInterval = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid SOUND_INTERVAL_MINUTES '%s'", soundIntervalStr)
+			os.Exit(1)
+		}
+	}
+	if ntfyIntervalStr != "" {
+		if v, err := strconv.Atoi(ntfyIntervalStr); err == nil && v >= 0 {
+			ntfyInterval = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid NTFY_INTERVAL_MINUTES '%s'", ntfyIntervalStr)
+			os.Exit(1)
+		}
+	}
+	if quietHoursStr != "" {
+		rule, err := parseQuietHours(quietHoursStr)
+		if err != nil {
+			log.Printf("Invalid QUIET_HOURS '%s': %v", quietHoursStr, err)
+			os.Exit(1)
+		}
+		quietHours = rule
+	}
+	if err := parseNtfyRecoveries(ntfyRecoveriesStr); err != nil {
+		log.Printf("Invalid NTFY_RECOVERIES '%s': %v", ntfyRecoveriesStr, err)
+		os.Exit(1)
+	}
+	if provisioningMinEfficiencyStr != "" {
+		if v, err := strconv.Atoi(provisioningMinEfficiencyStr); err == nil && v >= 0 {
+			provisioningMinEfficiency = v
+		} else {
+			log.Printf("Invalid PROVISIONING_MIN_EFFICIENCY '%s'", provisioningMinEfficiencyStr)
+			os.Exit(1)
+		}
+	}
+	if provisioningMaxEfficiencyStr != "" {
+		if v, err := strconv.Atoi(provisioningMaxEfficiencyStr); err == nil && v > provisioningMinEfficiency {
+			provisioningMaxEfficiency = v
+		} else {
+			log.Printf("Invalid PROVISIONING_MAX_EFFICIENCY '%s', expected a percentage above PROVISIONING_MIN_EFFICIENCY", provisioningMaxEfficiencyStr)
+			os.Exit(1)
+		}
+	}
+	if kubeBenchConfigMap != "" && !strings.Contains(kubeBenchConfigMap, "/") {
+		log.Printf("Invalid KUBE_BENCH_CONFIGMAP '%s', expected namespace/name", kubeBenchConfigMap)
+		os.Exit(1)
+	}
+	if kubeBenchJob != "" && !strings.Contains(kubeBenchJob, "/") {
+		log.Printf("Invalid KUBE_BENCH_JOB '%s', expected namespace/label selector", kubeBenchJob)
+		os.Exit(1)
+	}
+	if falcoAlertTTLStr != "" {
+		if v, err := strconv.Atoi(falcoAlertTTLStr); err == nil && v > 0 {
+			falcoAlertTTL = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid FALCO_ALERT_TTL_MINUTES '%s'", falcoAlertTTLStr)
+			os.Exit(1)
+		}
+	}
+	if nodeTempThresholdStr != "" {
+		if v, err := strconv.ParseFloat(nodeTempThresholdStr, 64); err == nil && v > 0 {
+			nodeTempThreshold = v
+		} else {
+			log.Printf("Invalid NODE_TEMP_THRESHOLD '%s', expected degrees Celsius", nodeTempThresholdStr)
+			os.Exit(1)
+		}
+	}
+	if ghTicketRepo != "" {
+		if owner, repo, ok := strings.Cut(ghTicketRepo, "/"); !ok || owner == "" || repo == "" {
+			log.Printf("Invalid GH_TICKET_REPO '%s', expected owner/repo", ghTicketRepo)
+			os.Exit(1)
+		}
+		if ghToken.Get() == "" {
+			log.Printf("GH_TICKET_REPO needs GH_TOKEN with permission to write issues")
+			os.Exit(1)
+		}
+		ticketTrackers = append(ticketTrackers, githubTickets{})
+	}
+	if ghTicketAfterStr != "" {
+		if v, err := strconv.Atoi(ghTicketAfterStr); err == nil && v >= 0 {
+			ghTicketAfter = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid GH_TICKET_AFTER_MINUTES '%s'", ghTicketAfterStr)
+			os.Exit(1)
+		}
+	}
+	if jiraUrl != "" {
+		if jiraUser == "" || jiraApiToken == "" || jiraProject == "" {
+			log.Printf("JIRA_URL needs JIRA_USER, JIRA_API_TOKEN and JIRA_PROJECT")
+			os.Exit(1)
+		}
+		ticketTrackers = append(ticketTrackers, jiraTickets{})
+	}
+	if jiraAfterStr != "" {
+		if v, err := strconv.Atoi(jiraAfterStr); err == nil && v >= 0 {
+			jiraAfter = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid JIRA_AFTER_MINUTES '%s'", jiraAfterStr)
+			os.Exit(1)
+		}
+	}
+	if haLightArea != "" && haLightEntityId != "" {
+		log.Printf("HA_LIGHT_AREA and HA_LIGHT_ENTITY_ID can't be combined")
+		os.Exit(1)
+	}
+	if tokenReloadStr != "" {
+		if v, err := strconv.Atoi(tokenReloadStr); err == nil && v > 0 {
+			tokenReloadInterval = time.Duration(v) * time.Minute
+		} else {
+			log.Printf("Invalid TOKEN_RELOAD_MINUTES '%s'", tokenReloadStr)
+			os.Exit(1)
+		}
+	}
+	if lightDriverStr != "" {
+		if !validDriver(lightDriverStr, "homeassistant") {
+			log.Printf("Invalid LIGHT_DRIVER '%s', expected homeassistant, noop or recording", lightDriverStr)
+			os.Exit(1)
+		}
+		lightDriver = lightDriverStr
+	}
+	if notifyDriverStr != "" {
+		if !validDriver(notifyDriverStr, "ntfy") {
+			log.Printf("Invalid NOTIFY_DRIVER '%s', expected ntfy, noop or recording", notifyDriverStr)
+			os.Exit(1)
+		}
+		notifyDriver = notifyDriverStr
+	}
+	// The test drivers need an entity to address, but not a real one
+	if lightDriver != "homeassistant" && haLightEntityId == "" && haLightArea == "" {
+		haLightEntityId = "light.clusterbulb"
+	}
+
+	// Load the optional YAML config file
+	if configFile != "" {
+		if err := loadConfig(configFile); err != nil {
+			log.Printf("Invalid CONFIG_FILE '%s': %v", configFile, err)
+			os.Exit(1)
+		}
+	}
+	if config.Nanoleaf.URL != "" {
+		displays = append(displays, &nanoleafDisplay{})
+	}
 
-func main() {
+	// Fail fast on light entities that don't exist in Home Assistant
+	haCtx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	err = validateHALights(haCtx)
+	cancel()
+	if err != nil {
+		log.Printf("Invalid HA_LIGHT_ENTITY_ID or HA_LIGHT_AREA: %v", err)
+		os.Exit(1)
+	}
 
-	// Prevent running as root/superuser
-	if isSuperUser() {
-		log.Fatalf("Running with superuser privileges is not permitted.")
+	// Register the optional checkers
+	if alertmanagerUrl != "" {
+		registerChecker("alertmanager", 30*time.Second, checkAlertmanager)
+	}
+	if config.Prometheus.URL != "" && len(config.Prometheus.Queries) > 0 {
+		interval := config.Prometheus.Interval
+		if interval <= 0 {
+			interval = 60
+		}
+		registerChecker("promql", time.Duration(interval)*time.Second, checkPromQL)
+	}
+	if dnsCheckName != "" || dnsCheckExternalName != "" {
+		registerChecker("dns", 30*time.Second, checkDNS)
+	}
+	if egressCheckUrl != "" {
+		registerChecker("egress", 60*time.Second, checkEgress)
+	}
+	if len(registryCheckHosts) > 0 {
+		registerChecker("registry", 60*time.Second, checkRegistries)
+	}
+	if len(criticalWorkloads) > 0 {
+		registerChecker("critical", 10*time.Second, checkCriticalWorkloads)
+	}
+	registerChecker("integrations", 10*time.Second, checkIntegrations)
+	registerChecker("loadbalancers", time.Minute, checkLoadBalancers)
+	registerChecker("webhooks", 30*time.Second, checkAdmissionWebhooks)
+	registerChecker("capacity", 30*time.Second, checkCapacity)
+	registerChecker("signals", 10*time.Second, checkExternalSignals)
+	if falcoWebhookToken != "" {
+		registerChecker("falco", 10*time.Second, checkFalco)
+	}
+	if kubeBenchConfigMap != "" || kubeBenchJob != "" {
+		registerChecker("kube-bench", 30*time.Minute, checkKubeBench)
+	}
+	if provisioningCheck {
+		registerChecker("provisioning", 15*time.Minute, checkProvisioning)
+	}
+	registerChecker("preemptions", time.Minute, checkPreemptions)
+	registerChecker("reboot", 5*time.Minute, checkRebootRequired)
+	registerChecker("versions", time.Hour, checkVersions)
+	registerChecker("csrs", 5*time.Minute, checkCertificateSigningRequests)
+	registerChecker("deprecations", 10*time.Minute, checkDeprecations)
+	registerChecker("clockskew", time.Minute, checkClockSkew)
+	registerChecker("zones", 30*time.Second, checkZones)
+	registerChecker("spot", 10*time.Second, checkSpotInterruptions)
+	registerChecker("gpus", time.Minute, checkGPUs)            // no-op unless the NVIDIA device plugin is installed
+	registerChecker("trivy", 5*time.Minute, checkTrivy)        // no-op unless the Trivy Operator is installed
+	registerChecker("policies", 5*time.Minute, checkPolicies)  // no-op unless Kyverno or Gatekeeper is installed
+	registerChecker("storage", time.Minute, checkStorage)      // no-op unless Longhorn or Rook is installed
+	registerChecker("etcd", 10*time.Minute, checkEtcdBackups)  // no-op without snapshot CRs or ETCD_BACKUP_PATH
+	registerChecker("rollouts", 30*time.Second, checkRollouts) // no-op unless Argo Rollouts is installed
+	if (nodeTempThreshold > 0 || smartCheck) && config.Prometheus.URL == "" {
+		log.Printf("NODE_TEMP_THRESHOLD and SMART_CHECK need prometheus.url in CONFIG_FILE")
 		os.Exit(1)
 	}
+	if nodeTempThreshold > 0 || smartCheck {
+		registerChecker("hardware", time.Minute, checkHardware)
+	}
+	if len(config.HASensors.Sensors) > 0 && haUrl != "" && haToken.Get() != "" {
+		interval := config.HASensors.Interval
+		if interval <= 0 {
+			interval = 60
+		}
+		registerChecker("ha-sensors", time.Duration(interval)*time.Second, checkHASensors)
+	}
+	if len(config.Probes.Targets) > 0 {
+		interval := config.Probes.Interval
+		if interval <= 0 {
+			interval = 30
+		}
+		registerChecker("probes", time.Duration(interval)*time.Second, checkProbes)
+	}
 
-	// Gather environment variables
-	ghOwner = os.Getenv("GH_OWNER")
-	ghRepo = os.Getenv("GH_REPO")
-	ghToken = os.Getenv("GH_TOKEN") // optional/recommended (GitHub API rate limits apply)
-	ghPRCheckIntervalStr := os.Getenv("GH_PR_CHECK_INTERVAL")
-	haToken = os.Getenv("HA_TOKEN")
-	haUrl = os.Getenv("HA_URL")
-	haLightEntityId = os.Getenv("HA_LIGHT_ENTITY_ID")
-	haLightBrightnessStr := os.Getenv("HA_LIGHT_BRIGHTNESS")
+	logSettings()
+	startWarmup(time.Now())
+
+	// Consumers of cluster state transitions
+	onTransition(haTransition)
+	onTransition(ackTransition)
+	onTransition(metricsTransition)
+	onTransition(auditTransition)
+	onTransition(historyTransition)
+	if ntfyStateChanges {
+		onTransition(ntfyTransition)
+	}
+	if grpcListenAddr != "" {
+		onTransition(grpcTransition)
+	}
+	if haMediaPlayerEntityId != "" {
+		onTransition(soundTransition)
+	}
 
-	// Parse GH_PR_CHECK_INTERVAL (seconds) with a default
-	ghPRCheckInterval = 300 // default seconds (5 minutes)
-	if ghPRCheckIntervalStr != "" {
-		if v, err := strconv.Atoi(ghPRCheckIntervalStr); err == nil && v > 0 {
-			ghPRCheckInterval = v
-		} else {
-			log.Printf("Invalid GH_PR_CHECK_INTERVAL '%s', using default %d", ghPRCheckIntervalStr, ghPRCheckInterval)
-			os.Exit(1)
-		}
+	// Register HTTP routes and start the server
+	httpMux.HandleFunc("/metrics", metricsHandler)
+	httpMux.HandleFunc("/api/v1/history", requireToken(historyHandler))
+	httpMux.HandleFunc("/api/v1/issues", requireToken(issuesHandler))
+	httpMux.HandleFunc("/api/v1/report", requireToken(reportHandler))
+	if lightDriver == "recording" || notifyDriver == "recording" {
+		httpMux.HandleFunc("/api/v1/recording", requireToken(recordingHandler))
 	}
-	// Parse HA_LIGHT_BRIGHTNESS and ensure it is a valid integer between 0-255
-	haLightBrightness = 255 // default brightness
-	if haLightBrightnessStr != "" {
-		if v, err := strconv.Atoi(haLightBrightnessStr); err == nil && v > 0 {
-			if v > 255 || v < 1 {
-				log.Printf("HA_LIGHT_BRIGHTNESS '%s' out of range (1-255), using default %d", haLightBrightnessStr, haLightBrightness)
-				os.Exit(1)
-			}
-			haLightBrightness = v
-		} else {
-			log.Printf("Invalid HA_LIGHT_BRIGHTNESS '%s', using default %d", haLightBrightnessStr, haLightBrightness)
+	httpMux.HandleFunc("/api/v1/slo", requireToken(sloHandler))
+	httpMux.HandleFunc("/api/v1/recheck", requireToken(recheckHandler))
+	httpMux.HandleFunc("/api/v1/signal", requireToken(signalHandler))
+	httpMux.HandleFunc("/api/v1/integrations", requireToken(integrationsHandler))
+	httpMux.HandleFunc("/api/v1/transitions", requireToken(transitionsHandler))
+	httpMux.HandleFunc("/api/v1/config", requireToken(configHandler))
+	if statusPageEnabled() {
+		httpMux.HandleFunc("/status", statusPageHandler)
+		httpMux.HandleFunc("/status.json", statusPageHandler)
+	}
+	if ghWebhookSecret != "" {
+		httpMux.HandleFunc("/webhooks/github", ghWebhookHandler)
+	}
+	if falcoWebhookToken != "" {
+		httpMux.HandleFunc("/webhooks/falco", falcoWebhookHandler)
+	}
+	if slackSigningSecret != "" {
+		httpMux.HandleFunc("/webhooks/slack", slackCommandHandler)
+	}
+	if telegramWebhookSecret != "" {
+		httpMux.HandleFunc("/webhooks/telegram", telegramWebhookHandler)
+	}
+	server := startHTTPServer()
+
+	// Cancelled on SIGINT/SIGTERM so in-flight checks and requests stop promptly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	appCtx = ctx
+	startGRPCServer(ctx)
+	if tuiEngine != nil {
+		if err := startTUIEngine(tuiEngine, stop); err != nil {
+			log.Printf("%v", err)
 			os.Exit(1)
 		}
 	}
@@ -150,46 +1503,59 @@ func main() {
 	// Setup the tickers
 	tickerHABulbUpdate := time.NewTicker(1 * time.Second) // every second for smooth updates to bulb
 	tickerClusterChecks := time.NewTicker(10 * time.Second)
-	tickerGitHubPRChecks := time.NewTicker(time.Duration(ghPRCheckInterval) * time.Second)
+	tickerSCMChecks := time.NewTicker(time.Duration(ghPRCheckInterval) * time.Second)
+	tickerTokenReload := time.NewTicker(tokenReloadInterval)
 
-	// Quit channel for clean shutdown
-	quit := make(chan struct{})
+	// Closed once the scheduler has stopped
+	done := make(chan struct{})
 
 	// Run tasks concurrently
 	go func() {
+		defer close(done)
 		for {
 			select {
 			case <-tickerHABulbUpdate.C:
-				haUpdateBulb()
+				haUpdateBulb(ctx)
 			case <-tickerClusterChecks.C:
-				clusterChecks()
-			case <-tickerGitHubPRChecks.C:
-				ghPullRequestsCheck()
-			case <-quit:
+				haMuteCheck(ctx)
+				clusterChecks(ctx)
+			case <-tickerSCMChecks.C:
+				scmCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+				scmChecks(scmCtx)
+				ghSecurityAlertsCheck(scmCtx)
+				ghLabeledIssuesCheck(scmCtx)
+				cancel()
+			case <-scmRecheck:
+				// webhook triggered check, polling above remains as the reconciliation pass
+				scmCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+				scmChecks(scmCtx)
+				cancel()
+			case <-clusterRecheck:
+				clusterChecks(ctx)
+				haUpdateBulb(ctx)
+			case action := <-userActions:
+				runUserAction(action)
+			case <-tickerTokenReload.C:
+				reloadTokens()
+			case <-tokenReload:
+				reloadTokens()
+			case <-ctx.Done():
 				tickerHABulbUpdate.Stop()
 				tickerClusterChecks.Stop()
-				tickerGitHubPRChecks.Stop()
+				tickerSCMChecks.Stop()
+				tickerTokenReload.Stop()
 				fmt.Println("Scheduler stopped.")
 				return
 			}
 		}
 	}()
 
-	// Keep the main function running indefinitely
-	select {}
-}
-
-var errorCount int
-var errorLimit = 5 // change as needed
-func HandleError(msg string, err error) {
-	if err == nil {
-		return
-	}
-	errorCount++
-	fmt.Printf("%s %s %v\n", time.Now().Format(time.RFC3339), msg, err)
-	if errorCount >= errorLimit {
-		fmt.Printf("Error limit (%d) reached. Exiting.\n", errorLimit)
-		os.Exit(1)
+	// Run until the scheduler stops, then give in-flight HTTP requests a moment to finish
+	<-done
+	if server != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
 	}
 }
 
@@ -214,218 +1580,360 @@ func isSuperUser() bool {
 	return uid == 0
 }
 
-func haUpdateBulb() {
-	// Home Assistant bulb update logic
-	switch clusterState {
-	case "healthy":
-		// Set bulb to green
-		haLastColorState = "healthy"
-		haSetBulbColors(0, 255, 0)
-	case "pull_requests_open":
-		// Set bulb to blue
-		haLastColorState = "pull_requests_open"
-		haSetBulbColors(0, 0, 255)
-	case "issues_detected":
-		// Set bulb to red
-		haLastColorState = "issues_detected"
-		haSetBulbColors(255, 0, 0)
-	case "pull_requests_open|issues_detected":
-		// Set bulb to blinking red-blue
-		if haLastColorState == "issues_detected" {
-			haLastColorState = "pull_requests_open"
-			haSetBulbColors(0, 0, 255)
-		} else {
-			haLastColorState = "issues_detected"
-			haSetBulbColors(255, 0, 0)
-		}
-	}
+// haStateColors maps each cluster state to its bulb color
+var haStateColors = map[Signal][3]int{
+	SignalHealthy:               {0, 255, 0},     // green
+	SignalPullRequestsOpen:      {0, 0, 255},     // blue
+	SignalPullRequestsEscalated: {0, 255, 255},   // cyan, too many or too old PRs
+	SignalPullRequestsIgnored:   {100, 0, 255},   // violet, only used with GH_PR_IGNORED_MODE=color
+	SignalDependencyUpdatesOpen: {0, 128, 128},   // teal, only used with GH_PR_BOTS_SEPARATE=true
+	SignalPullRequestsBlocked:   {255, 200, 0},   // yellow, merge conflicts or failing required checks
+	SignalCIFailing:             {255, 0, 255},   // magenta
+	SignalSecurityAlertsOpen:    {255, 80, 0},    // orange-red, open Dependabot/code scanning alerts
+	SignalIncidentsOpen:         {255, 0, 80},    // crimson, labeled GitHub issues with GH_ISSUE_LABEL_STATE=incidents_open
+	SignalVulnerabilitiesFound:  {200, 0, 120},   // plum, critical CVEs with TRIVY_STATE=vulnerabilities_found
+	SignalSpotInterruption:      {255, 140, 0},   // amber, spot nodes with a termination notice
+	SignalRebootRequired:        {255, 255, 255}, // white, nodes pending a reboot with REBOOT_REQUIRED_STATE=reboot_required
+	SignalSLOBudgetLow:          {75, 0, 130},    // indigo, error budget of SLO_TARGET nearly spent
+	SignalWarningEvents:         {255, 165, 0},   // orange, only warning events with WARNING_EVENTS_STATE=warning_events
+	SignalIssuesDetected:        {255, 0, 0},     // red
+	SignalStarting:              {255, 220, 150}, // warm white, during the WARMUP_MINUTES grace period
+	SignalBooting:               {255, 105, 180}, // pink, the cluster restarted within CLUSTER_BOOT_MINUTES
 }
 
-func haSetBulbColors(colorR int, colorG int, colorB int) {
+// haStateColorsMu guards haStateColors, which the HA_COLOR_* env vars and the config file extend
+// while /api/v1/config and the displays read it
+var haStateColorsMu sync.RWMutex
 
-	// Ensure required environment variables are set otherwise skip
-	if haToken == "" || haUrl == "" || haLightEntityId == "" {
-		return
+// stateColor returns the bulb color of the state
+func stateColor(state Signal) ([3]int, bool) {
+	haStateColorsMu.RLock()
+	defer haStateColorsMu.RUnlock()
+	color, ok := haStateColors[state]
+	return color, ok
+}
+
+// setStateColor sets the bulb color of the state
+func setStateColor(state Signal, color [3]int) {
+	haStateColorsMu.Lock()
+	defer haStateColorsMu.Unlock()
+	haStateColors[state] = color
+}
+
+// stateColors returns a copy of the bulb colors of every state
+func stateColors() map[Signal][3]int {
+	haStateColorsMu.RLock()
+	defer haStateColorsMu.RUnlock()
+	return maps.Clone(haStateColors)
+}
+
+// parseRGB parses an "r,g,b" color with each channel between 0-255
+func parseRGB(value string) ([3]int, error) {
+	channels, err := parseChannels(value, 3)
+	if err != nil {
+		return [3]int{}, err
 	}
+	return [3]int(channels), nil
+}
 
-	// Prepare payload
-	payload := map[string]interface{}{
-		"entity_id":  haLightEntityId,
-		"rgb_color":  []int{colorR, colorG, colorB},
-		"brightness": haLightBrightness,
+// parseChannels parses n comma-separated color channels, each between 0-255
+func parseChannels(value string, n int) ([]int, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d comma-separated channels", n)
 	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		fmt.Printf("Error marshaling payload: %v\n", err)
-		return
+	channels := make([]int, n)
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || v < 0 || v > 255 {
+			return nil, fmt.Errorf("channel '%s' must be an integer between 0-255", p)
+		}
+		channels[i] = v
 	}
+	return channels, nil
+}
 
-	// Create POST request
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/services/light/turn_on", haUrl), bytes.NewBuffer(body))
-	if err != nil {
-		fmt.Printf("Error creating request: %v\n", err)
+func haUpdateBulb(ctx context.Context) {
+	// The bulb keeps its last color while muted
+	if isMuted() {
 		return
 	}
 
-	// Set headers
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", haToken))
-	req.Header.Set("Content-Type", "application/json")
+	// Commands missed while Home Assistant was unreachable catch up after this cycle's commands,
+	// which supersede those of the same entities
+	defer haReplayPending(ctx)
 
-	// Send request
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		fmt.Printf("Error sending request: %v\n", err)
+	// Namespace lights blink through their own states independently of the main bulb
+	haUpdateNamespaceLights(ctx)
+	haUpdateGroupLights(ctx)
+
+	// The bulb shows the starting color until the warm-up window ends
+	if warmingUp() {
+		if haShowSignal(ctx, SignalStarting) {
+			haLastColorState = SignalStarting
+		}
 		return
 	}
-	defer resp.Body.Close()
-}
-
-func clusterChecks() {
-	ctx := context.Background()
 
-	// In-cluster configuration
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		log.Fatalf("Failed to get in-cluster config: %v", err)
-		os.Exit(1)
+	// The optional issue count burst takes over the bulb while it runs
+	if countBurstStep(ctx) {
+		return
 	}
 
-	clientset, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		log.Fatalf("Failed to create clientset: %v", err)
-		os.Exit(1)
+	// Home Assistant bulb update logic
+	// Combined states (e.g. "pull_requests_open|issues_detected") blink through each color in turn
+	shown := shownState()
+	next := nextBlinkSignal(shown, haLastColorState)
+	if haNextColorState != "" && shown.Has(haNextColorState) {
+		next = haNextColorState
 	}
+	haNextColorState = ""
 
-	report := &HealthReport{
-		Timestamp: time.Now(),
+	// An acknowledged state shows as healthy until it changes
+	if ackedState != "" && ackedState == clusterState.String() {
+		next = SignalHealthy
 	}
 
-	nodeIssues := checkNodes(ctx, clientset)
-	podIssues := checkPods(ctx, clientset)
-	eventIssues := checkEvents(ctx, clientset)
-	report.NodeIssues = nodeIssues
-	report.PodIssues = podIssues
-	report.EventIssues = eventIssues
-	report.PullRequests = pullRequests
-	report.TotalIssues = len(nodeIssues) + len(podIssues) + len(eventIssues)
+	if haShowSignal(ctx, next) {
+		haLastColorState = next
+	}
+}
 
-	if report.TotalIssues == 0 {
-		report.ClusterState = "healthy"
-		if ghPRState == "open" {
-			report.ClusterState = "pull_requests_open"
-		}
-	} else {
-		report.ClusterState = "issues_detected"
-		if ghPRState == "open" {
-			report.ClusterState = "pull_requests_open|issues_detected"
+// nextBlinkSignal returns the signal following last in the blink cycle of the state
+func nextBlinkSignal(state ClusterState, last Signal) Signal {
+	signals := state.Signals()
+	for i, signal := range signals {
+		if signal == last {
+			return signals[(i+1)%len(signals)]
 		}
 	}
+	return signals[0]
+}
 
-	clusterState = report.ClusterState
-
-	_, err = json.MarshalIndent(report, "", "  ")
-	if err != nil {
-		log.Fatalf("Failed to marshal JSON output: %v", err)
+// haTransition shows a newly raised signal right away instead of waiting for its turn in the blink cycle
+func haTransition(t Transition) {
+	if len(t.Added) > 0 {
+		haNextColorState = t.Added[0]
 	}
-
-	// fmt.Println(string(output))
 }
 
-// Pull Request Checks
-func ghPullRequestsCheck() {
+// haSetBulbColors turns the bulb on with the color attribute (rgb_color, rgbw_color or rgbww_color)
+func haSetBulbColors(ctx context.Context, entityId string, colorAttr string, color []int, brightness int, transition float64) {
 
 	// Ensure required environment variables are set otherwise skip
-	if /*token == "" ||*/ ghOwner == "" || ghRepo == "" {
+	if !haConfigured() || entityId == "" {
 		return
 	}
 
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls?state=open", ghOwner, ghRepo)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		HandleError("Error creating request:", err)
-		//os.Exit(1)
+	// Prepare payload
+	payload := map[string]interface{}{
+		"entity_id":  entityId,
+		colorAttr:    color,
+		"brightness": brightness,
+	}
+	if transition > 0 {
+		payload["transition"] = transition
+	}
+	haCallService(ctx, "light", "turn_on", payload)
+}
+
+// haTurnOffBulb switches the bulb off, e.g. between the blinks of a count burst
+func haTurnOffBulb(ctx context.Context) {
+	target := haLightTarget(ctx)
+	if !haConfigured() || target == "" {
 		return
 	}
+	haLastTarget = ""
+	haCallService(ctx, "light", "turn_off", map[string]interface{}{"entity_id": target})
+}
 
-	// Set Authorization header if token is provided (recommended)
-	if ghToken != "" {
-		req.Header.Set("Authorization", "token "+ghToken)
+// haCallService calls a Home Assistant service with the given payload and returns an error
+// when Home Assistant did not accept the call
+func haCallService(ctx context.Context, domain string, service string, payload map[string]interface{}) error {
+	if lightDriver != "homeassistant" {
+		if lightDriver == "recording" {
+			recordCommand("light", domain+"."+service, payload)
+		}
+		return nil
 	}
 
-	req.Header.Set("Accept", "application/vnd.github+json")
-	client := &http.Client{Timeout: 10 * time.Second}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Error marshaling payload: %v", err)
+		return err
+	}
 
-	resp, err := client.Do(req)
+	// Create POST request
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/api/services/%s/%s", haUrl, domain, service), bytes.NewBuffer(body))
 	if err != nil {
-		HandleError("Error sending request:", err)
-		//os.Exit(1)
-		return
+		log.Printf("Error creating request: %v", err)
+		return err
+	}
+
+	// Set headers
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", haToken.Get()))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Send request; bulb commands that don't get through are replayed once Home Assistant is back
+	resp, err := httpClient("homeassistant").Do(req)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrHAUnreachable, err)
+		HandleError("homeassistant", fmt.Sprintf("Error calling %s.%s:", domain, service), err)
+		haQueueCommand(domain, service, payload)
+		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		err := fmt.Errorf("%w: %s", ErrHAUnreachable, resp.Status)
+		HandleError("homeassistant", fmt.Sprintf("Error calling %s.%s:", domain, service), err)
+		haQueueCommand(domain, service, payload)
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		err := fmt.Errorf("service call returned status: %s", resp.Status)
+		HandleError("homeassistant", fmt.Sprintf("Error calling %s.%s:", domain, service), err)
+		return err
+	}
+	integrationOK("homeassistant")
+	haClearPending(payload)
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		//fmt.Printf("GitHub API returned status: %s\n", resp.Status)
-		HandleError("GitHub API returned status:", errors.New(resp.Status))
-		//os.Exit(1)
-		return
+// kubeRestConfig returns the in-cluster config, or the KUBECONFIG config when running out of cluster
+func kubeRestConfig() (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
 	}
+	return rest.InClusterConfig()
+}
 
-	var prs []PullRequest
-	if err := json.NewDecoder(resp.Body).Decode(&prs); err != nil {
-		HandleError("Error decoding response:", err)
-		//os.Exit(1)
-		return
+func clusterChecks(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	// In-cluster configuration
+	restConfig, err := kubeRestConfig()
+	if err != nil {
+		log.Fatalf("Failed to get cluster config: %v", err)
+		os.Exit(1)
 	}
 
-	if len(prs) == 0 {
-		//fmt.Println("No open pull requests found.")
-		ghPRState = "none"
-		return
+	restConfig.WarningHandler = deprecationWarningHandler{}
+
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		log.Fatalf("Failed to create clientset: %v", err)
+		os.Exit(1)
 	}
 
-	var issues []Issue
-	var latestPR Issue
-	for _, pr := range prs {
-		//fmt.Printf("PR #%d: %s by %s\n", pr.Number, pr.Title, pr.User.Login)
-		issues = append(issues, Issue{Key: fmt.Sprintf("pr/%d", pr.Number), Type: "PullRequest", Message: pr.Title, Timestamp: time.Now()})
-		latestPR = Issue{Key: fmt.Sprintf("pr/%d", pr.Number), Message: pr.Title}
-	}
-	//ghPRState = "none" // this line to be removed
-	if len(issues) > 0 {
-
-		// if current ghPRState is changing from none to open, send a ntfy message
-		if ghPRState == "none" {
-			ntfyOpts := NtfyOptions{
-				Title:    fmt.Sprintf("Pull Requests: %d", len(issues)),
-				Priority: 3, // (required)
-			}
-			err := SendNtfyAlert(fmt.Sprintf("Latest: #%s %s", latestPR.Key, latestPR.Message), ntfyOpts)
-			if err != nil {
-				log.Printf("Error sending ntfy alert: %v", err)
-			}
-		}
+	evaluateCluster(ctx, clientset)
+}
+
+// evaluateCluster runs the cluster checks against clientset, updates the cluster state and
+// returns the resulting report
+func evaluateCluster(ctx context.Context, clientset kubernetes.Interface) *HealthReport {
+	report := &HealthReport{
+		Timestamp: time.Now(),
+	}
 
-		ghPRState = "open"
+	// The Kubernetes API is only healthy once nodes, pods and events could all be listed
+	kube := &integrationCycle{integration: "kubernetes"}
+	nodeIssues := checkNodes(ctx, clientset, kube)
+	podIssues := checkPods(ctx, clientset, kube)
+	eventIssues := checkEvents(ctx, clientset, kube)
+	kube.done()
+	checkIssues := runCheckers(ctx, clientset)
+	// Pods the cluster can't scale up for are reported once, as a capacity issue of this cycle
+	podIssues, eventIssues = excludeCapacityPods(podIssues, eventIssues)
+	drainIssues, podIssues, eventIssues := collapseDrains(podIssues, eventIssues)
+	eventIssues = collapseEventStorm(eventIssues)
+	report.NodeIssues = nodeIssues
+	report.PodIssues = podIssues
+	report.EventIssues = eventIssues
+	report.PullRequests = pullRequests
+	report.OpenPRCount = prCount
+	report.SecurityAlerts = securityAlerts
+	report.Incidents = incidents
+	report.CheckIssues = append(checkIssues, drainIssues...)
+	report.CVESummary = vulnerabilitySummary
+	report.TopOffenders = topOffenders(podIssues)
+	report.TotalIssues = len(nodeIssues) + len(podIssues) + len(eventIssues) + len(report.CheckIssues)
+	activeIssueCount = report.TotalIssues
+	applyRunbooks(nodeIssues, podIssues, eventIssues, report.CheckIssues)
+	trackIssues(nodeIssues, podIssues, eventIssues, report.CheckIssues, report.PullRequests, report.SecurityAlerts, report.Incidents)
+	syncTickets(ctx, slices.Concat(nodeIssues, podIssues, eventIssues, report.CheckIssues))
+	pruneAckedIssues(slices.Concat(nodeIssues, podIssues, eventIssues, report.CheckIssues, report.PullRequests, report.SecurityAlerts, report.Incidents))
+	updateSignalIssues(unacked(slices.Concat(nodeIssues, podIssues)), unacked(eventIssues), unacked(report.CheckIssues))
+
+	// Compose the state from each active signal, in blink order; individually acknowledged issues are left out
+	inputs := StateInputs{
+		PRState:         ghPRState,
+		IgnoredPRs:      ghIgnoredPRState == "open",
+		BotPRs:          ghBotPRState == "open",
+		BlockedPRs:      ghBlockedPRState == "open",
+		CIFailing:       ciState == "failure",
+		SecurityAlerts:  ghSecurityState == "open",
+		ClusterIssues:   len(unacked(nodeIssues))+len(unacked(podIssues)) > 0,
+		WarningEvents:   len(unacked(eventIssues)) > 0,
+		WarningEventSig: warningEventsState,
+		CheckIssues:     unacked(report.CheckIssues),
+		LabeledIssues:   ghIssueState == "open",
+		LabeledIssueSig: ghIssueLabelState,
+		SLOBudgetLow:    sloBudgetLow,
 	}
-	pullRequests = issues
+	// With scoring, the generic issues only raise a signal once their weighted score reaches a threshold
+	if scoringEnabled() {
+		score := applyScoring(&inputs, unacked(slices.Concat(nodeIssues, podIssues, report.CheckIssues, pullRequests)), unacked(eventIssues))
+		report.Score = score
+		setGauge("clusterbulb_issue_score", "", float64(score))
+	}
+	applyClusterBoot(&inputs, detectClusterBoot(ctx, clientset))
+	state := composedState(composeState(inputs))
+	report.ClusterState = state.String()
+	report.CIState = ciState
+	report.Integrations = integrationsSnapshot()
+	report.GitHubQuota = ghQuota()
+	updateProfiles(unacked(nodeIssues), unacked(podIssues), unacked(eventIssues), unacked(report.CheckIssues), unacked(report.PullRequests))
+	report.Profiles = profileStates()
+	updateComponents(unacked(nodeIssues), unacked(podIssues), unacked(eventIssues), unacked(report.CheckIssues))
+	report.Components = componentHealth()
+	setLastReport(report)
+
+	setClusterState(state)
+	// Alerts held back during warm-up go out once it ended, if they still apply
+	sendWarmupAlerts()
+	updateNamespaceStates(slices.Concat(podIssues, report.CheckIssues), eventIssues)
+	updateStatusPage(ctx, report.Components, slices.Concat(nodeIssues, podIssues, eventIssues, report.CheckIssues))
+	updateDisplays(ctx)
+	recordIssues(slices.Concat(nodeIssues, podIssues, eventIssues, report.CheckIssues))
+	updateSLO()
+	pruneKnownIssues()
+	return report
 }
 
 // Node Checks
-func checkNodes(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
+func checkNodes(ctx context.Context, clientset kubernetes.Interface, cycle *integrationCycle) []Issue {
 	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
-		log.Printf("Error fetching nodes: %v", err)
+		cycle.fail("Error fetching nodes:", fmt.Errorf("%w: %v", ErrKubeAPI, err))
 		return nil
 	}
 
 	var issues []Issue
+	var readySince time.Time // oldest ready transition, zero while a node is not ready
+	cordonedNodes = map[string]bool{}
 	for _, node := range nodes.Items {
 		key := fmt.Sprintf("node/%s", node.Name)
+		if node.Spec.Unschedulable {
+			cordonedNodes[node.Name] = true
+		}
 		ready := false
 		for _, cond := range node.Status.Conditions {
 			if cond.Type == v1.NodeReady && cond.Status == v1.ConditionTrue {
 				ready = true
+				if readySince.IsZero() || cond.LastTransitionTime.Time.Before(readySince) {
+					readySince = cond.LastTransitionTime.Time
+				}
 				break
 			}
 		}
@@ -435,21 +1943,25 @@ func checkNodes(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
 		} else {
 			msg := fmt.Sprintf("Node %s is not ready", node.Name)
 			reportIssue(key) //, msg)
-			issues = append(issues, Issue{Key: key, Type: "Node", Message: msg, Timestamp: time.Now()})
+			issues = append(issues, Issue{Key: key, Type: "Node", Message: msg, Timestamp: time.Now(), Kind: "Node", Name: node.Name, Reason: "NotReady"})
 		}
 	}
+	if len(issues) == 0 {
+		noteClusterReady(readySince)
+	}
 	return issues
 }
 
 // Pod Checks
-func checkPods(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
+func checkPods(ctx context.Context, clientset kubernetes.Interface, cycle *integrationCycle) []Issue {
 	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		log.Printf("Error fetching pods: %v", err)
+		cycle.fail("Error fetching pods:", fmt.Errorf("%w: %v", ErrKubeAPI, err))
 		return nil
 	}
 
 	var issues []Issue
+	owners := &podOwners{clientset: clientset, replicaSets: map[string]string{}}
 	for _, pod := range pods.Items {
 		key := fmt.Sprintf("pod/%s/%s", pod.Namespace, pod.Name)
 
@@ -469,24 +1981,30 @@ func checkPods(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
 			} else {
 				msg := fmt.Sprintf("Pod %s/%s has containers not ready", pod.Namespace, pod.Name)
 				reportIssue(key) //, msg)
-				issues = append(issues, Issue{Key: key, Type: "Pod", Message: msg, Timestamp: time.Now()})
+				reason := "ContainersNotReady"
+				if pod.DeletionTimestamp != nil {
+					reason = "Terminating"
+				}
+				issues = append(issues, Issue{Key: key, Type: "Pod", Message: msg, Timestamp: time.Now(), Namespace: pod.Namespace, Kind: "Pod", Name: pod.Name, Reason: reason,
+					Owner: owners.owner(ctx, pod), Node: pod.Spec.NodeName, labels: pod.Labels})
 			}
 		default:
 			msg := fmt.Sprintf("Pod %s/%s in unexpected phase: %s", pod.Namespace, pod.Name, pod.Status.Phase)
 			reportIssue(key) //, msg)
-			issues = append(issues, Issue{Key: key, Type: "Pod", Message: msg, Timestamp: time.Now()})
+			issues = append(issues, Issue{Key: key, Type: "Pod", Message: msg, Timestamp: time.Now(), Namespace: pod.Namespace, Kind: "Pod", Name: pod.Name, Reason: string(pod.Status.Phase),
+				Owner: owners.owner(ctx, pod), Node: pod.Spec.NodeName, labels: pod.Labels})
 		}
 	}
 	return issues
 }
 
 // Event Checks
-func checkEvents(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
+func checkEvents(ctx context.Context, clientset kubernetes.Interface, cycle *integrationCycle) []Issue {
 	// filter events from the last interval
 	since := time.Now().Add(-10 * time.Second)
 	events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
 	if err != nil {
-		log.Printf("Error fetching events: %v", err)
+		cycle.fail("Error fetching events:", fmt.Errorf("%w: %v", ErrKubeAPI, err))
 		return nil
 	}
 
@@ -497,15 +2015,14 @@ func checkEvents(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
 		if e.Type != v1.EventTypeWarning {
 			continue
 		}
-		if e.LastTimestamp.Time.Before(since) {
+		if eventLastSeen(e).Before(since) {
 			continue
 		}
-
 		key := fmt.Sprintf("%s/%s:%s", e.Namespace, e.InvolvedObject.Name, e.Reason)
 		if last, ok := seen[key]; ok && time.Since(last) < 5*time.Minute {
 			continue
 		}
-		seen[key] = e.LastTimestamp.Time
+		seen[key] = eventLastSeen(e)
 
 		// Skip event if resource is healthy
 		if !isResourceUnhealthy(ctx, clientset, e) {
@@ -515,14 +2032,28 @@ func checkEvents(ctx context.Context, clientset *kubernetes.Clientset) []Issue {
 
 		msg := fmt.Sprintf("%s/%s: %s — %s", e.Namespace, e.InvolvedObject.Name, e.Reason, e.Message)
 		reportIssue(key) //, msg)
-		issues = append(issues, Issue{Key: key, Type: "Event", Message: msg, Timestamp: time.Now()})
+		issues = append(issues, Issue{Key: key, Type: "Event", Message: msg, Timestamp: time.Now(), Namespace: e.Namespace,
+			Kind: e.InvolvedObject.Kind, Name: e.InvolvedObject.Name, Reason: e.Reason, Count: int(e.Count), Node: e.Source.Host})
 	}
 
 	return issues
 }
 
+// eventLastSeen returns when an event last occurred. Events recorded through events.k8s.io/v1
+// leave LastTimestamp empty and set EventTime, and Series once they repeat.
+func eventLastSeen(e v1.Event) time.Time {
+	last := e.LastTimestamp.Time
+	if e.EventTime.Time.After(last) {
+		last = e.EventTime.Time
+	}
+	if e.Series != nil && e.Series.LastObservedTime.Time.After(last) {
+		last = e.Series.LastObservedTime.Time
+	}
+	return last
+}
+
 // Resource Health Helper
-func isResourceUnhealthy(ctx context.Context, clientset *kubernetes.Clientset, e v1.Event) bool {
+func isResourceUnhealthy(ctx context.Context, clientset kubernetes.Interface, e v1.Event) bool {
 	switch e.InvolvedObject.Kind {
 
 	// Check Pods
@@ -579,22 +2110,9 @@ func clearIssue(key string) {
 	//}
 }
 
-var ntfyUrl = ""                // os.Getenv("NTFY_URL")
-var ntfyTopic = ""              // os.Getenv("NTFY_TOPIC")
-var ntfyPriority = "high"       // os.Getenv("NTFY_PRIORITY") // low, default, high, urgent
-var ntfyTags = ""               // os.Getenv("NTFY_TAGS") // comma-separated list of tags
-var ntfyTitle = "Cluster Alert" // os.Getenv("NTFY_TITLE") // default: "Cluster Alert"
-
-var ntfyMessage = ""    // os.Getenv("NTFY_MESSAGE") // default: "An issue has been detected in the cluster."
-var ntfyEnabled = false // os.Getenv("NTFY_ENABLED") == "true"
-var ntfyMaxRetries = 3  // os.Getenv("NTFY_MAX_RETRIES") // default: 3
-var ntfyRetryDelay = 5  // os.Getenv("NTFY_RETRY_DELAY") // seconds, default: 5
-var ntfyLastSent time.Time
-var ntfyRateLimit = 60 // seconds, default: 60
-var ntfyErrorCount int
-var ntfyErrorLimit = 5  // default: 5
-var ntfyErrorDelay = 10 // seconds, default: 10
-var ntfyLastError time.Time
+var ntfyUrl = ""          // os.Getenv("NTFY_URL") // e.g. https://ntfy.sh, empty disables notifications
+var ntfyTopic = ""        // os.Getenv("NTFY_TOPIC") // e.g. clusterbulb
+var ntfyToken secretToken // os.Getenv("NTFY_TOKEN") // access token of a protected ntfy server
 
 type NtfyOptions struct {
 	Server   string
@@ -603,11 +2121,38 @@ type NtfyOptions struct {
 	Priority int    // 1–5 (ntfy standard)
 	Icon     string // URL or emoji
 	Tags     string // comma-separated tags (optional)
+	Click    string // URL opened when the notification is tapped (optional)
+	Markdown bool   // render the message as Markdown, e.g. for links (optional)
+
+	Attachment []byte // file sent as the body, the message then goes in a header (optional)
+	Filename   string // attachment file name (optional)
+
+	// Alerts held back during the warm-up window are sent when it ends, the last one per Key
+	// (default Title), unless Active reports that their condition cleared in the meantime
+	Key    string      // (optional)
+	Active func() bool // (optional)
 }
 
 func SendNtfyAlert(message string, opts NtfyOptions) error {
+	if warmingUp() && !isMuted() {
+		holdWarmupAlert(message, opts)
+		return nil
+	}
+	if notifySilenced(opts.Priority) {
+		return nil
+	}
+	if opts.Priority < 5 && notifyRateLimited("ntfy/"+ntfyKey(opts), ntfyInterval) {
+		log.Printf("Notification %q held back by NTFY_INTERVAL_MINUTES", opts.Title)
+		return nil
+	}
+	if notifyDriver != "ntfy" {
+		if notifyDriver == "recording" {
+			recordCommand("notify", "send", map[string]interface{}{"title": opts.Title, "priority": opts.Priority, "message": message, "click": opts.Click})
+		}
+		return nil
+	}
 	if opts.Server == "" {
-		opts.Server = os.Getenv("NTFY_URL") // "https://ntfy.sh"
+		opts.Server = ntfyUrl
 	}
 	if opts.Server == "" {
 		// server is still empty, so just return without doing anything
@@ -615,7 +2160,7 @@ func SendNtfyAlert(message string, opts NtfyOptions) error {
 	}
 
 	if opts.Topic == "" {
-		opts.Topic = os.Getenv("NTFY_TOPIC") // "clusterbulb"
+		opts.Topic = ntfyTopic
 	}
 	if opts.Topic == "" {
 		return fmt.Errorf("ntfy topic cannot be empty")
@@ -626,14 +2171,25 @@ func SendNtfyAlert(message string, opts NtfyOptions) error {
 
 	url := fmt.Sprintf("%s/%s", opts.Server, opts.Topic)
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer([]byte(message)))
+	// Critical notifications can carry the health report, inline or as an attachment
+	message = ntfyWithReport(message, &opts)
+	method, body := "POST", []byte(message)
+	if opts.Attachment != nil {
+		method, body = "PUT", opts.Attachment
+	}
+
+	req, err := http.NewRequestWithContext(appCtx, method, url, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	if opts.Attachment != nil {
+		req.Header.Set("Filename", opts.Filename)
+		req.Header.Set("Message", ntfyHeaderMessage(message))
+	}
 
 	// Add headers
 	if opts.Title != "" {
-		req.Header.Set("Title", opts.Title)
+		req.Header.Set("Title", ntfyHeader(opts.Title))
 	}
 	req.Header.Set("Priority", fmt.Sprintf("%d", opts.Priority))
 
@@ -643,9 +2199,18 @@ func SendNtfyAlert(message string, opts NtfyOptions) error {
 	if opts.Tags != "" {
 		req.Header.Set("Tags", opts.Tags)
 	}
+	if opts.Click != "" {
+		req.Header.Set("Click", opts.Click)
+	}
+	if opts.Markdown {
+		req.Header.Set("Markdown", "yes")
+	}
+	if ntfyToken.Get() != "" {
+		req.Header.Set("Authorization", "Bearer "+ntfyToken.Get())
+	}
 
 	// Send request
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpClient("ntfy").Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send ntfy request: %w", err)
 	}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/golden")
+
+// TestGoldenReports evaluates the fixtures of every case in testdata/golden. A case is a directory
+// of numbered cluster snapshots (1.yaml, 2.yaml, ...) evaluated in order; each snapshot's report is
+// compared to N.report.json and the transitions of the whole case to transitions.txt.
+func TestGoldenReports(t *testing.T) {
+	cases, err := filepath.Glob("testdata/golden/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("no golden cases found")
+	}
+
+	for _, dir := range cases {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			resetClusterGlobals(t)
+
+			var transitions []string
+			onTransition(func(tr Transition) {
+				line := fmt.Sprintf("%s -> %s", tr.From, tr.To)
+				for _, s := range tr.Added {
+					line += " +" + string(s)
+				}
+				for _, s := range tr.Removed {
+					line += " -" + string(s)
+				}
+				transitions = append(transitions, line)
+			})
+
+			snapshots, err := filepath.Glob(filepath.Join(dir, "[0-9]*.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, snapshot := range snapshots {
+				objects := loadFixtures(t, snapshot)
+				report := evaluateCluster(context.Background(), fake.NewSimpleClientset(objects...))
+
+				got, err := json.MarshalIndent(normalizeReport(report), "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertGolden(t, strings.TrimSuffix(snapshot, ".yaml")+".report.json", append(got, '\n'))
+			}
+			assertGolden(t, filepath.Join(dir, "transitions.txt"), []byte(strings.Join(transitions, "\n")+"\n"))
+		})
+	}
+}
+
+// resetClusterGlobals clears the state a previous case or test left behind and restores it
+// once the test is done
+func resetClusterGlobals(t *testing.T) {
+	t.Helper()
+	savedState, savedKnown, savedSightings := clusterState, knownIssues, issueSightings
+	savedPRState, savedPRs, savedCheckers := ghPRState, pullRequests, checkers
+	savedCordoned, savedHistory, savedListeners := cordonedNodes, history, transitionListeners
+	t.Cleanup(func() {
+		clusterState, knownIssues, issueSightings = savedState, savedKnown, savedSightings
+		ghPRState, pullRequests, checkers = savedPRState, savedPRs, savedCheckers
+		cordonedNodes, history, transitionListeners = savedCordoned, savedHistory, savedListeners
+	})
+
+	clusterState = nil
+	knownIssues = make(map[string]time.Time)
+	issueSightings = map[string]*issueSighting{}
+	ghPRState = "none"
+	pullRequests = nil
+	checkers = nil
+	cordonedNodes = map[string]bool{}
+	history = issueHistory{Since: time.Now(), Open: map[string]*IssueRecord{}}
+	transitionListeners = nil
+}
+
+// loadFixtures decodes a multi-document YAML file of Kubernetes objects. Warning events without
+// a lastTimestamp are stamped with the current time so they fall into the checked window.
+func loadFixtures(t *testing.T, path string) []runtime.Object {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var objects []runtime.Object
+	for _, doc := range regexp.MustCompile(`(?m)^---\s*$`).Split(string(data), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode([]byte(doc), nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		if e, ok := obj.(*v1.Event); ok && e.LastTimestamp.IsZero() {
+			e.LastTimestamp = metav1.Now()
+		}
+		objects = append(objects, obj)
+	}
+	return objects
+}
+
+// normalizeReport clears the timestamps and integration health so reports are comparable between runs
+func normalizeReport(report *HealthReport) *HealthReport {
+	report.Timestamp = time.Time{}
+	report.Integrations = nil
+	for _, issues := range [][]Issue{report.NodeIssues, report.PodIssues, report.EventIssues, report.CheckIssues} {
+		for i := range issues {
+			issues[i].Timestamp = time.Time{}
+			issues[i].FirstSeen, issues[i].LastSeen = time.Time{}, time.Time{}
+		}
+	}
+	return report
+}
+
+// assertGolden compares got with the golden file, or rewrites it with -update
+func assertGolden(t *testing.T, path string, got []byte) {
+	t.Helper()
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s mismatch\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var gpuNodeSelector = "nvidia.com/gpu.present=true" // os.Getenv("GPU_NODE_SELECTOR") // label selector of nodes expected to have GPUs
+var gpuExpectedCount = 0                            // os.Getenv("GPU_EXPECTED_COUNT") // GPUs each selected node should advertise, 0 means at least one
+
+// checkGPUs verifies, when the NVIDIA device plugin is installed, that its DaemonSet is healthy
+// and that every GPU node still advertises nvidia.com/gpu capacity
+func checkGPUs(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	daemonSets, err := clientset.AppsV1().DaemonSets("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching daemonsets: %v", err)
+		return nil
+	}
+
+	var issues []Issue
+	found := false
+	for _, ds := range daemonSets.Items {
+		if !strings.Contains(ds.Name, "nvidia-device-plugin") {
+			continue
+		}
+		found = true
+		if ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+			key := fmt.Sprintf("gpu/daemonset/%s/%s", ds.Namespace, ds.Name)
+			message := fmt.Sprintf("NVIDIA device plugin %s/%s has %d/%d pods ready", ds.Namespace, ds.Name, ds.Status.NumberReady, ds.Status.DesiredNumberScheduled)
+			issues = append(issues, Issue{Key: key, Type: "GPU", Severity: "warning", Message: message, Timestamp: time.Now(),
+				Namespace: ds.Namespace, Kind: "DaemonSet", Name: ds.Name, Owner: "daemonset/" + ds.Name})
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: gpuNodeSelector})
+	if err != nil {
+		log.Printf("Error fetching GPU nodes: %v", err)
+		return issues
+	}
+	for _, node := range nodes.Items {
+		capacity := node.Status.Capacity[v1.ResourceName("nvidia.com/gpu")]
+		gpus := int(capacity.Value())
+		if gpus == 0 || gpus < gpuExpectedCount {
+			message := fmt.Sprintf("GPU node %s advertises %d nvidia.com/gpu", node.Name, gpus)
+			if gpuExpectedCount > 0 {
+				message += fmt.Sprintf(", expected %d", gpuExpectedCount)
+			}
+			issues = append(issues, Issue{Key: "gpu/node/" + node.Name, Type: "GPU", Severity: "critical", Message: message, Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// issueGroup is the share of the cluster issues a profile or component is made of: the issues of
+// its namespaces, labelled pods and workloads, composed into its own state that can drive a light
+type issueGroup struct {
+	Name       string   `yaml:"name"`
+	Namespaces []string `yaml:"namespaces"` // namespace patterns, e.g. media-*
+	Selector   string   `yaml:"selector"`   // label selector of the pods, e.g. app.kubernetes.io/part-of=media
+	Workloads  []string `yaml:"workloads"`  // owner patterns, e.g. deployment/jellyfin
+	Nodes      bool     `yaml:"nodes"`      // node issues count as well
+	Light      string   `yaml:"light"`      // Home Assistant light entity, optional
+
+	selector labels.Selector
+	state    ClusterState
+	last     Signal
+}
+
+// validate checks the name, patterns, selector and light of item i of a group kind, e.g. profile
+func (g *issueGroup) validate(problems *configProblems, i int, kind string, seen map[string]bool, patterns ...string) {
+	if g.Name == "" || seen[g.Name] {
+		problems.add(i, "%s names must be set and unique, got %q", kind, g.Name)
+	}
+	seen[g.Name] = true
+	for _, pattern := range slices.Concat(g.Namespaces, g.Workloads, patterns) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			problems.add(i, "%s %q: invalid pattern %q", kind, g.Name, pattern)
+		}
+	}
+	if g.Selector != "" {
+		selector, err := labels.Parse(g.Selector)
+		if err != nil {
+			problems.add(i, "%s %q: invalid selector: %v", kind, g.Name, err)
+		} else {
+			g.selector = selector
+		}
+	}
+	if g.Light != "" && !strings.HasPrefix(g.Light, "light.") {
+		problems.add(i, "%s %q: light must be a light entity, got %q", kind, g.Name, g.Light)
+	}
+}
+
+// matches reports whether the issue belongs to the group
+func (g *issueGroup) matches(issue Issue) bool {
+	return (g.Nodes && issue.Type == "Node") ||
+		(issue.Namespace != "" && matchesAny(g.Namespaces, issue.Namespace)) ||
+		(issue.Owner != "" && matchesAny(g.Workloads, issue.Owner)) ||
+		(g.selector != nil && issue.labels != nil && g.selector.Matches(labels.Set(issue.labels)))
+}
+
+// own returns the issues that belong to the group
+func (g *issueGroup) own(issues []Issue) []Issue {
+	return slices.DeleteFunc(slices.Clone(issues), func(issue Issue) bool { return !g.matches(issue) })
+}
+
+// inputs returns the state inputs of the group's share of the cluster issues
+func (g *issueGroup) inputs(nodeIssues, podIssues, eventIssues, checkIssues []Issue) StateInputs {
+	return StateInputs{
+		PRState:         "none",
+		ClusterIssues:   slices.ContainsFunc(podIssues, g.matches) || slices.ContainsFunc(nodeIssues, g.matches),
+		WarningEvents:   slices.ContainsFunc(eventIssues, g.matches),
+		WarningEventSig: warningEventsState,
+		CheckIssues:     g.own(checkIssues),
+	}
+}
+
+// showLight advances the blink cycle of the group's light
+func (g *issueGroup) showLight(ctx context.Context) {
+	if g.Light == "" {
+		return
+	}
+	next := nextBlinkSignal(g.state, g.last)
+	color, ok := stateColor(next)
+	if !ok {
+		return
+	}
+	g.last = next
+	haSetBulbColors(ctx, g.Light, "rgb_color", color[:], haLightBrightness, haLightTransition)
+}
+
+// haUpdateGroupLights advances the blink cycle of every profile and component light
+func haUpdateGroupLights(ctx context.Context) {
+	for i := range config.Profiles {
+		config.Profiles[i].showLight(ctx)
+	}
+	for i := range config.Components {
+		config.Components[i].showLight(ctx)
+	}
+}
+
+// matchesAny reports whether value matches one of the patterns
+func matchesAny(patterns []string, value string) bool {
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		ok, _ := path.Match(pattern, value)
+		return ok
+	})
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	clusterbulbv1 "go-clusterchecks/proto/clusterbulb/v1"
+)
+
+var grpcListenAddr = "" // os.Getenv("GRPC_LISTEN_ADDR") // e.g. :9090, empty disables the gRPC API
+var apiToken = ""       // os.Getenv("API_TOKEN") // bearer token required by the gRPC API and /api/v1 when set
+
+// StateChange is one transition of the cluster state; the first message of a stream carries
+// the current state with From equal to To
+type StateChange struct {
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Added   []string  `json:"added,omitempty"`
+	Removed []string  `json:"removed,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// stateSubscribers receive every transition while they stream state changes
+var stateSubscribers = map[chan StateChange]bool{}
+var stateSubscribersMu sync.Mutex
+var currentStateChange = StateChange{From: string(SignalHealthy), To: string(SignalHealthy)}
+
+// stateChange converts a transition to the state change sent to the clients
+func stateChange(t Transition) StateChange {
+	change := StateChange{From: t.From.String(), To: t.To.String(), At: t.At}
+	for _, s := range t.Added {
+		change.Added = append(change.Added, string(s))
+	}
+	for _, s := range t.Removed {
+		change.Removed = append(change.Removed, string(s))
+	}
+	return change
+}
+
+// grpcTransition fans transitions out to the streaming clients, dropping them for slow clients
+func grpcTransition(t Transition) {
+	change := stateChange(t)
+
+	stateSubscribersMu.Lock()
+	defer stateSubscribersMu.Unlock()
+	currentStateChange = StateChange{From: change.To, To: change.To, At: change.At}
+	for ch := range stateSubscribers {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// subscribeStateChanges returns a channel receiving transitions, primed with the current state
+func subscribeStateChanges() (chan StateChange, func()) {
+	ch := make(chan StateChange, 16)
+	stateSubscribersMu.Lock()
+	defer stateSubscribersMu.Unlock()
+	ch <- currentStateChange
+	stateSubscribers[ch] = true
+	return ch, func() {
+		stateSubscribersMu.Lock()
+		defer stateSubscribersMu.Unlock()
+		delete(stateSubscribers, ch)
+	}
+}
+
+// statusServer implements the clusterbulb.v1.Status service of proto/clusterbulb/v1/status.proto
+type statusServer struct {
+	clusterbulbv1.UnimplementedStatusServer
+}
+
+func (statusServer) GetReport(context.Context, *clusterbulbv1.Empty) (*clusterbulbv1.Report, error) {
+	report := latestReport()
+	if report == nil {
+		return nil, status.Error(codes.Unavailable, "no health report yet")
+	}
+	return protoReport(report), nil
+}
+
+func (statusServer) StreamStateChanges(_ *clusterbulbv1.Empty, stream grpc.ServerStreamingServer[clusterbulbv1.StateChange]) error {
+	ch, unsubscribe := subscribeStateChanges()
+	defer unsubscribe()
+	for {
+		select {
+		case change := <-ch:
+			if err := stream.Send(protoStateChange(change)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// The actions are queued like the Stream Deck keys do
+func (statusServer) Acknowledge(context.Context, *clusterbulbv1.Empty) (*clusterbulbv1.Empty, error) {
+	return grpcAction("ack")
+}
+
+func (statusServer) Snooze(context.Context, *clusterbulbv1.Empty) (*clusterbulbv1.Empty, error) {
+	return grpcAction("snooze")
+}
+
+func (statusServer) TriggerCheck(context.Context, *clusterbulbv1.Empty) (*clusterbulbv1.Empty, error) {
+	return grpcAction("recheck")
+}
+
+// grpcAction queues a user action, failing with ResourceExhausted when it was dropped
+func grpcAction(action string) (*clusterbulbv1.Empty, error) {
+	if !requestUserAction(action) {
+		return nil, status.Error(codes.ResourceExhausted, "too many pending actions")
+	}
+	return &clusterbulbv1.Empty{}, nil
+}
+
+// protoTime converts a time, leaving zero times unset
+func protoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// protoIssues converts issues to their gRPC messages
+func protoIssues(issues []Issue) []*clusterbulbv1.Issue {
+	var out []*clusterbulbv1.Issue
+	for _, i := range issues {
+		out = append(out, &clusterbulbv1.Issue{
+			Key: i.Key, Type: i.Type, Severity: i.Severity, Message: i.Message, Timestamp: protoTime(i.Timestamp),
+			Namespace: i.Namespace, Kind: i.Kind, Name: i.Name, Owner: i.Owner, Node: i.Node, Reason: i.Reason,
+			FirstSeen: protoTime(i.FirstSeen), LastSeen: protoTime(i.LastSeen), Count: int32(i.Count), Runbook: i.Runbook,
+		})
+	}
+	return out
+}
+
+// protoReport converts a health report to its gRPC message
+func protoReport(r *HealthReport) *clusterbulbv1.Report {
+	report := &clusterbulbv1.Report{
+		Timestamp:      protoTime(r.Timestamp),
+		ClusterState:   r.ClusterState,
+		CiState:        r.CIState,
+		TotalIssues:    int32(r.TotalIssues),
+		Score:          int32(r.Score),
+		OpenPrCount:    int32(r.OpenPRCount),
+		NodeIssues:     protoIssues(r.NodeIssues),
+		PodIssues:      protoIssues(r.PodIssues),
+		EventIssues:    protoIssues(r.EventIssues),
+		CheckIssues:    protoIssues(r.CheckIssues),
+		PullRequests:   protoIssues(r.PullRequests),
+		SecurityAlerts: protoIssues(r.SecurityAlerts),
+		Incidents:      protoIssues(r.Incidents),
+		CveSummary:     r.CVESummary,
+		Profiles:       r.Profiles,
+	}
+	for name, h := range r.Integrations {
+		if report.Integrations == nil {
+			report.Integrations = map[string]*clusterbulbv1.IntegrationHealth{}
+		}
+		report.Integrations[name] = &clusterbulbv1.IntegrationHealth{
+			ConsecutiveFailures: int32(h.Failures), Degraded: h.Degraded, LastError: h.LastError,
+			LastFailure: protoTime(h.LastFailure), LastSuccess: protoTime(h.LastSuccess), RetryAfter: protoTime(h.RetryAfter),
+		}
+	}
+	for _, c := range r.Components {
+		report.Components = append(report.Components, &clusterbulbv1.ComponentHealth{Name: c.Name, State: c.State, Status: c.Status})
+	}
+	return report
+}
+
+// protoStateChange converts a state change to its gRPC message
+func protoStateChange(c StateChange) *clusterbulbv1.StateChange {
+	return &clusterbulbv1.StateChange{From: c.From, To: c.To, Added: c.Added, Removed: c.Removed, At: protoTime(c.At)}
+}
+
+// grpcAuthorized checks the bearer token in the call metadata against API_TOKEN
+func grpcAuthorized(ctx context.Context) error {
+	if apiToken == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if tokenValid(value) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid API token")
+}
+
+// newGRPCServer returns a server with the status service and token authentication
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := grpcAuthorized(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := grpcAuthorized(ss.Context()); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		}),
+	)
+	clusterbulbv1.RegisterStatusServer(server, statusServer{})
+	return server
+}
+
+// startGRPCServer serves the gRPC API in the background until ctx is cancelled
+func startGRPCServer(ctx context.Context) {
+	if grpcListenAddr == "" {
+		return
+	}
+	lis, err := net.Listen("tcp", grpcListenAddr)
+	if err != nil {
+		log.Fatalf("gRPC server failed: %v", err)
+	}
+	server := newGRPCServer()
+	go func() {
+		log.Printf("gRPC server listening on %s", grpcListenAddr)
+		if err := server.Serve(lis); err != nil {
+			log.Fatalf("gRPC server failed: %v", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+}
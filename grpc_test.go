@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	clusterbulbv1 "go-clusterchecks/proto/clusterbulb/v1"
+)
+
+func TestGRPCStatusService(t *testing.T) {
+	apiToken = "secret"
+	clusterState = nil
+	setLastReport(nil)
+	defer func() {
+		apiToken = ""
+		setLastReport(nil)
+	}()
+
+	lis := bufconn.Listen(1 << 20)
+	server := newGRPCServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := clusterbulbv1.NewStatusClient(conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err = client.GetReport(ctx, &clusterbulbv1.Empty{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("GetReport without token: got %v, want Unauthenticated", err)
+	}
+	wrong := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secreT")
+	if _, err = client.GetReport(wrong, &clusterbulbv1.Empty{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("GetReport with a wrong token: got %v, want Unauthenticated", err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+	_, err = client.GetReport(ctx, &clusterbulbv1.Empty{})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("GetReport before the first check: got %v, want Unavailable", err)
+	}
+	setLastReport(&HealthReport{
+		ClusterState: "issues_detected",
+		TotalIssues:  2,
+		PodIssues:    []Issue{{Key: "pod/prod/api", Type: "Pod", Namespace: "prod", Owner: "deployment/api", Timestamp: time.Now()}},
+		Integrations: map[string]IntegrationHealth{"github": {Failures: 3, Degraded: true}},
+	})
+	report, err := client.GetReport(ctx, &clusterbulbv1.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ClusterState != "issues_detected" || report.TotalIssues != 2 {
+		t.Errorf("GetReport = %+v", report)
+	}
+	if len(report.PodIssues) != 1 || report.PodIssues[0].Owner != "deployment/api" || report.PodIssues[0].Timestamp == nil {
+		t.Errorf("pod issues = %v", report.PodIssues)
+	}
+	if h := report.Integrations["github"]; h == nil || !h.Degraded || h.ConsecutiveFailures != 3 {
+		t.Errorf("integrations = %v", report.Integrations)
+	}
+
+	// The stream starts with the current state, then carries every transition
+	stream, err := client.StreamStateChanges(ctx, &clusterbulbv1.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	change, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.To != "healthy" {
+		t.Errorf("first state change = %v, want the current state", change)
+	}
+
+	grpcTransition(Transition{From: nil, To: ClusterState{SignalCIFailing}, Added: []Signal{SignalCIFailing}, Removed: []Signal{SignalHealthy}})
+	if change, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if change.From != "healthy" || change.To != "ci_failing" || len(change.Added) != 1 {
+		t.Errorf("state change = %v", change)
+	}
+}
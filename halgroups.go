@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var haLightArea = "" // os.Getenv("HA_LIGHT_AREA") // area whose lights show the state together, instead of HA_LIGHT_ENTITY_ID
+
+// haLightMembers are the lights of HA_LIGHT_AREA or of a group.* HA_LIGHT_ENTITY_ID, resolved
+// through the API so no group has to be maintained in Home Assistant just for the bulb
+var haLightMembers []string
+var haLightMembersCheckedAt time.Time
+
+// haLightGrouped reports whether the bulb is an area or an old-style group that needs resolving;
+// light groups are commanded as they are, Home Assistant keeps their members in sync
+func haLightGrouped() bool {
+	return haLightArea != "" || strings.HasPrefix(haLightEntityId, "group.")
+}
+
+// haLightTarget returns the entity IDs the bulb commands go to, resolving the members of an area
+// or group once and retrying every minute while that fails
+func haLightTarget(ctx context.Context) string {
+	if !haLightGrouped() {
+		return haLightEntityId
+	}
+	// The test drivers have no instance to resolve against
+	if lightDriver != "homeassistant" {
+		if haLightArea != "" {
+			return "area." + haLightArea
+		}
+		return haLightEntityId
+	}
+	if haLightMembers == nil && time.Since(haLightMembersCheckedAt) >= time.Minute {
+		haLightMembersCheckedAt = time.Now()
+		members, err := haResolveLights(ctx)
+		if err != nil {
+			HandleError("homeassistant", "Error resolving the bulb lights:", err)
+			return ""
+		}
+		log.Printf("Bulb lights: %s", strings.Join(members, ", "))
+		haLightMembers = members
+	}
+	return strings.Join(haLightMembers, ", ")
+}
+
+// haResolveLights returns the lights of HA_LIGHT_AREA or the members of the group.* HA_LIGHT_ENTITY_ID
+func haResolveLights(ctx context.Context) ([]string, error) {
+	var members []string
+	if haLightArea != "" {
+		out, err := haRenderTemplate(ctx, fmt.Sprintf(`{{ area_entities(%q) | select('match', 'light\.') | list | tojson }}`, haLightArea))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(out), &members); err != nil {
+			return nil, fmt.Errorf("failed to decode area lights: %w", err)
+		}
+		if len(members) == 0 {
+			return nil, fmt.Errorf("area %s has no lights", haLightArea)
+		}
+		return members, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/states/%s", haUrl, haLightEntityId), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+haToken.Get())
+	var group struct {
+		Attributes struct {
+			EntityID []string `json:"entity_id"`
+		} `json:"attributes"`
+	}
+	if err := httpGetJSON("homeassistant", req, &group); err != nil {
+		return nil, err
+	}
+	for _, entity := range group.Attributes.EntityID {
+		if strings.HasPrefix(entity, "light.") {
+			members = append(members, entity)
+		}
+	}
+	if len(members) == 0 {
+		return nil, fmt.Errorf("group %s has no lights", haLightEntityId)
+	}
+	return members, nil
+}
+
+// haRenderTemplate renders a template with the Home Assistant template API
+func haRenderTemplate(ctx context.Context, template string) (string, error) {
+	body, _ := json.Marshal(map[string]string{"template": template})
+	req, err := http.NewRequestWithContext(ctx, "POST", haUrl+"/api/template", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+haToken.Get())
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient("homeassistant").Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Home Assistant returned status: %s", resp.Status)
+	}
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	return string(out), nil
+}
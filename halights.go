@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// haLight is a light entity as returned by the Home Assistant states API
+type haLight struct {
+	EntityID   string `json:"entity_id"`
+	State      string `json:"state"`
+	Attributes struct {
+		FriendlyName string `json:"friendly_name"`
+	} `json:"attributes"`
+}
+
+// haListLights returns the light entities of the Home Assistant instance, sorted by entity ID
+func haListLights(ctx context.Context) ([]haLight, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", haUrl+"/api/states", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+haToken.Get())
+	var states []haLight
+	if err := httpGetJSON("homeassistant", req, &states); err != nil {
+		return nil, err
+	}
+	lights := slices.DeleteFunc(states, func(l haLight) bool { return !strings.HasPrefix(l.EntityID, "light.") })
+	sort.Slice(lights, func(i, j int) bool { return lights[i].EntityID < lights[j].EntityID })
+	return lights, nil
+}
+
+// validateHALights checks that HA_LIGHT_ENTITY_ID (or the lights of HA_LIGHT_AREA or a group) and
+// the namespace lights exist, so a typo fails at startup with the available lights instead of
+// silently calling a missing entity forever. An unreachable Home Assistant is not an error, it
+// may still be starting.
+func validateHALights(ctx context.Context) error {
+	if lightDriver != "homeassistant" || !haConfigured() {
+		return nil
+	}
+	lights, err := haListLights(ctx)
+	if err != nil {
+		return nil
+	}
+
+	wanted := []string{haLightEntityId}
+	if haLightGrouped() {
+		members, err := haResolveLights(ctx)
+		if err != nil {
+			return err
+		}
+		haLightMembers, wanted = members, members
+	}
+	for _, nl := range namespaceLights {
+		wanted = append(wanted, nl.entityId)
+	}
+	for _, p := range config.Profiles {
+		wanted = append(wanted, p.Light)
+	}
+	for _, c := range config.Components {
+		wanted = append(wanted, c.Light)
+	}
+	for _, entity := range wanted {
+		if entity == "" || slices.ContainsFunc(lights, func(l haLight) bool { return l.EntityID == entity }) {
+			continue
+		}
+		var available []string
+		for _, l := range lights {
+			available = append(available, l.EntityID)
+		}
+		if len(available) == 0 {
+			return fmt.Errorf("light %s not found, Home Assistant has no light entities", entity)
+		}
+		return fmt.Errorf("light %s not found, available lights: %s", entity, strings.Join(available, ", "))
+	}
+	return nil
+}
+
+// cliLights lists the Home Assistant lights of HA_URL, marking HA_LIGHT_ENTITY_ID, to pick the
+// entity to configure
+func cliLights(c *cliClient, _ []string) error {
+	haUrl = strings.TrimRight(os.Getenv("HA_URL"), "/")
+	haToken.Set(os.Getenv("HA_TOKEN"))
+	if haUrl == "" || haToken.Get() == "" {
+		return fmt.Errorf("HA_URL and HA_TOKEN must be set")
+	}
+	lights, err := haListLights(context.Background())
+	if err != nil {
+		return err
+	}
+	if len(lights) == 0 {
+		fmt.Fprintln(c.out, "No light entities found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ENTITY\tNAME\tSTATE")
+	for _, l := range lights {
+		mark := " "
+		if l.EntityID == os.Getenv("HA_LIGHT_ENTITY_ID") {
+			mark = "*"
+		}
+		fmt.Fprintf(w, "%s %s\t%s\t%s\n", mark, l.EntityID, orDash(l.Attributes.FriendlyName), l.State)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+// haPendingCommand is the latest command for an entity that didn't reach Home Assistant
+type haPendingCommand struct {
+	domain   string
+	service  string
+	payload  map[string]interface{}
+	queuedAt time.Time // when the entity first fell behind
+}
+
+// haQueuedDomains are the domains of bulb commands, which are replayed once Home Assistant is
+// reachable again; a late sound or notification is worse than none
+var haQueuedDomains = []string{"light", "scene", "script"}
+
+// haPending holds at most one command per entity, the latest desired one
+var haPending = map[string]*haPendingCommand{}
+var haPendingMu sync.Mutex
+
+// haQueueCommand keeps the command to replay it, replacing an older one for the same entity
+func haQueueCommand(domain, service string, payload map[string]interface{}) {
+	if !slices.Contains(haQueuedDomains, domain) {
+		return
+	}
+	entity := fmt.Sprint(payload["entity_id"])
+	haPendingMu.Lock()
+	defer haPendingMu.Unlock()
+	queuedAt := time.Now()
+	if p, ok := haPending[entity]; ok {
+		queuedAt = p.queuedAt
+	}
+	haPending[entity] = &haPendingCommand{domain: domain, service: service, payload: payload, queuedAt: queuedAt}
+	haPendingMetrics()
+}
+
+// haClearPending drops the queued command of the entity once a newer one got through
+func haClearPending(payload map[string]interface{}) {
+	entity := fmt.Sprint(payload["entity_id"])
+	haPendingMu.Lock()
+	defer haPendingMu.Unlock()
+	if _, ok := haPending[entity]; ok {
+		delete(haPending, entity)
+		haPendingMetrics()
+	}
+}
+
+// haReplayPending resends the queued commands, oldest first, stopping at the first one that
+// fails as Home Assistant is then still unreachable. Commands superseded by a newer one for the
+// same entity that got through in the meantime are not replayed.
+func haReplayPending(ctx context.Context) {
+	haPendingMu.Lock()
+	haPendingMetrics()
+	var pending []haPendingCommand
+	for _, p := range haPending {
+		pending = append(pending, *p)
+	}
+	haPendingMu.Unlock()
+	slices.SortFunc(pending, func(a, b haPendingCommand) int { return a.queuedAt.Compare(b.queuedAt) })
+
+	for _, p := range pending {
+		if !haStillPending(p) {
+			continue
+		}
+		err := haCallService(ctx, p.domain, p.service, p.payload)
+		if errors.Is(err, ErrHAUnreachable) {
+			return
+		}
+		if err != nil {
+			// Rejected by Home Assistant, replaying it again won't help
+			haClearPending(p.payload)
+			continue
+		}
+		log.Printf("Replayed %s.%s for %v, queued %s ago", p.domain, p.service, p.payload["entity_id"], time.Since(p.queuedAt).Round(time.Second))
+		addCounter("clusterbulb_ha_commands_replayed_total", "", 1)
+	}
+}
+
+// haStillPending reports whether the command is still the queued one of its entity
+func haStillPending(p haPendingCommand) bool {
+	haPendingMu.Lock()
+	defer haPendingMu.Unlock()
+	queued, ok := haPending[fmt.Sprint(p.payload["entity_id"])]
+	return ok && queued.service == p.service && fmt.Sprint(queued.payload) == fmt.Sprint(p.payload)
+}
+
+// haPendingMetrics exports the queue length and the age of the oldest queued command; callers hold haPendingMu
+func haPendingMetrics() {
+	var oldest time.Time
+	for _, p := range haPending {
+		if oldest.IsZero() || p.queuedAt.Before(oldest) {
+			oldest = p.queuedAt
+		}
+	}
+	age := 0.0
+	if !oldest.IsZero() {
+		age = time.Since(oldest).Seconds()
+	}
+	setGauge("clusterbulb_ha_pending_commands", "", float64(len(haPending)))
+	setGauge("clusterbulb_ha_pending_command_age_seconds", "", age)
+}
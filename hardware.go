@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var nodeTempThreshold = 0.0 // os.Getenv("NODE_TEMP_THRESHOLD") // °C, enables the node temperature check
+var smartCheck = false      // os.Getenv("SMART_CHECK") == "true" // warn about disks failing their SMART self-assessment
+
+// Hardware queries against the node-exporter and smartctl_exporter metrics in Prometheus
+const (
+	nodeTempQuery = `max by (instance) (node_hwmon_temp_celsius or node_thermal_zone_temp)`
+	// smartctl_exporter reports 0 for a failed self-assessment, the node-exporter smartmon textfile collector 0 for unhealthy
+	smartFailedQuery = `smartctl_device_smart_status == 0 or smartmon_device_smart_healthy == 0`
+)
+
+// checkHardware warns about nodes running hotter than NODE_TEMP_THRESHOLD and disks failing SMART,
+// which on bare metal tend to precede a node going NotReady
+func checkHardware(ctx context.Context, _ kubernetes.Interface) []Issue {
+	if !integrationReady("prometheus") {
+		return nil
+	}
+
+	cycle := &integrationCycle{integration: "prometheus"}
+	defer cycle.done()
+	var issues []Issue
+	if nodeTempThreshold > 0 {
+		samples, err := promQuery(ctx, nodeTempQuery)
+		if err != nil {
+			cycle.fail("Error querying node temperatures:", err)
+			return nil
+		}
+		for _, s := range samples {
+			value, ok := s.Value[1].(string)
+			temp, err := strconv.ParseFloat(value, 64)
+			if !ok || err != nil || temp <= nodeTempThreshold {
+				continue
+			}
+			instance := s.Metric["instance"]
+			issues = append(issues, Issue{
+				Key:       "hardware/" + instance + "/temperature",
+				Type:      "NodeTemperature",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("Node %s is running hot: %.0f°C (threshold %.0f°C)", instance, temp, nodeTempThreshold),
+				Timestamp: time.Now(),
+				Kind:      "Node",
+				Name:      instance,
+				Reason:    "Overheating",
+			})
+		}
+	}
+
+	if smartCheck {
+		samples, err := promQuery(ctx, smartFailedQuery)
+		if err != nil {
+			cycle.fail("Error querying SMART status:", err)
+			return issues
+		}
+		for _, s := range samples {
+			instance, device := s.Metric["instance"], s.Metric["device"]
+			disk := s.Metric["model_name"]
+			if disk == "" {
+				disk = s.Metric["disk"]
+			}
+			issues = append(issues, Issue{
+				Key:       "hardware/" + instance + "/smart/" + device,
+				Type:      "DiskSMART",
+				Severity:  "warning",
+				Message:   fmt.Sprintf("Disk %s %s on %s is failing its SMART self-assessment", device, disk, instance),
+				Timestamp: time.Now(),
+				Kind:      "Node",
+				Name:      instance,
+				Reason:    "SMARTFailed",
+			})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// HASensorsConfig configures the Home Assistant sensor checks, e.g. a UPS on battery or a hot NAS
+type HASensorsConfig struct {
+	Interval int             `yaml:"interval"` // seconds between checks, default 60
+	Sensors  []HASensorCheck `yaml:"sensors"`
+}
+
+// HASensorCheck raises an issue while the entity is in State, or while its numeric state breaches Threshold
+type HASensorCheck struct {
+	Entity    string  `yaml:"entity"`   // e.g. binary_sensor.ups_on_battery
+	State     string  `yaml:"state"`    // e.g. "on"
+	Operator  string  `yaml:"operator"` // <, <=, >, >=, ==, != against Threshold
+	Threshold float64 `yaml:"threshold"`
+	Severity  string  `yaml:"severity"` // critical, warning (default), info
+	Message   string  `yaml:"message"`  // optional description used in the issue message
+}
+
+// validateHASensors checks that every sensor has an entity and either a state or an operator
+func validateHASensors() configProblems {
+	var problems configProblems
+	for i, s := range config.HASensors.Sensors {
+		if !strings.Contains(s.Entity, ".") {
+			problems.addField("sensors", i, "ha_sensors: invalid entity %q", s.Entity)
+		}
+		if (s.State == "") == (s.Operator == "") {
+			problems.addField("sensors", i, "ha_sensors %s: set either state or operator", s.Entity)
+		}
+		if s.Operator != "" && !slices.Contains([]string{"<", "<=", ">", ">=", "==", "!="}, s.Operator) {
+			problems.addField("sensors", i, "ha_sensors %s: unknown operator %q", s.Entity, s.Operator)
+		}
+		switch s.Severity {
+		case "", "critical", "warning", "info":
+		default:
+			problems.addField("sensors", i, "ha_sensors %s: unknown severity %q", s.Entity, s.Severity)
+		}
+	}
+	return problems
+}
+
+// checkHASensors reads each configured sensor and reports those in their alert state, so the bulb
+// reflects the physical infrastructure the cluster runs on; unavailable sensors are skipped
+func checkHASensors(ctx context.Context, _ kubernetes.Interface) []Issue {
+	if !integrationReady("homeassistant") {
+		return nil
+	}
+
+	var issues []Issue
+	cycle := &integrationCycle{integration: "homeassistant"}
+	defer cycle.done()
+	for _, s := range config.HASensors.Sensors {
+		state, err := haEntityState(ctx, s.Entity)
+		if err != nil {
+			cycle.fail(fmt.Sprintf("Error reading sensor %s:", s.Entity), err)
+			continue
+		}
+		if state == "unavailable" || state == "unknown" {
+			log.Printf("Sensor %s is %s", s.Entity, state)
+			continue
+		}
+		if !haSensorBreached(s, state) {
+			continue
+		}
+
+		msg := s.Message
+		if msg == "" {
+			msg = "state " + state
+		}
+		if s.Operator != "" {
+			msg = fmt.Sprintf("%s (%s %s %g)", msg, state, s.Operator, s.Threshold)
+		}
+		severity := s.Severity
+		if severity == "" {
+			severity = "warning"
+		}
+		issues = append(issues, Issue{
+			Key:       "ha-sensor/" + s.Entity,
+			Type:      "HASensor",
+			Severity:  severity,
+			Message:   fmt.Sprintf("%s: %s", s.Entity, msg),
+			Timestamp: time.Now(),
+			Name:      s.Entity,
+		})
+	}
+	return issues
+}
+
+// haSensorBreached reports whether the sensor state is the alert state or breaches the threshold
+func haSensorBreached(s HASensorCheck, state string) bool {
+	if s.State != "" {
+		return state == s.State
+	}
+	value, err := strconv.ParseFloat(state, 64)
+	return err == nil && compareThreshold(value, s.Operator, s.Threshold)
+}
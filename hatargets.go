@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// haStateTargets maps states to a Home Assistant scene or script activated instead of setting
+// the bulb color, so HA can orchestrate several devices per state
+var haStateTargets = map[Signal]string{} // os.Getenv("HA_STATE_TARGETS") // e.g. issues_detected=scene.cluster_red
+
+var haLastTarget = "" // last activated scene or script, so scripts don't rerun every second
+
+// parseHAStateTargets parses HA_STATE_TARGETS into haStateTargets
+func parseHAStateTargets(value string) error {
+	for _, entry := range splitList(value) {
+		state, entity, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("expected state=entity, got %q", entry)
+		}
+		state, entity = strings.TrimSpace(state), strings.TrimSpace(entity)
+		if _, ok := stateColor(Signal(state)); !ok {
+			return fmt.Errorf("unknown state %q", state)
+		}
+		if !strings.HasPrefix(entity, "scene.") && !strings.HasPrefix(entity, "script.") {
+			return fmt.Errorf("%q is not a scene or script entity", entity)
+		}
+		haStateTargets[Signal(state)] = entity
+	}
+	return nil
+}
+
+// haShowSignal shows the signal on the bulb, through its scene or script when one is mapped;
+// it returns false when the signal has neither a target nor a color
+func haShowSignal(ctx context.Context, signal Signal) bool {
+	if target, ok := haStateTargets[signal]; ok {
+		// A failed activation is retried on the next update
+		if target != haLastTarget && haActivateTarget(ctx, target) == nil {
+			haLastTarget = target
+		}
+		return true
+	}
+
+	color, ok := stateColor(signal)
+	if !ok {
+		return false
+	}
+	haLastTarget = ""
+	brightness, transition := haBrightness(signal)
+	// A brightness of 0 and a healthy cluster with HA_OFF_WHEN_HEALTHY switch the bulb off
+	if brightness == 0 || (signal == SignalHealthy && haOffWhenHealthy) {
+		haTurnOffBulb(ctx)
+		return true
+	}
+	attr, value := haColorPayload(ctx, signal, color)
+	haSetBulbColors(ctx, haLightTarget(ctx), attr, value, brightness, transition)
+	return true
+}
+
+// haActivateTarget turns on a scene or runs a script
+func haActivateTarget(ctx context.Context, entity string) error {
+	if !haConfigured() {
+		return nil
+	}
+	domain, _, _ := strings.Cut(entity, ".")
+	return haCallService(ctx, domain, "turn_on", map[string]interface{}{"entity_id": entity})
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+var historyFile = ""          // os.Getenv("HISTORY_FILE") // JSON file on a persistent volume, empty keeps history in memory only
+var historyRetentionDays = 30 // os.Getenv("HISTORY_RETENTION_DAYS") // resolved issues older than this are dropped
+
+// IssueRecord is one occurrence of an issue, from first seen until resolved
+type IssueRecord struct {
+	Key        string     `json:"key"`
+	Type       string     `json:"type"`
+	Message    string     `json:"message"`
+	FirstSeen  time.Time  `json:"first_seen"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// Duration returns how long the issue lasted, or has lasted so far when still open
+func (r IssueRecord) Duration() time.Duration {
+	if r.ResolvedAt != nil {
+		return r.ResolvedAt.Sub(r.FirstSeen)
+	}
+	return time.Since(r.FirstSeen)
+}
+
+// issueHistory holds open issues by key and resolved issues oldest first, along with the
+// outage periods used for availability, the state transitions and the audit trail
+type issueHistory struct {
+	Since       time.Time               `json:"since"`
+	Open        map[string]*IssueRecord `json:"open"`
+	Resolved    []IssueRecord           `json:"resolved"`
+	Outages     []Outage                `json:"outages"`
+	Transitions []StateChange           `json:"transitions,omitempty"`
+	Audit       []AuditEntry            `json:"audit,omitempty"`
+}
+
+// AuditEntry is a noteworthy event kept with the history, e.g. a recovery
+type AuditEntry struct {
+	At      time.Time `json:"at"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+}
+
+// Outage is a period in which the cluster state was not healthy; End is nil while ongoing
+type Outage struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// recentTransitionsMax is the number of transitions served by /api/v1/transitions
+const recentTransitionsMax = 20
+
+var historyMu sync.Mutex
+var history = issueHistory{Since: time.Now(), Open: map[string]*IssueRecord{}}
+
+// IssueStats summarizes the history of one issue key
+type IssueStats struct {
+	Key          string  `json:"key"`
+	Incidents    int     `json:"incidents"`
+	TotalSeconds float64 `json:"total_seconds"`
+	LastSeconds  float64 `json:"last_seconds"`
+	Open         bool    `json:"open"`
+}
+
+// HistorySummary is the incident count and mean time to recovery over the retained history
+type HistorySummary struct {
+	Incidents   int          `json:"incidents"`
+	OpenIssues  int          `json:"open_issues"`
+	MTTRSeconds float64      `json:"mttr_seconds"`
+	Issues      []IssueStats `json:"issues"`
+	Audit       []AuditEntry `json:"audit,omitempty"`
+}
+
+// loadHistory restores the history from HISTORY_FILE, starting empty when it doesn't exist yet
+func loadHistory() error {
+	data, err := os.ReadFile(historyFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if err := json.Unmarshal(data, &history); err != nil {
+		return err
+	}
+	if history.Open == nil {
+		history.Open = map[string]*IssueRecord{}
+	}
+	// An outage still open at shutdown is closed now; the next transition reopens it if it persists
+	if n := len(history.Outages); n > 0 && history.Outages[n-1].End == nil {
+		now := time.Now()
+		history.Outages[n-1].End = &now
+	}
+	return nil
+}
+
+// recordIssues opens a record for every newly active issue and resolves the records of issues
+// that are no longer active, persisting the history when anything changed
+func recordIssues(active []Issue) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	now := time.Now()
+	changed := false
+	seen := map[string]bool{}
+	for _, issue := range active {
+		seen[issue.Key] = true
+		if _, ok := history.Open[issue.Key]; !ok {
+			history.Open[issue.Key] = &IssueRecord{Key: issue.Key, Type: issue.Type, Message: issue.Message, FirstSeen: now}
+			changed = true
+		}
+	}
+	for key, record := range history.Open {
+		if seen[key] {
+			continue
+		}
+		resolved := now
+		record.ResolvedAt = &resolved
+		history.Resolved = append(history.Resolved, *record)
+		delete(history.Open, key)
+		changed = true
+	}
+
+	// Drop resolved issues past the retention
+	cutoff := now.AddDate(0, 0, -historyRetentionDays)
+	for len(history.Resolved) > 0 && history.Resolved[0].ResolvedAt.Before(cutoff) {
+		history.Resolved = history.Resolved[1:]
+		changed = true
+	}
+	for len(history.Outages) > 0 && history.Outages[0].End != nil && history.Outages[0].End.Before(cutoff) {
+		history.Outages = history.Outages[1:]
+		changed = true
+	}
+	for len(history.Transitions) > 0 && history.Transitions[0].At.Before(cutoff) {
+		history.Transitions = history.Transitions[1:]
+		changed = true
+	}
+	for len(history.Audit) > 0 && history.Audit[0].At.Before(cutoff) {
+		history.Audit = history.Audit[1:]
+		changed = true
+	}
+
+	if changed && historyFile != "" {
+		if err := saveHistory(); err != nil {
+			log.Printf("Error saving issue history: %v", err)
+		}
+	}
+}
+
+// recordAudit adds an entry to the audit trail of the history
+func recordAudit(kind, message string) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	history.Audit = append(history.Audit, AuditEntry{At: time.Now(), Kind: kind, Message: message})
+	if historyFile != "" {
+		if err := saveHistory(); err != nil {
+			log.Printf("Error saving issue history: %v", err)
+		}
+	}
+}
+
+// saveHistory writes the history to HISTORY_FILE atomically; callers hold historyMu
+func saveHistory() error {
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	tmp := historyFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, historyFile)
+}
+
+// historySummary computes per-issue durations, incident counts and the MTTR of resolved issues
+func historySummary() HistorySummary {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	stats := map[string]*IssueStats{}
+	add := func(r IssueRecord, open bool) {
+		s, ok := stats[r.Key]
+		if !ok {
+			s = &IssueStats{Key: r.Key}
+			stats[r.Key] = s
+		}
+		s.Incidents++
+		s.TotalSeconds += r.Duration().Seconds()
+		s.LastSeconds = r.Duration().Seconds()
+		s.Open = s.Open || open
+	}
+
+	summary := HistorySummary{Incidents: len(history.Resolved) + len(history.Open), OpenIssues: len(history.Open), Audit: slices.Clone(history.Audit)}
+	var recovery time.Duration
+	for _, r := range history.Resolved {
+		add(r, false)
+		recovery += r.Duration()
+	}
+	for _, r := range history.Open {
+		add(*r, true)
+	}
+	if len(history.Resolved) > 0 {
+		summary.MTTRSeconds = (recovery / time.Duration(len(history.Resolved))).Seconds()
+	}
+
+	for _, s := range stats {
+		summary.Issues = append(summary.Issues, *s)
+	}
+	sort.Slice(summary.Issues, func(i, j int) bool { return summary.Issues[i].TotalSeconds > summary.Issues[j].TotalSeconds })
+	return summary
+}
+
+// transitionsHandler serves the last transitions of the history, oldest first
+func transitionsHandler(w http.ResponseWriter, r *http.Request) {
+	historyMu.Lock()
+	transitions := slices.Clone(history.Transitions[max(0, len(history.Transitions)-recentTransitionsMax):])
+	historyMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(transitions); err != nil {
+		log.Printf("Error encoding transitions: %v", err)
+	}
+}
+
+// historyHandler serves the history summary as JSON
+func historyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(historySummary()); err != nil {
+		log.Printf("Error encoding issue history: %v", err)
+	}
+}
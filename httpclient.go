@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// httpTimeouts are the request timeouts per integration, overridable with HTTP_TIMEOUTS
+// (e.g. "github=20s,ntfy=5s"); integrations not listed use the default entry
+var httpTimeouts = map[string]time.Duration{
+	"default":        10 * time.Second,
+	"homeassistant":  5 * time.Second,
+	"github-graphql": 20 * time.Second,
+}
+
+var httpClientsMu sync.Mutex
+var httpClients = map[string]*http.Client{}
+
+// httpClient returns the shared client of an integration. All clients share the pooled
+// default transport (see configureOutboundTLS) and record request metrics per integration.
+func httpClient(integration string) *http.Client {
+	httpClientsMu.Lock()
+	defer httpClientsMu.Unlock()
+
+	if client, ok := httpClients[integration]; ok {
+		return client
+	}
+	timeout, ok := httpTimeouts[integration]
+	if !ok {
+		timeout = httpTimeouts["default"]
+	}
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: instrumentedTransport{integration: integration, base: http.DefaultTransport},
+	}
+	httpClients[integration] = client
+	return client
+}
+
+// parseHTTPTimeouts parses HTTP_TIMEOUTS into httpTimeouts
+func parseHTTPTimeouts(value string) error {
+	for _, entry := range splitList(value) {
+		name, d, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("expected integration=duration, got %q", entry)
+		}
+		timeout, err := time.ParseDuration(d)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid timeout %q for %s", d, name)
+		}
+		httpTimeouts[strings.TrimSpace(name)] = timeout
+	}
+	return nil
+}
+
+// instrumentedTransport counts requests by integration and status code and sums their durations
+type instrumentedTransport struct {
+	integration string
+	base        http.RoundTripper
+}
+
+func (t instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		if resp.StatusCode == http.StatusUnauthorized {
+			requestTokenReload(t.integration)
+		}
+	}
+	addCounter("clusterbulb_http_requests_total", fmt.Sprintf("integration=%q,code=%q", t.integration, code), 1)
+	addCounter("clusterbulb_http_request_duration_seconds_total", fmt.Sprintf("integration=%q", t.integration), time.Since(start).Seconds())
+	return resp, err
+}
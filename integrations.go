@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var integrationFailureLimit = 5     // os.Getenv("INTEGRATION_FAILURE_LIMIT") // consecutive failures before an integration is degraded
+var integrationDegradedState Signal // os.Getenv("INTEGRATION_DEGRADED_STATE") // e.g. issues_detected, empty only reports degraded integrations
+
+// Degraded integrations are retried with exponential backoff between these bounds
+const integrationBackoffMin = 30 * time.Second
+const integrationBackoffMax = 15 * time.Minute
+
+// Typed integration errors, matched with errors.Is to tell failures apart in logs and metrics
+var (
+	// ErrHAUnreachable wraps failures to reach Home Assistant, including 5xx responses of a proxy in front of it
+	ErrHAUnreachable = errors.New("Home Assistant unreachable")
+	// ErrGitHubRateLimited is returned instead of calling GitHub while backing off from a rate limit
+	ErrGitHubRateLimited = errors.New("GitHub API rate limit exhausted")
+	// ErrKubeAPI wraps failed Kubernetes API requests
+	ErrKubeAPI = errors.New("Kubernetes API request failed")
+)
+
+// errorReason returns the metric label of a typed error, or "error"
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrHAUnreachable):
+		return "ha_unreachable"
+	case errors.Is(err, ErrGitHubRateLimited):
+		return "github_rate_limited"
+	case errors.Is(err, ErrKubeAPI):
+		return "kube_api"
+	}
+	return "error"
+}
+
+// IntegrationHealth tracks the consecutive failures of an integration such as GitHub or Alertmanager
+type IntegrationHealth struct {
+	Failures    int       `json:"consecutive_failures"`
+	Degraded    bool      `json:"degraded"`
+	LastError   string    `json:"last_error,omitempty"`
+	LastFailure time.Time `json:"last_failure,omitzero"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	RetryAfter  time.Time `json:"retry_after,omitzero"`
+}
+
+var integrationsMu sync.Mutex
+var integrations = map[string]*IntegrationHealth{}
+
+// HandleError records a failed call of an integration. After INTEGRATION_FAILURE_LIMIT consecutive
+// failures the integration is marked degraded and retried with backoff; the process keeps running.
+func HandleError(integration string, msg string, err error) {
+	if err == nil {
+		return
+	}
+	// Backing off from a rate limit is expected and does not count as a failure
+	if errors.Is(err, ErrGitHubRateLimited) {
+		log.Printf("%s %v", msg, err)
+		addCounter("clusterbulb_integration_failures_total", fmt.Sprintf("integration=%q,reason=%q", integration, errorReason(err)), 1)
+		return
+	}
+	log.Printf("%s %v", msg, err)
+
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	h, ok := integrations[integration]
+	if !ok {
+		h = &IntegrationHealth{}
+		integrations[integration] = h
+	}
+	h.Failures++
+	h.LastError = err.Error()
+	h.LastFailure = time.Now()
+	addCounter("clusterbulb_integration_failures_total", fmt.Sprintf("integration=%q,reason=%q", integration, errorReason(err)), 1)
+	setGauge("clusterbulb_integration_up", fmt.Sprintf("integration=%q", integration), 0)
+
+	if h.Failures < integrationFailureLimit {
+		return
+	}
+	if !h.Degraded {
+		log.Printf("Integration %s degraded after %d consecutive failures", integration, h.Failures)
+	}
+	h.Degraded = true
+	backoff := integrationBackoffMin << min(h.Failures-integrationFailureLimit, 5)
+	h.RetryAfter = time.Now().Add(min(backoff, integrationBackoffMax))
+	setGauge("clusterbulb_integration_degraded", fmt.Sprintf("integration=%q", integration), 1)
+}
+
+// integrationOK records a successful call, recovering a degraded integration; the last error
+// is kept for diagnosis
+func integrationOK(integration string) {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	h, ok := integrations[integration]
+	if !ok {
+		h = &IntegrationHealth{}
+		integrations[integration] = h
+	}
+	if h.Degraded {
+		log.Printf("Integration %s recovered", integration)
+	}
+	*h = IntegrationHealth{LastError: h.LastError, LastFailure: h.LastFailure, LastSuccess: time.Now()}
+	setGauge("clusterbulb_integration_degraded", fmt.Sprintf("integration=%q", integration), 0)
+	setGauge("clusterbulb_integration_up", fmt.Sprintf("integration=%q", integration), 1)
+}
+
+// integrationCycle collects the calls of an integration during one check cycle, so a check making
+// several calls only reports the integration healthy once all of them succeeded
+type integrationCycle struct {
+	integration string
+	failed      bool
+}
+
+// fail records a failed call of the cycle with HandleError
+func (c *integrationCycle) fail(msg string, err error) {
+	c.failed = true
+	HandleError(c.integration, msg, err)
+}
+
+// done reports the integration healthy unless a call of the cycle failed
+func (c *integrationCycle) done() {
+	if !c.failed {
+		integrationOK(c.integration)
+	}
+}
+
+// integrationsSnapshot returns a copy of the health of every integration that has been called
+func integrationsSnapshot() map[string]IntegrationHealth {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	if len(integrations) == 0 {
+		return nil
+	}
+	snapshot := make(map[string]IntegrationHealth, len(integrations))
+	for name, h := range integrations {
+		snapshot[name] = *h
+	}
+	return snapshot
+}
+
+// integrationsFailing reports whether an integration other than the excepted ones failed its last call
+func integrationsFailing(except ...string) bool {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	for name, h := range integrations {
+		if h.Failures > 0 && !slices.Contains(except, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// integrationReady reports whether a degraded integration is due for a retry
+func integrationReady(integration string) bool {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	h, ok := integrations[integration]
+	return !ok || !time.Now().Before(h.RetryAfter)
+}
+
+// checkIntegrations reports degraded integrations, as info issues unless INTEGRATION_DEGRADED_STATE is set
+func checkIntegrations(_ context.Context, _ kubernetes.Interface) []Issue {
+	severity := "info"
+	if integrationDegradedState != "" {
+		severity = "warning"
+	}
+
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	var issues []Issue
+	for name, h := range integrations {
+		if !h.Degraded {
+			continue
+		}
+		issues = append(issues, Issue{
+			Key:       "integration/" + name,
+			Type:      "IntegrationDegraded",
+			Severity:  severity,
+			Message:   fmt.Sprintf("Integration %s degraded after %d consecutive failures: %s", name, h.Failures, h.LastError),
+			Timestamp: time.Now(),
+		})
+	}
+	return issues
+}
+
+// integrationsHandler serves the health of every integration that has been called
+func integrationsHandler(w http.ResponseWriter, r *http.Request) {
+	integrationsMu.Lock()
+	defer integrationsMu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(integrations); err != nil {
+		log.Printf("Error encoding integrations: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPartialFailuresDegradeIntegration(t *testing.T) {
+	savedLimit, savedPrometheus, savedSensors, savedHaUrl := integrationFailureLimit, config.Prometheus, config.HASensors, haUrl
+	t.Cleanup(func() {
+		integrationFailureLimit, config.Prometheus, config.HASensors, haUrl = savedLimit, savedPrometheus, savedSensors, savedHaUrl
+		delete(integrations, "prometheus")
+		delete(integrations, "kubernetes")
+		delete(integrations, "homeassistant")
+	})
+	integrationFailureLimit = 2
+	delete(integrations, "prometheus")
+	delete(integrations, "kubernetes")
+	delete(integrations, "homeassistant")
+
+	// One of two PromQL checks keeps failing while the other one succeeds
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("query") == "broken" {
+			http.Error(w, "bad query", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+	}))
+	defer srv.Close()
+	config.Prometheus = PrometheusConfig{URL: srv.URL, Queries: []PromQLCheck{
+		{Name: "ok", Query: "up", Operator: "<", Threshold: 1},
+		{Name: "broken", Query: "broken", Operator: "<", Threshold: 1},
+	}}
+	for range integrationFailureLimit {
+		checkPromQL(context.Background(), nil)
+	}
+	if h := integrationsSnapshot()["prometheus"]; !h.Degraded || h.Failures != 2 {
+		t.Errorf("prometheus = %+v, want degraded after 2 partially failed cycles", h)
+	}
+
+	// One of two Home Assistant sensors can't be read
+	ha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/states/sensor.missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"state":"21.5"}`)
+	}))
+	defer ha.Close()
+	haUrl = ha.URL
+	config.HASensors = HASensorsConfig{Sensors: []HASensorCheck{
+		{Entity: "sensor.rack_temperature", Operator: ">", Threshold: 40},
+		{Entity: "sensor.missing", Operator: ">", Threshold: 40},
+	}}
+	for range integrationFailureLimit {
+		checkHASensors(context.Background(), nil)
+	}
+	if h := integrationsSnapshot()["homeassistant"]; !h.Degraded || !h.LastSuccess.IsZero() {
+		t.Errorf("homeassistant = %+v, want degraded without a success", h)
+	}
+
+	// Nodes and pods can be listed but events can't
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("list", "events", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("apiserver timeout")
+	})
+	for range integrationFailureLimit {
+		kube := &integrationCycle{integration: "kubernetes"}
+		checkNodes(context.Background(), clientset, kube)
+		checkPods(context.Background(), clientset, kube)
+		checkEvents(context.Background(), clientset, kube)
+		kube.done()
+	}
+	if h := integrationsSnapshot()["kubernetes"]; !h.Degraded || !h.LastSuccess.IsZero() {
+		t.Errorf("kubernetes = %+v, want degraded without a success", h)
+	}
+
+	// A cycle in which every list succeeds recovers it
+	kube := &integrationCycle{integration: "kubernetes"}
+	healthy := fake.NewSimpleClientset()
+	checkNodes(context.Background(), healthy, kube)
+	checkPods(context.Background(), healthy, kube)
+	checkEvents(context.Background(), healthy, kube)
+	kube.done()
+	if h := integrationsSnapshot()["kubernetes"]; h.Degraded || h.Failures != 0 || h.LastSuccess.IsZero() {
+		t.Errorf("kubernetes = %+v, want recovered after a complete cycle", h)
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+var issueTTLMinutes = 60   // os.Getenv("ISSUE_TTL_MINUTES") // forget issues not reported again within this time
+var issueMaxEntries = 5000 // os.Getenv("ISSUE_MAX_ENTRIES") // cap on tracked issues, least recently reported are evicted first
+
+// pruneKnownIssues expires issues that were never explicitly cleared (e.g. event keys) and
+// enforces the size cap, so a noisy cluster can't grow the monitor's memory without bound
+func pruneKnownIssues() {
+	evicted := 0
+	ttl := time.Duration(issueTTLMinutes) * time.Minute
+	for key, last := range knownIssues {
+		if time.Since(last) > ttl {
+			delete(knownIssues, key)
+			evicted++
+		}
+	}
+
+	// knownIssues holds the last time each issue was reported, so the oldest entries are the least recently used
+	if len(knownIssues) > issueMaxEntries {
+		keys := make([]string, 0, len(knownIssues))
+		for key := range knownIssues {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return knownIssues[keys[i]].Before(knownIssues[keys[j]]) })
+		for _, key := range keys[:len(keys)-issueMaxEntries] {
+			delete(knownIssues, key)
+			evicted++
+		}
+	}
+
+	addCounter("clusterbulb_state_map_evictions_total", `map="known_issues"`, float64(evicted))
+	setGauge("clusterbulb_state_map_entries", `map="known_issues"`, float64(len(knownIssues)))
+	setGauge("clusterbulb_state_map_entries", `map="github_etag_cache"`, float64(len(ghETagCache)))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// issueSighting tracks an issue from the first check it was seen in until it clears
+type issueSighting struct {
+	firstSeen time.Time
+	lastSeen  time.Time
+	count     int
+}
+
+var issueSightings = map[string]*issueSighting{}
+
+var issuesMu sync.Mutex
+var currentIssues []Issue
+
+// trackIssues fills in FirstSeen, LastSeen and Count of the active issues in place, forgets
+// cleared issues, and keeps the issues for /api/v1/issues
+func trackIssues(groups ...[]Issue) {
+	now := time.Now()
+	seen := map[string]bool{}
+	var all []Issue
+	for _, issues := range groups {
+		for i := range issues {
+			issue := &issues[i]
+			s, ok := issueSightings[issue.Key]
+			if !ok {
+				s = &issueSighting{firstSeen: now}
+				issueSightings[issue.Key] = s
+			}
+			if !seen[issue.Key] {
+				s.lastSeen = now
+				s.count++
+			}
+			seen[issue.Key] = true
+
+			issue.FirstSeen, issue.LastSeen = s.firstSeen, s.lastSeen
+			// Events report their own count
+			if issue.Type != "Event" || issue.Count == 0 {
+				issue.Count = s.count
+			}
+		}
+		all = append(all, issues...)
+	}
+	for key := range issueSightings {
+		if !seen[key] {
+			delete(issueSightings, key)
+		}
+	}
+
+	issuesMu.Lock()
+	currentIssues = all
+	issuesMu.Unlock()
+}
+
+// issuesHandler serves the active issues as JSON, filtered by the type, namespace, kind,
+// severity and reason query parameters, e.g. /api/v1/issues?type=Pod&namespace=prod
+func issuesHandler(w http.ResponseWriter, r *http.Request) {
+	filters := map[string]func(Issue) string{
+		"type":      func(i Issue) string { return i.Type },
+		"namespace": func(i Issue) string { return i.Namespace },
+		"kind":      func(i Issue) string { return i.Kind },
+		"severity":  func(i Issue) string { return i.Severity },
+		"reason":    func(i Issue) string { return i.Reason },
+	}
+	query := r.URL.Query()
+
+	issuesMu.Lock()
+	issues := slices.Clone(currentIssues)
+	issuesMu.Unlock()
+
+	matches := []Issue{}
+	for _, issue := range issues {
+		ok := true
+		for param, field := range filters {
+			if values, set := query[param]; set && !slices.Contains(values, field(issue)) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matches = append(matches, issue)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(matches); err != nil {
+		log.Printf("Error encoding issues: %v", err)
+	}
+}
+
+// reportHandler serves the most recent health report as JSON
+func reportHandler(w http.ResponseWriter, r *http.Request) {
+	report := latestReport()
+	if report == nil {
+		http.Error(w, "no health report yet", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Error encoding health report: %v", err)
+	}
+}
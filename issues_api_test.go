@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestIssuesFilterCheckerOutput(t *testing.T) {
+	defer func(w []string, sightings map[string]*issueSighting) {
+		criticalWorkloads, criticalWorkloadsDegraded, issueSightings = w, map[string]Issue{}, sightings
+		trackIssues()
+	}(criticalWorkloads, issueSightings)
+	criticalWorkloads = []string{"media/jellyfin", "kube-system/coredns"}
+	issueSightings = map[string]*issueSighting{}
+
+	replicas := int32(1)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "jellyfin"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "jellyfin", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		},
+	)
+	trackIssues(checkCriticalWorkloads(context.Background(), clientset), checkLoadBalancers(context.Background(), clientset))
+
+	get := func(query string) []Issue {
+		rec := httptest.NewRecorder()
+		issuesHandler(rec, httptest.NewRequest("GET", "/api/v1/issues?"+query, nil))
+		var issues []Issue
+		if err := json.NewDecoder(rec.Body).Decode(&issues); err != nil {
+			t.Fatal(err)
+		}
+		return issues
+	}
+
+	if issues := get("namespace=media"); len(issues) != 2 {
+		t.Errorf("namespace=media: %v, want the critical workload and the service", issues)
+	}
+	issues := get("namespace=media&type=CriticalWorkload")
+	if len(issues) != 1 || issues[0].Kind != "Deployment" || issues[0].Name != "jellyfin" || issues[0].Owner != "deployment/jellyfin" {
+		t.Errorf("namespace=media&type=CriticalWorkload: %+v", issues)
+	}
+	if issues := get("kind=Service"); len(issues) != 1 || issues[0].Namespace != "media" {
+		t.Errorf("kind=Service: %+v", issues)
+	}
+}
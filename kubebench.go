@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var kubeBenchConfigMap = ""                // os.Getenv("KUBE_BENCH_CONFIGMAP") // namespace/name of a ConfigMap holding the JSON results
+var kubeBenchJob = ""                      // os.Getenv("KUBE_BENCH_JOB") // namespace/label selector of the kube-bench Job pods, e.g. kube-bench/app=kube-bench
+var kubeBenchConfigMapKey = "results.json" // os.Getenv("KUBE_BENCH_CONFIGMAP_KEY")
+
+// kubeBenchControls is a section of the kube-bench JSON output (kube-bench run --json)
+type kubeBenchControls struct {
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+	Tests []struct {
+		Section string `json:"section"`
+		Results []struct {
+			TestNumber string `json:"test_number"`
+			TestDesc   string `json:"test_desc"`
+			Status     string `json:"status"`
+			Scored     bool   `json:"scored"`
+		} `json:"results"`
+	} `json:"tests"`
+}
+
+// checkKubeBench raises a warning for every failed scored CIS control of the latest kube-bench
+// results, so security posture regressions after an upgrade show up on the bulb
+func checkKubeBench(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	data, err := kubeBenchResults(ctx, clientset)
+	if err != nil {
+		HandleError("kube-bench", "Error reading kube-bench results:", err)
+		return nil
+	}
+	if data == nil {
+		return nil
+	}
+	controls, err := parseKubeBench(data)
+	if err != nil {
+		HandleError("kube-bench", "Error parsing kube-bench results:", err)
+		return nil
+	}
+	integrationOK("kube-bench")
+
+	var issues []Issue
+	for _, c := range controls {
+		for _, t := range c.Tests {
+			for _, r := range t.Results {
+				if r.Status != "FAIL" || !r.Scored {
+					continue
+				}
+				issues = append(issues, Issue{
+					Key:       "kube-bench/" + r.TestNumber,
+					Type:      "CISControlFailed",
+					Severity:  "warning",
+					Message:   fmt.Sprintf("CIS %s failed: %s", r.TestNumber, r.TestDesc),
+					Timestamp: time.Now(),
+					Reason:    "FAIL",
+				})
+			}
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// parseKubeBench decodes the kube-bench JSON output, either {"Controls": [...]} or the bare
+// list of older releases; log lines such as "[INFO] ..." before the JSON document are skipped
+func parseKubeBench(data []byte) ([]kubeBenchControls, error) {
+	err := fmt.Errorf("no JSON results found")
+	lines := strings.SplitAfter(string(data), "\n")
+	for i, line := range lines {
+		text := strings.Join(lines[i:], "")
+		switch {
+		case strings.HasPrefix(line, "{"):
+			var wrapped struct {
+				Controls []kubeBenchControls `json:"Controls"`
+			}
+			if err = json.NewDecoder(strings.NewReader(text)).Decode(&wrapped); err == nil {
+				return wrapped.Controls, nil
+			}
+		case strings.HasPrefix(line, "["):
+			var controls []kubeBenchControls
+			if err = json.NewDecoder(strings.NewReader(text)).Decode(&controls); err == nil {
+				return controls, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+// kubeBenchResults returns the results from KUBE_BENCH_CONFIGMAP, or the logs of the newest
+// succeeded pod of KUBE_BENCH_JOB; nil without results
+func kubeBenchResults(ctx context.Context, clientset kubernetes.Interface) ([]byte, error) {
+	if kubeBenchConfigMap != "" {
+		namespace, name, _ := strings.Cut(kubeBenchConfigMap, "/")
+		cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get ConfigMap %s: %w", kubeBenchConfigMap, err)
+		}
+		if data, ok := cm.Data[kubeBenchConfigMapKey]; ok {
+			return []byte(data), nil
+		}
+		return nil, fmt.Errorf("ConfigMap %s has no key %s", kubeBenchConfigMap, kubeBenchConfigMapKey)
+	}
+
+	namespace, selector, _ := strings.Cut(kubeBenchJob, "/")
+	pods, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kube-bench pods: %w", err)
+	}
+	var newest *v1.Pod
+	for i, pod := range pods.Items {
+		if pod.Status.Phase == v1.PodSucceeded && (newest == nil || pod.CreationTimestamp.After(newest.CreationTimestamp.Time)) {
+			newest = &pods.Items[i]
+		}
+	}
+	if newest == nil {
+		return nil, nil
+	}
+	return clientset.CoreV1().Pods(namespace).GetLogs(newest.Name, &v1.PodLogOptions{}).DoRaw(ctx)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseKubeBench(t *testing.T) {
+	const results = `[INFO] 1 Control Plane Security Configuration
+{"Controls":[{"id":"1","text":"Control Plane","tests":[{"section":"1.1","results":[
+	{"test_number":"1.1.1","test_desc":"Ensure that the API server pod specification file permissions are set to 600","status":"FAIL","scored":true},
+	{"test_number":"1.1.2","test_desc":"Ensure that the API server pod specification file ownership is set to root:root","status":"PASS","scored":true},
+	{"test_number":"1.1.9","test_desc":"Ensure that the CNI file permissions are set to 600","status":"FAIL","scored":false}
+]}]}],"Totals":{"total_fail":2}}`
+
+	controls, err := parseKubeBench([]byte(results))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(controls) != 1 || len(controls[0].Tests[0].Results) != 3 {
+		t.Fatalf("parsed %+v", controls)
+	}
+
+	// The bare list of older releases
+	controls, err = parseKubeBench([]byte(`[{"id":"4","tests":[]}]`))
+	if err != nil || len(controls) != 1 || controls[0].ID != "4" {
+		t.Errorf("parsed %+v, %v", controls, err)
+	}
+}
+
+func TestCheckKubeBenchErrors(t *testing.T) {
+	defer func(cm string) { kubeBenchConfigMap = cm }(kubeBenchConfigMap)
+	kubeBenchConfigMap = "kube-bench/results"
+	configMap := func(data map[string]string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "kube-bench", Name: "results"}, Data: data}
+	}
+
+	for name, data := range map[string]map[string]string{
+		"missing key":      {"output.json": `{"Controls":[]}`},
+		"malformed output": {"results.json": "[INFO] 1 Control Plane Security Configuration\n{\"Controls\": [{\"id\""},
+	} {
+		delete(integrations, "kube-bench")
+		if issues := checkKubeBench(context.Background(), fake.NewSimpleClientset(configMap(data))); len(issues) != 0 {
+			t.Errorf("%s: issues = %+v, want none", name, issues)
+		}
+		if h := integrations["kube-bench"]; h == nil || h.Failures != 1 {
+			t.Errorf("%s: kube-bench health = %+v, want one failure", name, h)
+		}
+	}
+
+	results := `{"Controls":[{"id":"1","tests":[{"section":"1.1","results":[{"test_number":"1.1.1","test_desc":"Permissions","status":"FAIL","scored":true}]}]}]}`
+	issues := checkKubeBench(context.Background(), fake.NewSimpleClientset(configMap(map[string]string{"results.json": results})))
+	if len(issues) != 1 || issues[0].Key != "kube-bench/1.1.1" {
+		t.Errorf("issues = %+v, want the failed control", issues)
+	}
+	if h := integrations["kube-bench"]; h.Failures != 0 {
+		t.Errorf("kube-bench health = %+v, want recovered", h)
+	}
+}
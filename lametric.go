@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var lametricUrl = ""    // os.Getenv("LAMETRIC_URL") // local API, e.g. http://192.168.1.50:8080
+var lametricApiKey = "" // os.Getenv("LAMETRIC_API_KEY") // device API key from developer.lametric.com
+var lametricIcon = ""   // os.Getenv("LAMETRIC_ICON") // optional icon id shown with the count, e.g. i120
+
+// lametricDisplay posts a notification with the count and message to a LaMetric Time.
+// LaMetric frames can't be tinted, so the state is conveyed by the text and priority.
+type lametricDisplay struct{}
+
+func (lametricDisplay) Name() string {
+	return "LaMetric"
+}
+
+func (lametricDisplay) Show(ctx context.Context, view DisplayView) error {
+	priority := "info"
+	if view.Signal == SignalIssuesDetected {
+		priority = "warning"
+	}
+	count := map[string]interface{}{"text": fmt.Sprintf("%d", view.Count)}
+	if lametricIcon != "" {
+		count["icon"] = lametricIcon
+	}
+	notification := map[string]interface{}{
+		"priority": priority,
+		"model": map[string]interface{}{
+			"cycles": 1,
+			"frames": []map[string]interface{}{count, {"text": view.Message}},
+		},
+	}
+	url := strings.TrimRight(lametricUrl, "/") + "/api/v2/device/notifications"
+	return postJSON(ctx, "lametric", url, notification, func(req *http.Request) {
+		req.SetBasicAuth("dev", lametricApiKey)
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var lbPendingGraceMinutes = 5  // os.Getenv("LB_PENDING_GRACE_MINUTES") // minutes a LoadBalancer may wait for an address
+var loadBalancerIssues []Issue // issues of the last successful check, kept while the services can't be listed
+
+// checkLoadBalancers flags LoadBalancer services that still have no ingress IP or hostname
+// after the grace period, typically a MetalLB pool or cloud controller misconfiguration. While the
+// services can't be listed the issues of the last successful check are kept.
+func checkLoadBalancers(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	services, err := clientset.CoreV1().Services("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		HandleError("loadbalancers", "Error fetching services:", fmt.Errorf("%w: %v", ErrKubeAPI, err))
+		return loadBalancerIssues
+	}
+	integrationOK("loadbalancers")
+
+	grace := time.Duration(lbPendingGraceMinutes) * time.Minute
+	var issues []Issue
+	for _, svc := range services.Items {
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer || len(svc.Status.LoadBalancer.Ingress) > 0 {
+			continue
+		}
+		pending := time.Since(svc.CreationTimestamp.Time)
+		if pending < grace {
+			continue
+		}
+		key := fmt.Sprintf("service/%s/%s", svc.Namespace, svc.Name)
+		message := fmt.Sprintf("LoadBalancer service %s/%s has no external address after %s", svc.Namespace, svc.Name, pending.Round(time.Minute))
+		issues = append(issues, Issue{Key: key, Type: "LoadBalancer", Severity: "warning", Message: message, Timestamp: time.Now(),
+			Namespace: svc.Namespace, Kind: "Service", Name: svc.Name, Reason: "NoIngress"})
+	}
+	loadBalancerIssues = issues
+	return issues
+}
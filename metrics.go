@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// metric is a Prometheus gauge or counter with its samples keyed by label set, e.g. `map="known_issues"`
+type metric struct {
+	kind    string // gauge or counter
+	help    string
+	samples map[string]float64
+}
+
+var metricsMu sync.Mutex
+var metrics = map[string]*metric{
+	"clusterbulb_availability_percent":                {kind: "gauge", help: "Rolling availability of the cluster in percent.", samples: map[string]float64{}},
+	"clusterbulb_error_budget_remaining_percent":      {kind: "gauge", help: "Remaining 30d error budget for SLO_TARGET in percent.", samples: map[string]float64{}},
+	"clusterbulb_ha_commands_replayed_total":          {kind: "counter", help: "Home Assistant bulb commands replayed after it was unreachable.", samples: map[string]float64{}},
+	"clusterbulb_ha_pending_command_age_seconds":      {kind: "gauge", help: "Age of the oldest Home Assistant bulb command waiting to be replayed.", samples: map[string]float64{}},
+	"clusterbulb_ha_pending_commands":                 {kind: "gauge", help: "Home Assistant bulb commands waiting to be replayed, at most one per entity.", samples: map[string]float64{}},
+	"clusterbulb_integration_degraded":                {kind: "gauge", help: "Whether an integration is degraded after consecutive failures (1) or not (0).", samples: map[string]float64{}},
+	"clusterbulb_integration_failures_total":          {kind: "counter", help: "Failed integration calls by reason, e.g. ha_unreachable or kube_api.", samples: map[string]float64{}},
+	"clusterbulb_integration_up":                      {kind: "gauge", help: "Whether the last call of an integration succeeded (1) or failed (0).", samples: map[string]float64{}},
+	"clusterbulb_issue_score":                         {kind: "gauge", help: "Weighted score of the active issues, with scoring configured.", samples: map[string]float64{}},
+	"clusterbulb_http_requests_total":                 {kind: "counter", help: "Outbound HTTP requests by integration and status code.", samples: map[string]float64{}},
+	"clusterbulb_http_request_duration_seconds_total": {kind: "counter", help: "Total time spent on outbound HTTP requests by integration.", samples: map[string]float64{}},
+	"clusterbulb_state_map_entries":                   {kind: "gauge", help: "Entries held in in-memory state maps.", samples: map[string]float64{}},
+	"clusterbulb_state_map_evictions_total":           {kind: "counter", help: "Entries evicted from in-memory state maps by TTL or size cap.", samples: map[string]float64{}},
+	"clusterbulb_state_signal":                        {kind: "gauge", help: "Whether a cluster state signal is active (1) or not (0).", samples: map[string]float64{}},
+	"clusterbulb_state_transitions_total":             {kind: "counter", help: "Cluster state transitions.", samples: map[string]float64{}},
+}
+
+// setGauge sets a gauge sample
+func setGauge(name string, labels string, value float64) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metrics[name].samples[labels] = value
+}
+
+// addCounter increases a counter sample
+func addCounter(name string, labels string, delta float64) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	metrics[name].samples[labels] += delta
+}
+
+// metricsHandler serves all metrics in the Prometheus text exposition format
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	var names []string
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		m := metrics[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, m.help, name, m.kind)
+		var labels []string
+		for l := range m.samples {
+			labels = append(labels, l)
+		}
+		sort.Strings(labels)
+		for _, l := range labels {
+			if l == "" {
+				fmt.Fprintf(&b, "%s %g\n", name, m.samples[l])
+			} else {
+				fmt.Fprintf(&b, "%s{%s} %g\n", name, l, m.samples[l])
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write([]byte(b.String()))
+}
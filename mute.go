@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+var haMuteEntityId = "" // os.Getenv("HA_MUTE_ENTITY_ID") // input_boolean or input_select that silences the bulb and ntfy
+var haMuteState = ""    // os.Getenv("HA_MUTE_STATE") // muted state of an input_select, input_booleans are muted when "on"
+
+var haMuted = false
+
+// haMuteCheck polls the mute helper entity; on errors the last known mute state is kept
+func haMuteCheck(ctx context.Context) {
+	if haMuteEntityId == "" || haToken.Get() == "" || haUrl == "" {
+		return
+	}
+	state, err := haEntityState(ctx, haMuteEntityId)
+	if err != nil {
+		log.Printf("Error reading mute entity %s: %v", haMuteEntityId, err)
+		return
+	}
+
+	muted := state == "on"
+	if haMuteState != "" {
+		muted = state == haMuteState
+	}
+	if muted != haMuted {
+		log.Printf("Mute entity %s is %s, muted: %t", haMuteEntityId, state, muted)
+	}
+	haMuted = muted
+}
+
+// haEntityState returns the state of a Home Assistant entity
+func haEntityState(ctx context.Context, entityId string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/states/%s", haUrl, entityId), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", haToken.Get()))
+
+	resp, err := httpClient("homeassistant").Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrHAUnreachable, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Home Assistant returned status: %s", resp.Status)
+	}
+
+	var entity struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entity); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return entity.State, nil
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var nanoleafToken = "" // os.Getenv("NANOLEAF_TOKEN") // auth token of the Nanoleaf Open API
+
+// NanoleafConfig maps components to sections of a Nanoleaf panel layout, so one section shows the
+// health of the infrastructure and another that of the apps
+type NanoleafConfig struct {
+	URL   string         `yaml:"url"` // e.g. http://192.168.1.50:16021
+	Zones []NanoleafZone `yaml:"zones"`
+}
+
+// NanoleafZone shows a component, or the whole cluster when Component is empty, on its panels
+type NanoleafZone struct {
+	Component string `yaml:"component"`
+	Panels    []int  `yaml:"panels"` // panel IDs; zones without are given the remaining panels, left to right
+}
+
+// nanoleafNoLEDs are the shape types without LEDs: the Rhythm module and the Shapes controller
+var nanoleafNoLEDs = []int{1, 12}
+
+// validateNanoleaf checks the zones name existing components and don't share panels
+func validateNanoleaf() configProblems {
+	var problems configProblems
+	nl := config.Nanoleaf
+	if nl.URL == "" {
+		return nil
+	}
+	if nanoleafToken == "" {
+		problems.add(-1, "nanoleaf needs NANOLEAF_TOKEN")
+	}
+	if len(nl.Zones) == 0 {
+		problems.add(-1, "nanoleaf needs at least one zone")
+	}
+	panels := map[int]bool{}
+	for i, z := range nl.Zones {
+		if z.Component != "" && !slices.ContainsFunc(config.Components, func(c Component) bool { return c.Name == z.Component }) {
+			problems.addField("zones", i, "nanoleaf zone: unknown component %q", z.Component)
+		}
+		for _, p := range z.Panels {
+			if panels[p] {
+				problems.addField("zones", i, "nanoleaf panel %d is in more than one zone", p)
+			}
+			panels[p] = true
+		}
+	}
+	return problems
+}
+
+// nanoleafDisplay writes a static effect with the color of each zone to a Nanoleaf layout
+type nanoleafDisplay struct {
+	zones    [][]int // panels of each zone, resolved from the layout on first use
+	lastAnim string
+}
+
+func (*nanoleafDisplay) Name() string {
+	return "Nanoleaf"
+}
+
+func (d *nanoleafDisplay) Show(ctx context.Context, _ DisplayView) error {
+	return d.update(ctx)
+}
+
+// Refresh picks up component changes that leave the overall view unchanged
+func (d *nanoleafDisplay) Refresh() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return d.update(ctx)
+}
+
+// update writes the zone colors when they changed
+func (d *nanoleafDisplay) update(ctx context.Context) error {
+	if d.zones == nil {
+		zones, err := nanoleafZones(ctx)
+		if err != nil {
+			return err
+		}
+		d.zones = zones
+	}
+
+	var frames []string
+	panels := 0
+	for i, z := range config.Nanoleaf.Zones {
+		color := zoneColor(z)
+		for _, p := range d.zones[i] {
+			// panel ID, one frame, then the color, white and a one second transition
+			frames = append(frames, fmt.Sprintf("%d 1 %d %d %d 0 10", p, color[0], color[1], color[2]))
+			panels++
+		}
+	}
+	anim := fmt.Sprintf("%d %s", panels, strings.Join(frames, " "))
+	if anim == d.lastAnim {
+		return nil
+	}
+	effect := map[string]interface{}{"write": map[string]interface{}{
+		"command":  "display",
+		"animType": "static",
+		"animData": anim,
+		"loop":     false,
+		"palette":  []interface{}{},
+	}}
+	if err := nanoleafRequest(ctx, "PUT", "/effects", effect, nil); err != nil {
+		return err
+	}
+	d.lastAnim = anim
+	return nil
+}
+
+// zoneColor returns the color of the primary signal of the zone, preferring issues_detected
+// like the other displays
+func zoneColor(z NanoleafZone) [3]int {
+	state := shownState()
+	for _, c := range config.Components {
+		if z.Component != "" && c.Name == z.Component {
+			state = c.state
+		}
+	}
+	signal := state.Signals()[0]
+	if state.Has(SignalIssuesDetected) {
+		signal = SignalIssuesDetected
+	}
+	color, _ := stateColor(signal)
+	return color
+}
+
+// nanoleafPanel is a panel of the layout, positioned in the plane of the wall
+type nanoleafPanel struct {
+	PanelID   int `json:"panelId"`
+	X         int `json:"x"`
+	Y         int `json:"y"`
+	ShapeType int `json:"shapeType"`
+}
+
+// nanoleafZones returns the panels of each zone, splitting the panels not listed by any zone
+// left to right between the zones without panels
+func nanoleafZones(ctx context.Context) ([][]int, error) {
+	var layout struct {
+		PositionData []nanoleafPanel `json:"positionData"`
+	}
+	if err := nanoleafRequest(ctx, "GET", "/panelLayout/layout", nil, &layout); err != nil {
+		return nil, err
+	}
+	claimed := map[int]bool{}
+	var auto []int
+	for i, z := range config.Nanoleaf.Zones {
+		for _, p := range z.Panels {
+			claimed[p] = true
+		}
+		if len(z.Panels) == 0 {
+			auto = append(auto, i)
+		}
+	}
+	positions := layout.PositionData
+	slices.SortStableFunc(positions, func(a, b nanoleafPanel) int {
+		if a.X != b.X {
+			return a.X - b.X
+		}
+		return a.Y - b.Y
+	})
+	var free []int
+	for _, p := range positions {
+		if !claimed[p.PanelID] && !slices.Contains(nanoleafNoLEDs, p.ShapeType) {
+			free = append(free, p.PanelID)
+		}
+	}
+
+	zones := make([][]int, len(config.Nanoleaf.Zones))
+	for i, z := range config.Nanoleaf.Zones {
+		zones[i] = z.Panels
+	}
+	for n, i := range auto {
+		zones[i] = free[n*len(free)/len(auto) : (n+1)*len(free)/len(auto)]
+	}
+	log.Printf("Nanoleaf zones: %v", zones)
+	return zones, nil
+}
+
+// nanoleafRequest calls the Nanoleaf Open API, sending payload and decoding the response into v
+// when they are set
+func nanoleafRequest(ctx context.Context, method, path string, payload interface{}, v interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+	}
+	url := fmt.Sprintf("%s/api/v1/%s%s", strings.TrimRight(config.Nanoleaf.URL, "/"), nanoleafToken, path)
+	req, err := http.NewRequestWithContext(ctx, method, url, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v != nil {
+		return httpGetJSON("nanoleaf", req, v)
+	}
+
+	resp, err := httpClient("nanoleaf").Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("nanoleaf returned unexpected status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNanoleafZones(t *testing.T) {
+	var anims []string
+	nl := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/token/panelLayout/layout":
+			w.Write([]byte(`{"positionData": [
+				{"panelId": 3, "x": 200, "y": 0, "shapeType": 7},
+				{"panelId": 1, "x": 0, "y": 0, "shapeType": 7},
+				{"panelId": 9, "x": 50, "y": 0, "shapeType": 12},
+				{"panelId": 2, "x": 100, "y": 0, "shapeType": 7},
+				{"panelId": 4, "x": 300, "y": 0, "shapeType": 7},
+				{"panelId": 5, "x": 400, "y": 0, "shapeType": 7}]}`))
+		case "/api/v1/token/effects":
+			var effect struct {
+				Write struct {
+					AnimData string `json:"animData"`
+				} `json:"write"`
+			}
+			json.NewDecoder(r.Body).Decode(&effect)
+			anims = append(anims, effect.Write.AnimData)
+		}
+	}))
+	defer nl.Close()
+
+	nanoleafToken = "token"
+	config.Components = []Component{{issueGroup: issueGroup{Name: "Infra"}}, {issueGroup: issueGroup{Name: "Apps", state: ClusterState{SignalIssuesDetected}}}}
+	config.Nanoleaf = NanoleafConfig{URL: nl.URL, Zones: []NanoleafZone{{Component: "Infra"}, {Component: "Apps"}, {Panels: []int{5}}}}
+	defer func() { nanoleafToken, config.Components, config.Nanoleaf = "", nil, NanoleafConfig{} }()
+	if err := validateNanoleaf(); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &nanoleafDisplay{}
+	if err := d.Show(context.Background(), DisplayView{}); err != nil {
+		t.Fatal(err)
+	}
+	// The controller has no LEDs; the free panels split left to right, panel 5 shows the cluster
+	green, red := haStateColors[SignalHealthy], haStateColors[SignalIssuesDetected]
+	want := "5 1 1 " + rgb(green) + " 0 10 2 1 " + rgb(green) + " 0 10 3 1 " + rgb(red) + " 0 10 4 1 " + rgb(red) + " 0 10 5 1 " + rgb(green) + " 0 10"
+	if len(anims) != 1 || anims[0] != want {
+		t.Errorf("anim data %q, want %q", anims, want)
+	}
+
+	// Unchanged colors aren't written again
+	d.Refresh()
+	if len(anims) != 1 {
+		t.Errorf("unchanged zones were written %d times", len(anims))
+	}
+}
+
+func rgb(c [3]int) string {
+	return fmt.Sprintf("%d %d %d", c[0], c[1], c[2])
+}
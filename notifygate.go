@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+var quietHours *ScheduleRule   // os.Getenv("QUIET_HOURS") // e.g. 22:00-07:00, holds back sounds and non-urgent notifications
+var ntfyInterval time.Duration // os.Getenv("NTFY_INTERVAL_MINUTES") // minimum time between two notifications about the same thing
+
+// notifyLastSent is the last delivery per notification channel, for rate limiting
+var notifyLastSent = map[string]time.Time{}
+var notifyLastSentMu sync.Mutex
+
+// parseQuietHours parses a HH:MM-HH:MM window, which wraps past midnight when it ends before it starts
+func parseQuietHours(value string) (*ScheduleRule, error) {
+	from, to, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, fmt.Errorf("expected HH:MM-HH:MM")
+	}
+	rule := &ScheduleRule{Name: "quiet-hours", From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+	for _, t := range []string{rule.From, rule.To} {
+		if _, err := scheduleMinutes(t, 0); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
+
+// notifySilenced reports whether a notification of the priority (1-5) is held back by the
+// mute, the warm-up window or QUIET_HOURS; urgent notifications (5) get through quiet hours
+func notifySilenced(priority int) bool {
+	if isMuted() || warmingUp() {
+		return true
+	}
+	return quietHours != nil && quietHours.active(time.Now()) && priority < 5
+}
+
+// ntfyKey identifies what a notification is about, for rate limiting and the warm-up queue
+func ntfyKey(opts NtfyOptions) string {
+	if opts.Key != "" {
+		return opts.Key
+	}
+	return opts.Title
+}
+
+// notifyRateLimited reports whether the channel already delivered within interval, and
+// otherwise records a delivery now
+func notifyRateLimited(channel string, interval time.Duration) bool {
+	notifyLastSentMu.Lock()
+	defer notifyLastSentMu.Unlock()
+	if time.Since(notifyLastSent[channel]) < interval {
+		return true
+	}
+	notifyLastSent[channel] = time.Now()
+	return false
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// namespaceLight is a bulb showing the state of a single namespace, with its own blink cycle
+type namespaceLight struct {
+	namespace string
+	entityId  string
+	state     ClusterState
+	last      Signal
+}
+
+var namespaceLights []*namespaceLight // os.Getenv("NAMESPACE_LIGHTS") // e.g. media=light.shelf_left,home=light.shelf_right
+
+// parseNamespaceLights parses NAMESPACE_LIGHTS into namespaceLights
+func parseNamespaceLights(value string) error {
+	for _, entry := range splitList(value) {
+		namespace, entityId, ok := strings.Cut(entry, "=")
+		namespace, entityId = strings.TrimSpace(namespace), strings.TrimSpace(entityId)
+		if !ok || namespace == "" || !strings.HasPrefix(entityId, "light.") {
+			return fmt.Errorf("expected namespace=light.entity, got %q", entry)
+		}
+		namespaceLights = append(namespaceLights, &namespaceLight{namespace: namespace, entityId: entityId})
+	}
+	return nil
+}
+
+// updateNamespaceStates composes the state of every namespace light from the issues in its namespace;
+// warning events are passed separately so they can map to the warning_events state
+func updateNamespaceStates(issues []Issue, eventIssues []Issue) {
+	for _, nl := range namespaceLights {
+		var checkIssues []Issue
+		for _, issue := range issues {
+			if issue.Namespace == nl.namespace {
+				checkIssues = append(checkIssues, issue)
+			}
+		}
+		warningEvents := false
+		for _, issue := range eventIssues {
+			if issue.Namespace == nl.namespace {
+				warningEvents = true
+			}
+		}
+
+		state := composeState(StateInputs{
+			WarningEvents:   warningEvents,
+			WarningEventSig: warningEventsState,
+			CheckIssues:     checkIssues,
+		})
+		if state.String() != nl.state.String() {
+			log.Printf("Namespace %s state: %s -> %s", nl.namespace, nl.state, state)
+		}
+		nl.state = state
+	}
+}
+
+// haUpdateNamespaceLights advances the blink cycle of every namespace light
+func haUpdateNamespaceLights(ctx context.Context) {
+	for _, nl := range namespaceLights {
+		next := nextBlinkSignal(nl.state, nl.last)
+		color, ok := stateColor(next)
+		if !ok {
+			continue
+		}
+		nl.last = next
+		haSetBulbColors(ctx, nl.entityId, "rgb_color", color[:], haLightBrightness, haLightTransition)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"mime"
+	"strings"
+	"sync"
+)
+
+var ntfyAttachReport = ""  // os.Getenv("NTFY_ATTACH_REPORT") // inline, attach or auto; empty disables
+var ntfyAttachPriority = 4 // os.Getenv("NTFY_ATTACH_PRIORITY") // minimum priority of notifications carrying the report
+
+// ntfyInlineMax is the ntfy message size limit; larger messages are turned into attachments by the server
+const ntfyInlineMax = 4096
+
+// lastReport is the most recent health report, set before its transitions are emitted
+var lastReport *HealthReport
+var lastReportMu sync.Mutex
+
+// setLastReport replaces the most recent health report
+func setLastReport(report *HealthReport) {
+	lastReportMu.Lock()
+	defer lastReportMu.Unlock()
+	lastReport = report
+}
+
+// latestReport returns the most recent health report, or nil before the first check
+func latestReport() *HealthReport {
+	lastReportMu.Lock()
+	defer lastReportMu.Unlock()
+	return lastReport
+}
+
+// ntfyWithReport adds the last health report to a critical notification, inline below the
+// message or as a report.json attachment, and returns the message to send
+func ntfyWithReport(message string, opts *NtfyOptions) string {
+	last := latestReport()
+	if ntfyAttachReport == "" || last == nil || opts.Priority < ntfyAttachPriority {
+		return message
+	}
+	report, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling health report: %v", err)
+		return message
+	}
+
+	inline := message + "\n\n" + string(report)
+	if ntfyAttachReport == "inline" || (ntfyAttachReport == "auto" && len(inline) <= ntfyInlineMax) {
+		return inline
+	}
+	opts.Attachment = report
+	opts.Filename = "report.json"
+	return message
+}
+
+// ntfyHeaderMessage encodes a message for the Message header, which ntfy uses when the body is an
+// attachment. Header values must be ASCII, so other messages are sent RFC 2047 encoded, which ntfy
+// decodes.
+func ntfyHeaderMessage(message string) string {
+	return ntfyHeader(strings.ReplaceAll(message, "\n", `\n`))
+}
+
+// ntfyHeader RFC 2047 encodes a header value unless it is plain ASCII
+func ntfyHeader(value string) string {
+	return mime.BEncoding.Encode("UTF-8", value)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfyHeaderMessage(t *testing.T) {
+	if got := ntfyHeaderMessage("Pods failing\nsee report"); got != `Pods failing\nsee report` {
+		t.Errorf("ASCII message = %q, want it unencoded", got)
+	}
+
+	got := ntfyHeaderMessage("Node büro is running hot: 91°C")
+	for _, c := range got {
+		if c > 127 {
+			t.Fatalf("header %q is not ASCII", got)
+		}
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(got)
+	if err != nil || decoded != "Node büro is running hot: 91°C" {
+		t.Errorf("decoded header = %q, %v", decoded, err)
+	}
+}
+
+func TestNtfyAttachmentSendsEncodedHeaders(t *testing.T) {
+	defer func(driver, attach string, report *HealthReport) {
+		notifyDriver, ntfyAttachReport = driver, attach
+		setLastReport(report)
+	}(notifyDriver, ntfyAttachReport, latestReport())
+	notifyDriver, ntfyAttachReport = "ntfy", "attach"
+	setLastReport(&HealthReport{ClusterState: "issues_detected"})
+
+	var header http.Header
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	err := SendNtfyAlert("Zone süd is down", NtfyOptions{Server: srv.URL, Topic: "clusterbulb", Title: "Ausfall: süd", Priority: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := new(mime.WordDecoder)
+	if msg, err := dec.DecodeHeader(header.Get("Message")); err != nil || msg != "Zone süd is down" {
+		t.Errorf("Message header = %q (%q, %v)", header.Get("Message"), msg, err)
+	}
+	if title, err := dec.DecodeHeader(header.Get("Title")); err != nil || title != "Ausfall: süd" {
+		t.Errorf("Title header = %q (%q, %v)", header.Get("Title"), title, err)
+	}
+	if header.Get("Filename") != "report.json" || len(body) == 0 {
+		t.Errorf("attachment %q of %d bytes, want report.json", header.Get("Filename"), len(body))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// topOffendersMax bounds the offenders listed in the report
+const topOffendersMax = 10
+
+// Offender is a group of issues sharing a namespace, owner workload and reason
+type Offender struct {
+	Namespace string `json:"namespace,omitempty"`
+	Owner     string `json:"owner"` // e.g. deployment/web
+	Reason    string `json:"reason,omitempty"`
+	Count     int    `json:"count"`
+	Message   string `json:"message"` // e.g. "deployment web: 12 unhealthy pods (ContainersNotReady)"
+}
+
+// podOwners resolves the workload owning pods, following ReplicaSets up to their Deployment;
+// lookups are cached for one check cycle
+type podOwners struct {
+	clientset   kubernetes.Interface
+	replicaSets map[string]string
+}
+
+// owner returns the owning workload as kind/name, or "" for pods without a controller
+func (o *podOwners) owner(ctx context.Context, pod v1.Pod) string {
+	ref := metav1.GetControllerOf(&pod)
+	if ref == nil {
+		return ""
+	}
+	if ref.Kind != "ReplicaSet" {
+		return strings.ToLower(ref.Kind) + "/" + ref.Name
+	}
+
+	key := pod.Namespace + "/" + ref.Name
+	if owner, ok := o.replicaSets[key]; ok {
+		return owner
+	}
+	owner := "replicaset/" + ref.Name
+	if rs, err := o.clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, ref.Name, metav1.GetOptions{}); err == nil {
+		if dep := metav1.GetControllerOf(rs); dep != nil && dep.Kind == "Deployment" {
+			owner = "deployment/" + dep.Name
+		}
+	}
+	o.replicaSets[key] = owner
+	return owner
+}
+
+// topOffenders groups the issues with an owner by namespace, owner and reason, largest first
+func topOffenders(issues []Issue) []Offender {
+	groups := map[[3]string]int{}
+	for _, issue := range issues {
+		if issue.Owner != "" {
+			groups[[3]string{issue.Namespace, issue.Owner, issue.Reason}]++
+		}
+	}
+
+	var offenders []Offender
+	for g, count := range groups {
+		kind, name, _ := strings.Cut(g[1], "/")
+		msg := fmt.Sprintf("%s %s: %d unhealthy %s", kind, name, count, plural(count, "pod", "pods"))
+		if g[2] != "" {
+			msg += fmt.Sprintf(" (%s)", g[2])
+		}
+		offenders = append(offenders, Offender{Namespace: g[0], Owner: g[1], Reason: g[2], Count: count, Message: msg})
+	}
+	slices.SortFunc(offenders, func(a, b Offender) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Owner, b.Owner), cmp.Compare(a.Reason, b.Reason))
+	})
+	if len(offenders) > topOffendersMax {
+		offenders = offenders[:topOffendersMax]
+	}
+	return offenders
+}
+
+// plural returns one or many depending on n
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
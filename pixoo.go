@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+var pixooUrl = "" // os.Getenv("PIXOO_URL") // Divoom Pixoo 64 local API, e.g. http://192.168.1.60
+
+// pixooDisplay fills a Divoom Pixoo 64 with the state color and draws the count and a scrolling message
+type pixooDisplay struct{}
+
+const pixooSize = 64
+
+func (pixooDisplay) Name() string {
+	return "Pixoo"
+}
+
+func (pixooDisplay) Show(ctx context.Context, view DisplayView) error {
+	url := strings.TrimRight(pixooUrl, "/") + "/post"
+
+	// Pictures are sent as raw RGB pixels; the id counter must be reset before the first frame
+	if err := postJSON(ctx, "pixoo", url, map[string]interface{}{"Command": "Draw/ResetHttpGifId"}, nil); err != nil {
+		return err
+	}
+	pixels := make([]byte, 0, pixooSize*pixooSize*3)
+	for range pixooSize * pixooSize {
+		pixels = append(pixels, byte(view.Color[0]), byte(view.Color[1]), byte(view.Color[2]))
+	}
+	picture := map[string]interface{}{
+		"Command":   "Draw/SendHttpGif",
+		"PicNum":    1,
+		"PicWidth":  pixooSize,
+		"PicOffset": 0,
+		"PicID":     1,
+		"PicSpeed":  1000,
+		"PicData":   base64.StdEncoding.EncodeToString(pixels),
+	}
+	if err := postJSON(ctx, "pixoo", url, picture, nil); err != nil {
+		return err
+	}
+
+	texts := []map[string]interface{}{
+		{"TextId": 1, "y": 16, "font": 4, "speed": 0, "align": 2, "TextString": fmt.Sprintf("%d", view.Count)},
+		{"TextId": 2, "y": 40, "font": 2, "speed": 50, "align": 1, "TextString": view.Message},
+	}
+	for _, text := range texts {
+		text["Command"] = "Draw/SendHttpText"
+		text["x"] = 0
+		text["dir"] = 0
+		text["TextWidth"] = pixooSize
+		text["color"] = "#FFFFFF"
+		if err := postJSON(ctx, "pixoo", url, text, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
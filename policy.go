@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/kubernetes"
+)
+
+var policyMinSeverity = "medium" // os.Getenv("POLICY_MIN_SEVERITY") // critical, high, medium, low, info
+var policyReportIssues []Issue   // Kyverno issues of the last successful check, kept while the reports can't be listed
+var gatekeeperIssues []Issue     // Gatekeeper issues of the last successful check, kept while the constraints can't be listed
+
+// policySeverityRank orders policy result severities, higher is more severe
+var policySeverityRank = map[string]int{
+	"info": 0, "low": 1, "medium": 2, "high": 3, "critical": 4,
+}
+
+// PolicyReportList represents Kyverno (wgpolicyk8s.io) PolicyReports or ClusterPolicyReports
+type PolicyReportList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Results []struct {
+			Policy    string `json:"policy"`
+			Rule      string `json:"rule"`
+			Result    string `json:"result"`   // pass, fail, warn, error, skip
+			Severity  string `json:"severity"` // critical, high, medium, low, info, often unset
+			Resources []struct {
+				Kind      string `json:"kind"`
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"resources"`
+		} `json:"results"`
+	} `json:"items"`
+}
+
+// ConstraintList represents the Gatekeeper constraints of one constraint template
+type ConstraintList struct {
+	Items []struct {
+		Kind     string `json:"kind"`
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Spec struct {
+			EnforcementAction string `json:"enforcementAction"` // deny (default), warn, dryrun
+		} `json:"spec"`
+		Status struct {
+			TotalViolations int `json:"totalViolations"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// checkPolicies raises a warning for every Kyverno policy or Gatekeeper constraint with violations
+// at or above POLICY_MIN_SEVERITY; it does nothing when neither is installed
+func checkPolicies(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	issues := checkPolicyReports(ctx, clientset)
+	return append(issues, checkGatekeeperConstraints(ctx, clientset)...)
+}
+
+// checkPolicyReports aggregates failed Kyverno policy report results per policy. A partial list would
+// clear the violations of the reports it missed, so a failed list keeps the issues of the last check.
+func checkPolicyReports(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	minRank := policySeverityRank[policyMinSeverity]
+	counts := map[string]int{}
+	examples := map[string]string{}
+	var policies []string
+	installed := false
+
+	for _, resource := range []string{"policyreports", "clusterpolicyreports"} {
+		var reports PolicyReportList
+		found, err := listCustomResources(ctx, clientset, "wgpolicyk8s.io/v1alpha2", resource, &reports)
+		if err != nil {
+			HandleError("kyverno", fmt.Sprintf("Error fetching %s:", resource), err)
+			return policyReportIssues
+		}
+		if !found {
+			continue
+		}
+		installed = true
+
+		for _, report := range reports.Items {
+			for _, r := range report.Results {
+				if r.Result != "fail" {
+					continue
+				}
+				// Most policies don't declare a severity, treat those as medium
+				severity := r.Severity
+				if severity == "" {
+					severity = "medium"
+				}
+				if policySeverityRank[severity] < minRank {
+					continue
+				}
+				if counts[r.Policy] == 0 {
+					policies = append(policies, r.Policy)
+					if len(r.Resources) > 0 {
+						res := r.Resources[0]
+						examples[r.Policy] = strings.TrimPrefix(fmt.Sprintf("%s/%s/%s", res.Namespace, res.Kind, res.Name), "/")
+					}
+				}
+				counts[r.Policy]++
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, policy := range policies {
+		message := fmt.Sprintf("Policy %s: %d violations", policy, counts[policy])
+		if examples[policy] != "" {
+			message += fmt.Sprintf(" (e.g. %s)", examples[policy])
+		}
+		issues = append(issues, Issue{Key: "policy/" + policy, Type: "PolicyViolation", Severity: "warning", Message: message, Timestamp: time.Now()})
+	}
+	if installed {
+		integrationOK("kyverno")
+	}
+	policyReportIssues = issues
+	return issues
+}
+
+// checkGatekeeperConstraints reports Gatekeeper audit violations per constraint. Every constraint
+// template adds its own resource to constraints.gatekeeper.sh, so they are discovered first. When a
+// list fails the issues of the last successful check are kept.
+func checkGatekeeperConstraints(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	resources, err := clientset.Discovery().ServerResourcesForGroupVersion("constraints.gatekeeper.sh/v1beta1")
+	if apierrors.IsNotFound(err) {
+		gatekeeperIssues = nil
+		return nil
+	}
+	if err != nil {
+		HandleError("gatekeeper", "Error discovering Gatekeeper constraints:", err)
+		return gatekeeperIssues
+	}
+
+	// Gatekeeper has no severities, so derive one from the enforcement action
+	actionSeverity := map[string]string{"deny": "high", "warn": "medium", "dryrun": "low"}
+	minRank := policySeverityRank[policyMinSeverity]
+
+	var issues []Issue
+	for _, r := range resources.APIResources {
+		if strings.Contains(r.Name, "/") {
+			continue // skip subresources such as status
+		}
+		var constraints ConstraintList
+		if err := getCustomResources(ctx, clientset, "constraints.gatekeeper.sh/v1beta1", r.Name, &constraints); err != nil {
+			HandleError("gatekeeper", "Error fetching Gatekeeper constraints:", err)
+			return gatekeeperIssues
+		}
+		for _, c := range constraints.Items {
+			action := c.Spec.EnforcementAction
+			if action == "" {
+				action = "deny"
+			}
+			if c.Status.TotalViolations == 0 || policySeverityRank[actionSeverity[action]] < minRank {
+				continue
+			}
+			key := fmt.Sprintf("policy/%s/%s", c.Kind, c.Metadata.Name)
+			message := fmt.Sprintf("Constraint %s/%s: %d violations (%s)", c.Kind, c.Metadata.Name, c.Status.TotalViolations, action)
+			issues = append(issues, Issue{Key: key, Type: "PolicyViolation", Severity: "warning", Message: message, Timestamp: time.Now()})
+		}
+	}
+	integrationOK("gatekeeper")
+	gatekeeperIssues = issues
+	return issues
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ghPREscalateCount = 0   // os.Getenv("GH_PR_ESCALATE_COUNT") // escalate when more than this many PRs are open, 0 disables
+var ghPREscalateAgeDays = 0 // os.Getenv("GH_PR_ESCALATE_AGE_DAYS") // escalate when any PR is older than this, 0 disables
+
+// isPREscalated reports whether the open PRs exceed the configured count or age thresholds
+func isPREscalated(prs []PullRequest) bool {
+	if ghPREscalateCount > 0 && len(prs) > ghPREscalateCount {
+		return true
+	}
+	if ghPREscalateAgeDays > 0 {
+		maxAge := time.Duration(ghPREscalateAgeDays) * 24 * time.Hour
+		for _, pr := range prs {
+			if time.Since(pr.CreatedAt) > maxAge {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// formatAge renders a duration as a short human readable age (e.g. 45m, 5h, 9d)
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+// formatPRAges lists each PR with its age, oldest first, for notifications
+func formatPRAges(prs []PullRequest) string {
+	prs = slices.SortedFunc(slices.Values(prs), func(a, b PullRequest) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	var lines []string
+	for _, pr := range prs {
+		line := fmt.Sprintf("%s by %s (%s)", pr.link(), pr.User.Login, formatAge(time.Since(pr.CreatedAt)))
+		if pr.Draft {
+			line += " [draft]"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatPRAgesOldestFirst(t *testing.T) {
+	now := time.Now()
+	prs := []PullRequest{
+		{Number: 2, Title: "middle", CreatedAt: now.Add(-5 * time.Hour)},
+		{Number: 3, Title: "newest", CreatedAt: now.Add(-10 * time.Minute)},
+		{Number: 1, Title: "oldest", CreatedAt: now.Add(-72 * time.Hour)},
+	}
+	lines := strings.Split(formatPRAges(prs), "\n")
+	for i, want := range []string{"oldest", "middle", "newest"} {
+		if !strings.Contains(lines[i], want) {
+			t.Errorf("line %d = %q, want %s", i, lines[i], want)
+		}
+	}
+	if !strings.HasSuffix(lines[0], "(3d)") {
+		t.Errorf("oldest age in %q, want 3d", lines[0])
+	}
+	if prs[0].Title != "middle" {
+		t.Error("formatPRAges reordered its argument")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"slices"
+	"strings"
+)
+
+var ghPRIgnoreDrafts = false       // os.Getenv("GH_PR_IGNORE_DRAFTS") == "true"
+var ghPRIgnoreLabels = []string{}  // os.Getenv("GH_PR_IGNORE_LABELS") // comma-separated, e.g. "wip,on-hold"
+var ghPRIgnoreAuthors = []string{} // os.Getenv("GH_PR_IGNORE_AUTHORS") // comma-separated, e.g. "dependabot[bot],renovate[bot]"
+var ghPRIgnoredMode = "exclude"    // os.Getenv("GH_PR_IGNORED_MODE") // exclude, color
+var ghIgnoredPRState = "none"      // "open" when ignored PRs are shown in their own color
+
+var ghPRBotsSeparate = false                                                                // os.Getenv("GH_PR_BOTS_SEPARATE") == "true"
+var ghPRBotAuthors = []string{"dependabot[bot]", "renovate[bot]", "dependabot", "renovate"} // os.Getenv("GH_PR_BOT_AUTHORS") // comma-separated
+var ghBotPRState = "none"                                                                   // "open" when dependency update PRs are queued
+
+// isIgnoredPullRequest reports whether the PR matches any of the draft, label or author filters
+func isIgnoredPullRequest(pr PullRequest) bool {
+	if ghPRIgnoreDrafts && pr.Draft {
+		return true
+	}
+	if slices.ContainsFunc(ghPRIgnoreAuthors, func(a string) bool { return strings.EqualFold(a, pr.User.Login) }) {
+		return true
+	}
+	for _, label := range pr.Labels {
+		if slices.ContainsFunc(ghPRIgnoreLabels, func(l string) bool { return strings.EqualFold(l, label.Name) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterPullRequests splits prs into those that drive the "PRs open" state and those that are ignored
+func filterPullRequests(prs []PullRequest) (kept []PullRequest, ignored []PullRequest) {
+	for _, pr := range prs {
+		if isIgnoredPullRequest(pr) {
+			ignored = append(ignored, pr)
+		} else {
+			kept = append(kept, pr)
+		}
+	}
+	return kept, ignored
+}
+
+// isBotPullRequest reports whether the PR was opened by a dependency update bot
+func isBotPullRequest(pr PullRequest) bool {
+	return slices.ContainsFunc(ghPRBotAuthors, func(a string) bool { return strings.EqualFold(a, pr.User.Login) })
+}
+
+// splitBotPullRequests splits prs into those needing human review and routine dependency updates
+func splitBotPullRequests(prs []PullRequest) (human []PullRequest, bots []PullRequest) {
+	for _, pr := range prs {
+		if isBotPullRequest(pr) {
+			bots = append(bots, pr)
+		} else {
+			human = append(human, pr)
+		}
+	}
+	return human, bots
+}
+
+// splitList parses a comma-separated environment value into trimmed, non-empty entries
+func splitList(value string) []string {
+	var list []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
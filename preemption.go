@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var preemptionThreshold = 5      // os.Getenv("PREEMPTION_THRESHOLD") // warn when more pods than this are preempted per window
+var preemptionWindowMinutes = 10 // os.Getenv("PREEMPTION_WINDOW_MINUTES")
+
+// checkPreemptions counts pods preempted by higher priority pods within the window. Preempted
+// pods usually reschedule, so a spike is the only sign the cluster is over-committed.
+func checkPreemptions(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{FieldSelector: "reason=Preempted"})
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+		return nil
+	}
+
+	since := time.Now().Add(-time.Duration(preemptionWindowMinutes) * time.Minute)
+	preempted := map[string]bool{}
+	for _, e := range events.Items {
+		if eventLastSeen(e).Before(since) {
+			continue
+		}
+		preempted[e.Namespace+"/"+e.InvolvedObject.Name] = true
+	}
+
+	if len(preempted) <= preemptionThreshold {
+		return nil
+	}
+	message := fmt.Sprintf("%d pods preempted in the last %d minutes (threshold %d), the cluster is over-committed", len(preempted), preemptionWindowMinutes, preemptionThreshold)
+	return []Issue{{Key: "preemptions", Type: "Preemption", Severity: "warning", Message: message, Timestamp: time.Now()}}
+}
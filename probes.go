@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// ProbesConfig configures the synthetic HTTP(S)/TCP probes
+type ProbesConfig struct {
+	Interval int           `yaml:"interval"` // seconds between probe rounds, default 30
+	Targets  []ProbeTarget `yaml:"targets"`
+}
+
+// ProbeTarget is a single endpoint probed over HTTP(S) (URL) or TCP (TCP)
+type ProbeTarget struct {
+	Name               string `yaml:"name"`
+	URL                string `yaml:"url"`                  // http(s):// endpoint
+	TCP                string `yaml:"tcp"`                  // host:port, used when URL is empty
+	ExpectedStatus     int    `yaml:"expected_status"`      // default any 2xx/3xx
+	LatencyThresholdMs int    `yaml:"latency_threshold_ms"` // 0 disables the latency check
+	TLSExpiryDays      int    `yaml:"tls_expiry_days"`      // warn when the https certificate expires within this many days, 0 disables
+	TimeoutSeconds     int    `yaml:"timeout"`              // default 10
+	Severity           string `yaml:"severity"`             // severity of a failed probe, default critical
+}
+
+// validateProbes checks that every target has a unique name and either a http(s) URL or a
+// host:port to connect to
+func validateProbes() configProblems {
+	var problems configProblems
+	names := map[string]bool{}
+	for i, target := range config.Probes.Targets {
+		if target.Name == "" || names[target.Name] {
+			problems.addField("targets", i, "probe names must be set and unique, got %q", target.Name)
+		}
+		names[target.Name] = true
+		switch {
+		case target.URL != "":
+			if u, err := url.Parse(target.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems.addField("targets", i, "probe %q: invalid url %q", target.Name, target.URL)
+			}
+		case target.TCP != "":
+			if _, _, err := net.SplitHostPort(target.TCP); err != nil {
+				problems.addField("targets", i, "probe %q: tcp must be host:port, got %q", target.Name, target.TCP)
+			}
+		default:
+			problems.addField("targets", i, "probe %q: set url or tcp", target.Name)
+		}
+		if target.ExpectedStatus != 0 && (target.ExpectedStatus < 100 || target.ExpectedStatus > 599) {
+			problems.addField("targets", i, "probe %q: invalid expected_status %d", target.Name, target.ExpectedStatus)
+		}
+		switch target.Severity {
+		case "", "critical", "warning", "info":
+		default:
+			problems.addField("targets", i, "probe %q: unknown severity %q", target.Name, target.Severity)
+		}
+	}
+	return problems
+}
+
+// checkProbes runs every configured probe concurrently and reports failures, slow responses
+// and expiring certificates as issues
+func checkProbes(ctx context.Context, _ kubernetes.Interface) []Issue {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var issues []Issue
+
+	for _, target := range config.Probes.Targets {
+		wg.Add(1)
+		go func(target ProbeTarget) {
+			defer wg.Done()
+			found := runProbe(ctx, target)
+			mu.Lock()
+			issues = append(issues, found...)
+			mu.Unlock()
+		}(target)
+	}
+	wg.Wait()
+	return issues
+}
+
+// runProbe probes a single target and returns its issues
+func runProbe(ctx context.Context, target ProbeTarget) []Issue {
+	timeout := time.Duration(target.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	severity := target.Severity
+	if severity == "" {
+		severity = "critical"
+	}
+	key := "probe/" + target.Name
+	failed := func(format string, args ...interface{}) []Issue {
+		return []Issue{{Key: key, Type: "Probe", Severity: severity, Message: fmt.Sprintf("Probe %s: %s", target.Name, fmt.Sprintf(format, args...)), Timestamp: time.Now()}}
+	}
+
+	start := time.Now()
+	var certExpiry time.Time
+
+	if target.URL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", target.URL, nil)
+		if err != nil {
+			return failed("invalid URL: %v", err)
+		}
+		client := *httpClient("probes")
+		client.Timeout = timeout
+		resp, err := client.Do(req)
+		if err != nil {
+			return failed("request failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if target.ExpectedStatus != 0 && resp.StatusCode != target.ExpectedStatus {
+			return failed("returned %s, expected %d", resp.Status, target.ExpectedStatus)
+		}
+		if target.ExpectedStatus == 0 && resp.StatusCode >= 400 {
+			return failed("returned %s", resp.Status)
+		}
+		if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+			certExpiry = resp.TLS.PeerCertificates[0].NotAfter
+		}
+	} else {
+		conn, err := (&net.Dialer{Timeout: timeout}).DialContext(ctx, "tcp", target.TCP)
+		if err != nil {
+			return failed("connection failed: %v", err)
+		}
+		conn.Close()
+	}
+	latency := time.Since(start)
+
+	var issues []Issue
+	if target.LatencyThresholdMs > 0 && latency > time.Duration(target.LatencyThresholdMs)*time.Millisecond {
+		issues = append(issues, Issue{Key: key + "/latency", Type: "Probe", Severity: "warning", Message: fmt.Sprintf("Probe %s: slow response %s (threshold %dms)", target.Name, latency.Round(time.Millisecond), target.LatencyThresholdMs), Timestamp: time.Now()})
+	}
+	if target.TLSExpiryDays > 0 && !certExpiry.IsZero() && time.Until(certExpiry) < time.Duration(target.TLSExpiryDays)*24*time.Hour {
+		issues = append(issues, Issue{Key: key + "/tls", Type: "Probe", Severity: "warning", Message: fmt.Sprintf("Probe %s: TLS certificate expires %s", target.Name, certExpiry.Format(time.RFC3339)), Timestamp: time.Now()})
+	}
+	return issues
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// Profile is a team's view of the cluster: the issues of its namespaces and the PRs of its
+// repositories drive its own light and ntfy topic. Profiles are evaluated from the same cluster
+// and SCM results as the main bulb, so they cost no extra API calls.
+type Profile struct {
+	issueGroup `yaml:",inline"`
+
+	Repos     []string `yaml:"repos"`      // repository name patterns whose PRs count, none by default
+	NtfyTopic string   `yaml:"ntfy_topic"` // ntfy topic notified on state changes, optional
+}
+
+// validateProfiles checks the names, patterns, selectors and lights of the profiles
+func validateProfiles() configProblems {
+	var problems configProblems
+	seen := map[string]bool{}
+	for i := range config.Profiles {
+		p := &config.Profiles[i]
+		p.validate(&problems, i, "profile", seen, p.Repos...)
+	}
+	return problems
+}
+
+// prRepo returns the repository name of a PR issue from its key, pr/<repo>/<number> with
+// GH_ORG, or the configured repository for single repository keys
+func prRepo(issue Issue) string {
+	parts := strings.Split(issue.Key, "/")
+	if len(parts) >= 3 {
+		return parts[1]
+	}
+	return ghRepo
+}
+
+// updateProfiles composes the state of every profile from its share of the issues and notifies
+// its topic when the state changes
+func updateProfiles(nodeIssues, podIssues, eventIssues, checkIssues, prIssues []Issue) {
+	for i := range config.Profiles {
+		p := &config.Profiles[i]
+		inputs := p.inputs(nodeIssues, podIssues, eventIssues, checkIssues)
+		if slices.ContainsFunc(prIssues, func(issue Issue) bool { return issue.Type == "PullRequest" && matchesAny(p.Repos, prRepo(issue)) }) {
+			inputs.PRState = "open"
+		}
+
+		state := composeState(inputs)
+		if state.String() != p.state.String() {
+			log.Printf("Profile %s state: %s -> %s", p.Name, p.state, state)
+			if p.state != nil {
+				notifyProfile(p, state)
+			}
+		}
+		p.state = state
+	}
+}
+
+// notifyProfile sends the new state of the profile to its ntfy topic
+func notifyProfile(p *Profile, state ClusterState) {
+	if p.NtfyTopic == "" {
+		return
+	}
+	added, removed := diffStates(p.state, state)
+	var lines []string
+	for _, signal := range added {
+		lines = append(lines, "+ "+string(signal))
+	}
+	for _, signal := range removed {
+		lines = append(lines, "- "+string(signal))
+	}
+	priority := 3
+	if slices.Contains(added, SignalIssuesDetected) {
+		priority = 4
+	}
+	ntfyOpts := NtfyOptions{
+		Topic:    p.NtfyTopic,
+		Title:    fmt.Sprintf("%s: %s", p.Name, state),
+		Priority: priority, // (required)
+		Key:      "profile/" + p.Name,
+		Active:   func() bool { return slices.ContainsFunc(added, p.state.Has) },
+	}
+	if err := SendNtfyAlert(strings.Join(lines, "\n"), ntfyOpts); err != nil {
+		log.Printf("Error sending ntfy alert: %v", err)
+	}
+}
+
+// profileStates returns the state of every profile for the report
+func profileStates() map[string]string {
+	if len(config.Profiles) == 0 {
+		return nil
+	}
+	states := map[string]string{}
+	for _, p := range config.Profiles {
+		states[p.Name] = p.state.String()
+	}
+	return states
+}
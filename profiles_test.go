@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestUpdateProfiles(t *testing.T) {
+	defer func(c Config) { config = c }(config)
+	config.Profiles = []Profile{
+		{issueGroup: issueGroup{Name: "team-a", Namespaces: []string{"team-a-*"}}, Repos: []string{"api"}},
+		{issueGroup: issueGroup{Name: "team-b", Namespaces: []string{"team-b"}, Nodes: true}},
+	}
+
+	pods := []Issue{{Key: "pod/team-a-prod/web-0", Type: "Pod", Namespace: "team-a-prod"}}
+	prs := []Issue{{Key: "pr/api/12", Type: "PullRequest"}, {Key: "pr/web/3", Type: "PullRequest"}}
+	updateProfiles(nil, pods, nil, nil, prs)
+	if got := profileStates(); got["team-a"] != "pull_requests_open|issues_detected" || got["team-b"] != "healthy" {
+		t.Errorf("profile states = %v", got)
+	}
+
+	updateProfiles([]Issue{{Key: "node/a", Type: "Node"}}, nil, nil, nil, nil)
+	if got := profileStates(); got["team-a"] != "healthy" || got["team-b"] != "issues_detected" {
+		t.Errorf("profile states after the pods recovered = %v", got)
+	}
+}
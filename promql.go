@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// PrometheusConfig configures the PromQL threshold checks
+type PrometheusConfig struct {
+	URL      string        `yaml:"url"`      // e.g. http://prometheus.monitoring:9090
+	Interval int           `yaml:"interval"` // seconds between evaluations, default 60
+	Queries  []PromQLCheck `yaml:"queries"`
+}
+
+// PromQLCheck raises an issue for every series of Query whose value breaches Threshold
+type PromQLCheck struct {
+	Name      string  `yaml:"name"`
+	Query     string  `yaml:"query"`
+	Operator  string  `yaml:"operator"` // <, <=, >, >=, ==, !=
+	Threshold float64 `yaml:"threshold"`
+	Severity  string  `yaml:"severity"` // critical, warning (default), info
+	Message   string  `yaml:"message"`  // optional description used in the issue message
+}
+
+// promqlIssues are the issues of each check's last successful query, by check name, kept while
+// its query fails
+var promqlIssues = map[string][]Issue{}
+
+// PromQueryResult represents a Prometheus instant query response
+type PromQueryResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"` // vector, scalar
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// PromSample is a single series of an instant vector
+type PromSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  [2]interface{}    `json:"value"` // [unix time, "value"]
+}
+
+// validatePrometheus checks that every query has a unique name, a query and a known operator
+func validatePrometheus() configProblems {
+	var problems configProblems
+	names := map[string]bool{}
+	for i, check := range config.Prometheus.Queries {
+		if check.Name == "" || names[check.Name] {
+			problems.addField("queries", i, "query names must be set and unique, got %q", check.Name)
+		}
+		names[check.Name] = true
+		if strings.TrimSpace(check.Query) == "" {
+			problems.addField("queries", i, "query %q: query is required", check.Name)
+		}
+		if !slices.Contains([]string{"<", "<=", ">", ">=", "==", "!="}, check.Operator) {
+			problems.addField("queries", i, "query %q: unknown operator %q, expected <, <=, >, >=, == or !=", check.Name, check.Operator)
+		}
+		switch check.Severity {
+		case "", "critical", "warning", "info":
+		default:
+			problems.addField("queries", i, "query %q: unknown severity %q", check.Name, check.Severity)
+		}
+	}
+	if len(config.Prometheus.Queries) > 0 && config.Prometheus.URL == "" {
+		problems.add(-1, "queries need a url")
+	}
+	return problems
+}
+
+// checkPromQL evaluates each configured PromQL query and reports the series breaching their
+// threshold. A failed query keeps the issues of its last successful evaluation, so a Prometheus
+// outage doesn't look healthy.
+func checkPromQL(ctx context.Context, _ kubernetes.Interface) []Issue {
+	if !integrationReady("prometheus") {
+		var issues []Issue
+		for _, check := range config.Prometheus.Queries {
+			issues = append(issues, promqlIssues[check.Name]...)
+		}
+		return issues
+	}
+
+	cycle := &integrationCycle{integration: "prometheus"}
+	defer cycle.done()
+	var issues []Issue
+	last := map[string][]Issue{}
+	for _, check := range config.Prometheus.Queries {
+		result, err := promQuery(ctx, check.Query)
+		if err != nil {
+			cycle.fail(fmt.Sprintf("Error evaluating PromQL check %s:", check.Name), err)
+			last[check.Name] = promqlIssues[check.Name]
+			issues = append(issues, promqlIssues[check.Name]...)
+			continue
+		}
+
+		for _, series := range result {
+			s, ok := series.Value[1].(string)
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseFloat(s, 64)
+			if err != nil || !compareThreshold(value, check.Operator, check.Threshold) {
+				continue
+			}
+
+			labels := formatLabels(series.Metric)
+			msg := check.Message
+			if msg == "" {
+				msg = check.Query
+			}
+			severity := check.Severity
+			if severity == "" {
+				severity = "warning"
+			}
+			issues = append(issues, Issue{
+				Key:       fmt.Sprintf("promql/%s%s", check.Name, labels),
+				Type:      "PromQL",
+				Severity:  severity,
+				Message:   fmt.Sprintf("%s%s: %s (%g %s %g)", check.Name, labels, msg, value, check.Operator, check.Threshold),
+				Timestamp: time.Now(),
+				Namespace: series.Metric["namespace"],
+			})
+			last[check.Name] = append(last[check.Name], issues[len(issues)-1])
+		}
+	}
+	promqlIssues = last
+	return issues
+}
+
+// promQuery runs an instant query against the configured Prometheus and returns its samples
+func promQuery(ctx context.Context, query string) ([]PromSample, error) {
+	u := fmt.Sprintf("%s/api/v1/query?query=%s", strings.TrimRight(config.Prometheus.URL, "/"), url.QueryEscape(query))
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	var result PromQueryResult
+	if err := httpGetJSON("Prometheus", req, &result); err != nil {
+		return nil, err
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s", result.Error)
+	}
+
+	// A scalar result is a single unlabeled value
+	var samples []PromSample
+	switch result.Data.ResultType {
+	case "vector":
+		if err := json.Unmarshal(result.Data.Result, &samples); err != nil {
+			return nil, fmt.Errorf("failed to decode vector: %w", err)
+		}
+	case "scalar":
+		var sample PromSample
+		if err := json.Unmarshal(result.Data.Result, &sample.Value); err != nil {
+			return nil, fmt.Errorf("failed to decode scalar: %w", err)
+		}
+		samples = append(samples, sample)
+	default:
+		return nil, fmt.Errorf("unsupported result type %s", result.Data.ResultType)
+	}
+	return samples, nil
+}
+
+// compareThreshold applies a comparison operator to value and threshold
+func compareThreshold(value float64, operator string, threshold float64) bool {
+	switch operator {
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+// formatLabels renders series labels as {k="v",...} in a stable order, or "" when there are none
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var pairs []string
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", k, v))
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,772 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: proto/clusterbulb/v1/status.proto
+
+package clusterbulbv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{0}
+}
+
+type Issue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Severity      string                 `protobuf:"bytes,3,opt,name=severity,proto3" json:"severity,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Namespace     string                 `protobuf:"bytes,6,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Kind          string                 `protobuf:"bytes,7,opt,name=kind,proto3" json:"kind,omitempty"`
+	Name          string                 `protobuf:"bytes,8,opt,name=name,proto3" json:"name,omitempty"`
+	Owner         string                 `protobuf:"bytes,9,opt,name=owner,proto3" json:"owner,omitempty"`
+	Node          string                 `protobuf:"bytes,10,opt,name=node,proto3" json:"node,omitempty"`
+	Reason        string                 `protobuf:"bytes,11,opt,name=reason,proto3" json:"reason,omitempty"`
+	FirstSeen     *timestamppb.Timestamp `protobuf:"bytes,12,opt,name=first_seen,json=firstSeen,proto3" json:"first_seen,omitempty"`
+	LastSeen      *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Count         int32                  `protobuf:"varint,14,opt,name=count,proto3" json:"count,omitempty"`
+	Runbook       string                 `protobuf:"bytes,15,opt,name=runbook,proto3" json:"runbook,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Issue) Reset() {
+	*x = Issue{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Issue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Issue) ProtoMessage() {}
+
+func (x *Issue) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Issue.ProtoReflect.Descriptor instead.
+func (*Issue) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Issue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Issue) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Issue) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Issue) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Issue) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Issue) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Issue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Issue) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Issue) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Issue) GetNode() string {
+	if x != nil {
+		return x.Node
+	}
+	return ""
+}
+
+func (x *Issue) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Issue) GetFirstSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstSeen
+	}
+	return nil
+}
+
+func (x *Issue) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Issue) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *Issue) GetRunbook() string {
+	if x != nil {
+		return x.Runbook
+	}
+	return ""
+}
+
+type IntegrationHealth struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	ConsecutiveFailures int32                  `protobuf:"varint,1,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	Degraded            bool                   `protobuf:"varint,2,opt,name=degraded,proto3" json:"degraded,omitempty"`
+	LastError           string                 `protobuf:"bytes,3,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	LastFailure         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=last_failure,json=lastFailure,proto3" json:"last_failure,omitempty"`
+	LastSuccess         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_success,json=lastSuccess,proto3" json:"last_success,omitempty"`
+	RetryAfter          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *IntegrationHealth) Reset() {
+	*x = IntegrationHealth{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntegrationHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntegrationHealth) ProtoMessage() {}
+
+func (x *IntegrationHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntegrationHealth.ProtoReflect.Descriptor instead.
+func (*IntegrationHealth) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *IntegrationHealth) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *IntegrationHealth) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *IntegrationHealth) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *IntegrationHealth) GetLastFailure() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFailure
+	}
+	return nil
+}
+
+func (x *IntegrationHealth) GetLastSuccess() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSuccess
+	}
+	return nil
+}
+
+func (x *IntegrationHealth) GetRetryAfter() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+type ComponentHealth struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	State         string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ComponentHealth) Reset() {
+	*x = ComponentHealth{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ComponentHealth) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ComponentHealth) ProtoMessage() {}
+
+func (x *ComponentHealth) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ComponentHealth.ProtoReflect.Descriptor instead.
+func (*ComponentHealth) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ComponentHealth) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ComponentHealth) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *ComponentHealth) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type Report struct {
+	state          protoimpl.MessageState        `protogen:"open.v1"`
+	Timestamp      *timestamppb.Timestamp        `protobuf:"bytes,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ClusterState   string                        `protobuf:"bytes,2,opt,name=cluster_state,json=clusterState,proto3" json:"cluster_state,omitempty"`
+	CiState        string                        `protobuf:"bytes,3,opt,name=ci_state,json=ciState,proto3" json:"ci_state,omitempty"`
+	TotalIssues    int32                         `protobuf:"varint,4,opt,name=total_issues,json=totalIssues,proto3" json:"total_issues,omitempty"`
+	Score          int32                         `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	OpenPrCount    int32                         `protobuf:"varint,6,opt,name=open_pr_count,json=openPrCount,proto3" json:"open_pr_count,omitempty"`
+	NodeIssues     []*Issue                      `protobuf:"bytes,7,rep,name=node_issues,json=nodeIssues,proto3" json:"node_issues,omitempty"`
+	PodIssues      []*Issue                      `protobuf:"bytes,8,rep,name=pod_issues,json=podIssues,proto3" json:"pod_issues,omitempty"`
+	EventIssues    []*Issue                      `protobuf:"bytes,9,rep,name=event_issues,json=eventIssues,proto3" json:"event_issues,omitempty"`
+	CheckIssues    []*Issue                      `protobuf:"bytes,10,rep,name=check_issues,json=checkIssues,proto3" json:"check_issues,omitempty"`
+	PullRequests   []*Issue                      `protobuf:"bytes,11,rep,name=pull_requests,json=pullRequests,proto3" json:"pull_requests,omitempty"`
+	SecurityAlerts []*Issue                      `protobuf:"bytes,12,rep,name=security_alerts,json=securityAlerts,proto3" json:"security_alerts,omitempty"`
+	Incidents      []*Issue                      `protobuf:"bytes,13,rep,name=incidents,proto3" json:"incidents,omitempty"`
+	CveSummary     string                        `protobuf:"bytes,14,opt,name=cve_summary,json=cveSummary,proto3" json:"cve_summary,omitempty"`
+	Integrations   map[string]*IntegrationHealth `protobuf:"bytes,15,rep,name=integrations,proto3" json:"integrations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Profiles       map[string]string             `protobuf:"bytes,16,rep,name=profiles,proto3" json:"profiles,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Components     []*ComponentHealth            `protobuf:"bytes,17,rep,name=components,proto3" json:"components,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Report) Reset() {
+	*x = Report{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Report) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Report) ProtoMessage() {}
+
+func (x *Report) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Report.ProtoReflect.Descriptor instead.
+func (*Report) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Report) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Report) GetClusterState() string {
+	if x != nil {
+		return x.ClusterState
+	}
+	return ""
+}
+
+func (x *Report) GetCiState() string {
+	if x != nil {
+		return x.CiState
+	}
+	return ""
+}
+
+func (x *Report) GetTotalIssues() int32 {
+	if x != nil {
+		return x.TotalIssues
+	}
+	return 0
+}
+
+func (x *Report) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Report) GetOpenPrCount() int32 {
+	if x != nil {
+		return x.OpenPrCount
+	}
+	return 0
+}
+
+func (x *Report) GetNodeIssues() []*Issue {
+	if x != nil {
+		return x.NodeIssues
+	}
+	return nil
+}
+
+func (x *Report) GetPodIssues() []*Issue {
+	if x != nil {
+		return x.PodIssues
+	}
+	return nil
+}
+
+func (x *Report) GetEventIssues() []*Issue {
+	if x != nil {
+		return x.EventIssues
+	}
+	return nil
+}
+
+func (x *Report) GetCheckIssues() []*Issue {
+	if x != nil {
+		return x.CheckIssues
+	}
+	return nil
+}
+
+func (x *Report) GetPullRequests() []*Issue {
+	if x != nil {
+		return x.PullRequests
+	}
+	return nil
+}
+
+func (x *Report) GetSecurityAlerts() []*Issue {
+	if x != nil {
+		return x.SecurityAlerts
+	}
+	return nil
+}
+
+func (x *Report) GetIncidents() []*Issue {
+	if x != nil {
+		return x.Incidents
+	}
+	return nil
+}
+
+func (x *Report) GetCveSummary() string {
+	if x != nil {
+		return x.CveSummary
+	}
+	return ""
+}
+
+func (x *Report) GetIntegrations() map[string]*IntegrationHealth {
+	if x != nil {
+		return x.Integrations
+	}
+	return nil
+}
+
+func (x *Report) GetProfiles() map[string]string {
+	if x != nil {
+		return x.Profiles
+	}
+	return nil
+}
+
+func (x *Report) GetComponents() []*ComponentHealth {
+	if x != nil {
+		return x.Components
+	}
+	return nil
+}
+
+type StateChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Added         []string               `protobuf:"bytes,3,rep,name=added,proto3" json:"added,omitempty"`
+	Removed       []string               `protobuf:"bytes,4,rep,name=removed,proto3" json:"removed,omitempty"`
+	At            *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=at,proto3" json:"at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StateChange) Reset() {
+	*x = StateChange{}
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StateChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StateChange) ProtoMessage() {}
+
+func (x *StateChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_clusterbulb_v1_status_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StateChange.ProtoReflect.Descriptor instead.
+func (*StateChange) Descriptor() ([]byte, []int) {
+	return file_proto_clusterbulb_v1_status_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StateChange) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *StateChange) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *StateChange) GetAdded() []string {
+	if x != nil {
+		return x.Added
+	}
+	return nil
+}
+
+func (x *StateChange) GetRemoved() []string {
+	if x != nil {
+		return x.Removed
+	}
+	return nil
+}
+
+func (x *StateChange) GetAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.At
+	}
+	return nil
+}
+
+var File_proto_clusterbulb_v1_status_proto protoreflect.FileDescriptor
+
+const file_proto_clusterbulb_v1_status_proto_rawDesc = "" +
+	"\n" +
+	"!proto/clusterbulb/v1/status.proto\x12\x0eclusterbulb.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\xc9\x03\n" +
+	"\x05Issue\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1a\n" +
+	"\bseverity\x18\x03 \x01(\tR\bseverity\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x128\n" +
+	"\ttimestamp\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1c\n" +
+	"\tnamespace\x18\x06 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04kind\x18\a \x01(\tR\x04kind\x12\x12\n" +
+	"\x04name\x18\b \x01(\tR\x04name\x12\x14\n" +
+	"\x05owner\x18\t \x01(\tR\x05owner\x12\x12\n" +
+	"\x04node\x18\n" +
+	" \x01(\tR\x04node\x12\x16\n" +
+	"\x06reason\x18\v \x01(\tR\x06reason\x129\n" +
+	"\n" +
+	"first_seen\x18\f \x01(\v2\x1a.google.protobuf.TimestampR\tfirstSeen\x127\n" +
+	"\tlast_seen\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x14\n" +
+	"\x05count\x18\x0e \x01(\x05R\x05count\x12\x18\n" +
+	"\arunbook\x18\x0f \x01(\tR\arunbook\"\xbc\x02\n" +
+	"\x11IntegrationHealth\x121\n" +
+	"\x14consecutive_failures\x18\x01 \x01(\x05R\x13consecutiveFailures\x12\x1a\n" +
+	"\bdegraded\x18\x02 \x01(\bR\bdegraded\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x03 \x01(\tR\tlastError\x12=\n" +
+	"\flast_failure\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\vlastFailure\x12=\n" +
+	"\flast_success\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\vlastSuccess\x12;\n" +
+	"\vretry_after\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"retryAfter\"S\n" +
+	"\x0fComponentHealth\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\"\x85\b\n" +
+	"\x06Report\x128\n" +
+	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12#\n" +
+	"\rcluster_state\x18\x02 \x01(\tR\fclusterState\x12\x19\n" +
+	"\bci_state\x18\x03 \x01(\tR\aciState\x12!\n" +
+	"\ftotal_issues\x18\x04 \x01(\x05R\vtotalIssues\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x05R\x05score\x12\"\n" +
+	"\ropen_pr_count\x18\x06 \x01(\x05R\vopenPrCount\x126\n" +
+	"\vnode_issues\x18\a \x03(\v2\x15.clusterbulb.v1.IssueR\n" +
+	"nodeIssues\x124\n" +
+	"\n" +
+	"pod_issues\x18\b \x03(\v2\x15.clusterbulb.v1.IssueR\tpodIssues\x128\n" +
+	"\fevent_issues\x18\t \x03(\v2\x15.clusterbulb.v1.IssueR\veventIssues\x128\n" +
+	"\fcheck_issues\x18\n" +
+	" \x03(\v2\x15.clusterbulb.v1.IssueR\vcheckIssues\x12:\n" +
+	"\rpull_requests\x18\v \x03(\v2\x15.clusterbulb.v1.IssueR\fpullRequests\x12>\n" +
+	"\x0fsecurity_alerts\x18\f \x03(\v2\x15.clusterbulb.v1.IssueR\x0esecurityAlerts\x123\n" +
+	"\tincidents\x18\r \x03(\v2\x15.clusterbulb.v1.IssueR\tincidents\x12\x1f\n" +
+	"\vcve_summary\x18\x0e \x01(\tR\n" +
+	"cveSummary\x12L\n" +
+	"\fintegrations\x18\x0f \x03(\v2(.clusterbulb.v1.Report.IntegrationsEntryR\fintegrations\x12@\n" +
+	"\bprofiles\x18\x10 \x03(\v2$.clusterbulb.v1.Report.ProfilesEntryR\bprofiles\x12?\n" +
+	"\n" +
+	"components\x18\x11 \x03(\v2\x1f.clusterbulb.v1.ComponentHealthR\n" +
+	"components\x1ab\n" +
+	"\x11IntegrationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x127\n" +
+	"\x05value\x18\x02 \x01(\v2!.clusterbulb.v1.IntegrationHealthR\x05value:\x028\x01\x1a;\n" +
+	"\rProfilesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8d\x01\n" +
+	"\vStateChange\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x14\n" +
+	"\x05added\x18\x03 \x03(\tR\x05added\x12\x18\n" +
+	"\aremoved\x18\x04 \x03(\tR\aremoved\x12*\n" +
+	"\x02at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x02at2\xc3\x02\n" +
+	"\x06Status\x12:\n" +
+	"\tGetReport\x12\x15.clusterbulb.v1.Empty\x1a\x16.clusterbulb.v1.Report\x12J\n" +
+	"\x12StreamStateChanges\x12\x15.clusterbulb.v1.Empty\x1a\x1b.clusterbulb.v1.StateChange0\x01\x12;\n" +
+	"\vAcknowledge\x12\x15.clusterbulb.v1.Empty\x1a\x15.clusterbulb.v1.Empty\x126\n" +
+	"\x06Snooze\x12\x15.clusterbulb.v1.Empty\x1a\x15.clusterbulb.v1.Empty\x12<\n" +
+	"\fTriggerCheck\x12\x15.clusterbulb.v1.Empty\x1a\x15.clusterbulb.v1.EmptyB5Z3go-clusterchecks/proto/clusterbulb/v1;clusterbulbv1b\x06proto3"
+
+var (
+	file_proto_clusterbulb_v1_status_proto_rawDescOnce sync.Once
+	file_proto_clusterbulb_v1_status_proto_rawDescData []byte
+)
+
+func file_proto_clusterbulb_v1_status_proto_rawDescGZIP() []byte {
+	file_proto_clusterbulb_v1_status_proto_rawDescOnce.Do(func() {
+		file_proto_clusterbulb_v1_status_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_clusterbulb_v1_status_proto_rawDesc), len(file_proto_clusterbulb_v1_status_proto_rawDesc)))
+	})
+	return file_proto_clusterbulb_v1_status_proto_rawDescData
+}
+
+var file_proto_clusterbulb_v1_status_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proto_clusterbulb_v1_status_proto_goTypes = []any{
+	(*Empty)(nil),                 // 0: clusterbulb.v1.Empty
+	(*Issue)(nil),                 // 1: clusterbulb.v1.Issue
+	(*IntegrationHealth)(nil),     // 2: clusterbulb.v1.IntegrationHealth
+	(*ComponentHealth)(nil),       // 3: clusterbulb.v1.ComponentHealth
+	(*Report)(nil),                // 4: clusterbulb.v1.Report
+	(*StateChange)(nil),           // 5: clusterbulb.v1.StateChange
+	nil,                           // 6: clusterbulb.v1.Report.IntegrationsEntry
+	nil,                           // 7: clusterbulb.v1.Report.ProfilesEntry
+	(*timestamppb.Timestamp)(nil), // 8: google.protobuf.Timestamp
+}
+var file_proto_clusterbulb_v1_status_proto_depIdxs = []int32{
+	8,  // 0: clusterbulb.v1.Issue.timestamp:type_name -> google.protobuf.Timestamp
+	8,  // 1: clusterbulb.v1.Issue.first_seen:type_name -> google.protobuf.Timestamp
+	8,  // 2: clusterbulb.v1.Issue.last_seen:type_name -> google.protobuf.Timestamp
+	8,  // 3: clusterbulb.v1.IntegrationHealth.last_failure:type_name -> google.protobuf.Timestamp
+	8,  // 4: clusterbulb.v1.IntegrationHealth.last_success:type_name -> google.protobuf.Timestamp
+	8,  // 5: clusterbulb.v1.IntegrationHealth.retry_after:type_name -> google.protobuf.Timestamp
+	8,  // 6: clusterbulb.v1.Report.timestamp:type_name -> google.protobuf.Timestamp
+	1,  // 7: clusterbulb.v1.Report.node_issues:type_name -> clusterbulb.v1.Issue
+	1,  // 8: clusterbulb.v1.Report.pod_issues:type_name -> clusterbulb.v1.Issue
+	1,  // 9: clusterbulb.v1.Report.event_issues:type_name -> clusterbulb.v1.Issue
+	1,  // 10: clusterbulb.v1.Report.check_issues:type_name -> clusterbulb.v1.Issue
+	1,  // 11: clusterbulb.v1.Report.pull_requests:type_name -> clusterbulb.v1.Issue
+	1,  // 12: clusterbulb.v1.Report.security_alerts:type_name -> clusterbulb.v1.Issue
+	1,  // 13: clusterbulb.v1.Report.incidents:type_name -> clusterbulb.v1.Issue
+	6,  // 14: clusterbulb.v1.Report.integrations:type_name -> clusterbulb.v1.Report.IntegrationsEntry
+	7,  // 15: clusterbulb.v1.Report.profiles:type_name -> clusterbulb.v1.Report.ProfilesEntry
+	3,  // 16: clusterbulb.v1.Report.components:type_name -> clusterbulb.v1.ComponentHealth
+	8,  // 17: clusterbulb.v1.StateChange.at:type_name -> google.protobuf.Timestamp
+	2,  // 18: clusterbulb.v1.Report.IntegrationsEntry.value:type_name -> clusterbulb.v1.IntegrationHealth
+	0,  // 19: clusterbulb.v1.Status.GetReport:input_type -> clusterbulb.v1.Empty
+	0,  // 20: clusterbulb.v1.Status.StreamStateChanges:input_type -> clusterbulb.v1.Empty
+	0,  // 21: clusterbulb.v1.Status.Acknowledge:input_type -> clusterbulb.v1.Empty
+	0,  // 22: clusterbulb.v1.Status.Snooze:input_type -> clusterbulb.v1.Empty
+	0,  // 23: clusterbulb.v1.Status.TriggerCheck:input_type -> clusterbulb.v1.Empty
+	4,  // 24: clusterbulb.v1.Status.GetReport:output_type -> clusterbulb.v1.Report
+	5,  // 25: clusterbulb.v1.Status.StreamStateChanges:output_type -> clusterbulb.v1.StateChange
+	0,  // 26: clusterbulb.v1.Status.Acknowledge:output_type -> clusterbulb.v1.Empty
+	0,  // 27: clusterbulb.v1.Status.Snooze:output_type -> clusterbulb.v1.Empty
+	0,  // 28: clusterbulb.v1.Status.TriggerCheck:output_type -> clusterbulb.v1.Empty
+	24, // [24:29] is the sub-list for method output_type
+	19, // [19:24] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
+}
+
+func init() { file_proto_clusterbulb_v1_status_proto_init() }
+func file_proto_clusterbulb_v1_status_proto_init() {
+	if File_proto_clusterbulb_v1_status_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_clusterbulb_v1_status_proto_rawDesc), len(file_proto_clusterbulb_v1_status_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_clusterbulb_v1_status_proto_goTypes,
+		DependencyIndexes: file_proto_clusterbulb_v1_status_proto_depIdxs,
+		MessageInfos:      file_proto_clusterbulb_v1_status_proto_msgTypes,
+	}.Build()
+	File_proto_clusterbulb_v1_status_proto = out.File
+	file_proto_clusterbulb_v1_status_proto_goTypes = nil
+	file_proto_clusterbulb_v1_status_proto_depIdxs = nil
+}
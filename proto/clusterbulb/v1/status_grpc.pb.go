@@ -0,0 +1,277 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/clusterbulb/v1/status.proto
+
+package clusterbulbv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Status_GetReport_FullMethodName          = "/clusterbulb.v1.Status/GetReport"
+	Status_StreamStateChanges_FullMethodName = "/clusterbulb.v1.Status/StreamStateChanges"
+	Status_Acknowledge_FullMethodName        = "/clusterbulb.v1.Status/Acknowledge"
+	Status_Snooze_FullMethodName             = "/clusterbulb.v1.Status/Snooze"
+	Status_TriggerCheck_FullMethodName       = "/clusterbulb.v1.Status/TriggerCheck"
+)
+
+// StatusClient is the client API for Status service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type StatusClient interface {
+	GetReport(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Report, error)
+	StreamStateChanges(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StateChange], error)
+	Acknowledge(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Snooze(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	TriggerCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+}
+
+type statusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStatusClient(cc grpc.ClientConnInterface) StatusClient {
+	return &statusClient{cc}
+}
+
+func (c *statusClient) GetReport(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Report, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Report)
+	err := c.cc.Invoke(ctx, Status_GetReport_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) StreamStateChanges(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StateChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Status_ServiceDesc.Streams[0], Status_StreamStateChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Empty, StateChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Status_StreamStateChangesClient = grpc.ServerStreamingClient[StateChange]
+
+func (c *statusClient) Acknowledge(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Status_Acknowledge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) Snooze(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Status_Snooze_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *statusClient) TriggerCheck(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, Status_TriggerCheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StatusServer is the server API for Status service.
+// All implementations must embed UnimplementedStatusServer
+// for forward compatibility.
+type StatusServer interface {
+	GetReport(context.Context, *Empty) (*Report, error)
+	StreamStateChanges(*Empty, grpc.ServerStreamingServer[StateChange]) error
+	Acknowledge(context.Context, *Empty) (*Empty, error)
+	Snooze(context.Context, *Empty) (*Empty, error)
+	TriggerCheck(context.Context, *Empty) (*Empty, error)
+	mustEmbedUnimplementedStatusServer()
+}
+
+// UnimplementedStatusServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedStatusServer struct{}
+
+func (UnimplementedStatusServer) GetReport(context.Context, *Empty) (*Report, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReport not implemented")
+}
+func (UnimplementedStatusServer) StreamStateChanges(*Empty, grpc.ServerStreamingServer[StateChange]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamStateChanges not implemented")
+}
+func (UnimplementedStatusServer) Acknowledge(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Acknowledge not implemented")
+}
+func (UnimplementedStatusServer) Snooze(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snooze not implemented")
+}
+func (UnimplementedStatusServer) TriggerCheck(context.Context, *Empty) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TriggerCheck not implemented")
+}
+func (UnimplementedStatusServer) mustEmbedUnimplementedStatusServer() {}
+func (UnimplementedStatusServer) testEmbeddedByValue()                {}
+
+// UnsafeStatusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to StatusServer will
+// result in compilation errors.
+type UnsafeStatusServer interface {
+	mustEmbedUnimplementedStatusServer()
+}
+
+func RegisterStatusServer(s grpc.ServiceRegistrar, srv StatusServer) {
+	// If the following call pancis, it indicates UnimplementedStatusServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Status_ServiceDesc, srv)
+}
+
+func _Status_GetReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).GetReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_GetReport_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).GetReport(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_StreamStateChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StatusServer).StreamStateChanges(m, &grpc.GenericServerStream[Empty, StateChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Status_StreamStateChangesServer = grpc.ServerStreamingServer[StateChange]
+
+func _Status_Acknowledge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).Acknowledge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_Acknowledge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).Acknowledge(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_Snooze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).Snooze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_Snooze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).Snooze(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Status_TriggerCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StatusServer).TriggerCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Status_TriggerCheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StatusServer).TriggerCheck(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Status_ServiceDesc is the grpc.ServiceDesc for Status service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Status_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "clusterbulb.v1.Status",
+	HandlerType: (*StatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetReport",
+			Handler:    _Status_GetReport_Handler,
+		},
+		{
+			MethodName: "Acknowledge",
+			Handler:    _Status_Acknowledge_Handler,
+		},
+		{
+			MethodName: "Snooze",
+			Handler:    _Status_Snooze_Handler,
+		},
+		{
+			MethodName: "TriggerCheck",
+			Handler:    _Status_TriggerCheck_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStateChanges",
+			Handler:       _Status_StreamStateChanges_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/clusterbulb/v1/status.proto",
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var provisioningCheck = false       // os.Getenv("PROVISIONING_CHECK") == "true" // compare resource requests with usage
+var opencostUrl = ""                // os.Getenv("OPENCOST_URL") // e.g. http://opencost.opencost:9003, metrics-server is used without it
+var provisioningMinEfficiency = 30  // os.Getenv("PROVISIONING_MIN_EFFICIENCY") // percent of requests used below which the cluster is over-provisioned
+var provisioningMaxEfficiency = 120 // os.Getenv("PROVISIONING_MAX_EFFICIENCY") // percent of requests used above which it is under-provisioned
+
+// resourceEfficiency is the share of the requested CPU and memory that is used, 1 meaning all of it
+type resourceEfficiency struct {
+	CPU    float64
+	Memory float64
+	Source string
+}
+
+// checkProvisioning raises an info issue when the cluster is significantly over- or
+// under-provisioned, from the OpenCost efficiency of the last week or the current
+// metrics-server usage against the pod requests
+func checkProvisioning(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var eff *resourceEfficiency
+	var err error
+	if opencostUrl != "" {
+		eff, err = opencostEfficiency(ctx)
+		if err != nil {
+			HandleError("opencost", "Error querying OpenCost:", err)
+			return nil
+		}
+		integrationOK("opencost")
+	} else {
+		eff, err = metricsServerEfficiency(ctx, clientset)
+		if err != nil {
+			HandleError("metrics-server", "Error comparing requests with usage:", err)
+			return nil
+		}
+		if eff != nil {
+			integrationOK("metrics-server")
+		}
+	}
+	if eff == nil {
+		return nil
+	}
+
+	minEff, maxEff := float64(provisioningMinEfficiency)/100, float64(provisioningMaxEfficiency)/100
+	var verdict string
+	switch {
+	case eff.CPU < minEff && eff.Memory < minEff:
+		verdict = "over-provisioned"
+	case eff.CPU > maxEff || eff.Memory > maxEff:
+		verdict = "under-provisioned"
+	default:
+		return nil
+	}
+	msg := fmt.Sprintf("Cluster significantly %s: %.0f%% of requested CPU and %.0f%% of requested memory used (%s)", verdict, eff.CPU*100, eff.Memory*100, eff.Source)
+	return []Issue{{Key: "provisioning/cluster", Type: "Provisioning", Severity: "info", Message: msg, Timestamp: time.Now(), Reason: verdict}}
+}
+
+// opencostAllocations is the response of the OpenCost allocation API aggregated by cluster
+type opencostAllocations struct {
+	Data []map[string]struct {
+		CPUEfficiency float64 `json:"cpuEfficiency"`
+		RAMEfficiency float64 `json:"ramEfficiency"`
+	} `json:"data"`
+}
+
+// opencostEfficiency returns the CPU and memory efficiency of the cluster over the last week
+func opencostEfficiency(ctx context.Context) (*resourceEfficiency, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", opencostUrl+"/allocation/compute?window=7d&aggregate=cluster&accumulate=true", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	var allocations opencostAllocations
+	if err := httpGetJSON("OpenCost", req, &allocations); err != nil {
+		return nil, err
+	}
+	for _, set := range allocations.Data {
+		for name, a := range set {
+			if name != "__idle__" {
+				return &resourceEfficiency{CPU: a.CPUEfficiency, Memory: a.RAMEfficiency, Source: "OpenCost, last 7 days"}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// PodMetricsList holds the fields of interest of a metrics.k8s.io pod metrics list
+type PodMetricsList struct {
+	Items []struct {
+		Containers []struct {
+			Usage v1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// metricsServerEfficiency compares the current usage reported by metrics-server with the requests
+// of the running pods; it returns nil when metrics-server is not installed
+func metricsServerEfficiency(ctx context.Context, clientset kubernetes.Interface) (*resourceEfficiency, error) {
+	var metrics PodMetricsList
+	found, err := listCustomResources(ctx, clientset, "metrics.k8s.io/v1beta1", "pods", &metrics)
+	if err != nil || !found {
+		return nil, err
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{FieldSelector: "status.phase=Running"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var requested, used v1.ResourceList = v1.ResourceList{}, v1.ResourceList{}
+	add := func(list v1.ResourceList, name v1.ResourceName, q resource.Quantity) {
+		sum := list[name]
+		sum.Add(q)
+		list[name] = sum
+	}
+	for _, pod := range pods.Items {
+		for _, c := range pod.Spec.Containers {
+			add(requested, v1.ResourceCPU, c.Resources.Requests[v1.ResourceCPU])
+			add(requested, v1.ResourceMemory, c.Resources.Requests[v1.ResourceMemory])
+		}
+	}
+	for _, pod := range metrics.Items {
+		for _, c := range pod.Containers {
+			add(used, v1.ResourceCPU, c.Usage[v1.ResourceCPU])
+			add(used, v1.ResourceMemory, c.Usage[v1.ResourceMemory])
+		}
+	}
+
+	cpuRequested, memRequested := requested[v1.ResourceCPU], requested[v1.ResourceMemory]
+	if cpuRequested.IsZero() || memRequested.IsZero() {
+		return nil, nil
+	}
+	cpuUsed, memUsed := used[v1.ResourceCPU], used[v1.ResourceMemory]
+	return &resourceEfficiency{
+		CPU:    float64(cpuUsed.MilliValue()) / float64(cpuRequested.MilliValue()),
+		Memory: float64(memUsed.Value()) / float64(memRequested.Value()),
+		Source: "metrics-server, current usage",
+	}, nil
+}
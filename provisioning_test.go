@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestOpencostEfficiency(t *testing.T) {
+	defer func(u string) { opencostUrl = u }(opencostUrl)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/allocation/compute" || r.URL.Query().Get("window") != "7d" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"data":[{"__idle__":{"cpuEfficiency":0,"ramEfficiency":0},"homelab":{"cpuEfficiency":0.12,"ramEfficiency":0.25}}]}`)
+	}))
+	defer srv.Close()
+	opencostUrl = srv.URL
+
+	eff, err := opencostEfficiency(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eff == nil || eff.CPU != 0.12 || eff.Memory != 0.25 {
+		t.Fatalf("efficiency = %+v, want the cluster allocation", eff)
+	}
+	issues := checkProvisioning(context.Background(), nil)
+	if len(issues) != 1 || issues[0].Reason != "over-provisioned" {
+		t.Errorf("issues = %+v, want over-provisioned", issues)
+	}
+
+	// A failing OpenCost degrades its integration instead of raising an issue
+	delete(integrations, "opencost")
+	opencostUrl = srv.URL + "/missing"
+	if issues := checkProvisioning(context.Background(), nil); len(issues) != 0 {
+		t.Errorf("issues = %+v, want none when OpenCost fails", issues)
+	}
+	if h := integrations["opencost"]; h == nil || h.Failures != 1 {
+		t.Errorf("opencost health = %+v, want one failure", h)
+	}
+}
+
+// metricsServerAPI serves the discovery, pod metrics and pods of a cluster using 2 of the 4
+// requested CPUs and 1 of the 4Gi requested memory
+func metricsServerAPI(t *testing.T, metricsStatus int) *kubernetes.Clientset {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/metrics.k8s.io/v1beta1":
+			fmt.Fprint(w, `{"kind":"APIResourceList","groupVersion":"metrics.k8s.io/v1beta1","resources":[{"name":"pods","namespaced":true,"kind":"PodMetrics","verbs":["get","list"]}]}`)
+		case "/apis/metrics.k8s.io/v1beta1/pods":
+			w.WriteHeader(metricsStatus)
+			fmt.Fprint(w, `{"items":[{"containers":[{"usage":{"cpu":"1500m","memory":"512Mi"}},{"usage":{"cpu":"500m","memory":"512Mi"}}]}]}`)
+		case "/api/v1/pods":
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[{"metadata":{"name":"app","namespace":"default"},"spec":{"containers":[
+				{"name":"a","resources":{"requests":{"cpu":"3","memory":"3Gi"}}},
+				{"name":"b","resources":{"requests":{"cpu":"1","memory":"1Gi"}}}]}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientset
+}
+
+func TestMetricsServerEfficiency(t *testing.T) {
+	eff, err := metricsServerEfficiency(context.Background(), metricsServerAPI(t, http.StatusOK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eff == nil || math.Abs(eff.CPU-0.5) > 0.001 || math.Abs(eff.Memory-0.25) > 0.001 {
+		t.Fatalf("efficiency = %+v, want 50%% CPU and 25%% memory", eff)
+	}
+
+	// A failing metrics API degrades the metrics-server integration instead of raising an issue
+	delete(integrations, "metrics-server")
+	if issues := checkProvisioning(context.Background(), metricsServerAPI(t, http.StatusServiceUnavailable)); len(issues) != 0 {
+		t.Errorf("issues = %+v, want none when metrics-server fails", issues)
+	}
+	if h := integrations["metrics-server"]; h == nil || h.Failures != 1 {
+		t.Errorf("metrics-server health = %+v, want one failure", h)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var rebootRequiredAnnotation = "weave.works/kured-most-recent-reboot-needed" // os.Getenv("REBOOT_REQUIRED_ANNOTATION") // set by kured --annotate-nodes
+var rebootRequiredState Signal                                               // os.Getenv("REBOOT_REQUIRED_STATE") // e.g. reboot_required, empty only reports the nodes
+
+// checkRebootRequired reports nodes carrying the reboot-required annotation, so patch debt is
+// visible. They are info issues unless REBOOT_REQUIRED_STATE gives them their own bulb state.
+func checkRebootRequired(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching nodes: %v", err)
+		return nil
+	}
+
+	severity := "info"
+	if rebootRequiredState != "" {
+		severity = "warning"
+	}
+
+	var issues []Issue
+	for _, node := range nodes.Items {
+		if _, ok := node.Annotations[rebootRequiredAnnotation]; !ok {
+			continue
+		}
+		issues = append(issues, Issue{Key: "reboot/" + node.Name, Type: "RebootRequired", Severity: severity, Message: fmt.Sprintf("Node %s is pending a reboot", node.Name), Timestamp: time.Now()})
+	}
+	return issues
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+)
+
+// recoveryKinds are the improvements that can be notified with NTFY_RECOVERIES
+var recoveryKinds = []string{"prs_closed", "prs_decreased", "ci_recovered"}
+
+var ntfyRecoveries = map[string]bool{} // os.Getenv("NTFY_RECOVERIES") // e.g. prs_closed,ci_recovered or all
+
+// parseNtfyRecoveries parses the comma-separated recovery kinds into ntfyRecoveries
+func parseNtfyRecoveries(value string) error {
+	for _, kind := range splitList(value) {
+		if kind == "all" {
+			for _, k := range recoveryKinds {
+				ntfyRecoveries[k] = true
+			}
+			continue
+		}
+		if !slices.Contains(recoveryKinds, kind) {
+			return fmt.Errorf("unknown recovery %q, expected prs_closed, prs_decreased, ci_recovered or all", kind)
+		}
+		ntfyRecoveries[kind] = true
+	}
+	return nil
+}
+
+// notifyRecovery logs and audits the recovery and sends a low priority notification when its kind
+// is enabled; recoveries during warm-up are not notified since the alerts they follow were not sent either
+func notifyRecovery(kind, title, message string) {
+	log.Printf("Recovery (%s): %s", kind, message)
+	recordAudit("recovery/"+kind, message)
+	if !ntfyRecoveries[kind] || warmingUp() {
+		return
+	}
+	ntfyOpts := NtfyOptions{
+		Title:    title,
+		Priority: 2, // (required)
+		Tags:     "white_check_mark",
+	}
+	if err := SendNtfyAlert(message, ntfyOpts); err != nil {
+		log.Printf("Error sending ntfy alert: %v", err)
+	}
+}
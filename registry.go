@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var registryCheckHosts []string // os.Getenv("REGISTRY_CHECK_HOSTS") // comma-separated registries, e.g. harbor.example.com,ghcr.io
+
+// checkRegistries pings the /v2/ endpoint of every configured container registry, so
+// an unreachable registry shows up before pods start failing with ImagePullBackOff
+func checkRegistries(ctx context.Context, _ kubernetes.Interface) []Issue {
+	var issues []Issue
+	for _, host := range registryCheckHosts {
+		if err := pingRegistry(ctx, host); err != nil {
+			issues = append(issues, Issue{Key: "registry/" + host, Type: "Registry", Severity: "warning", Message: fmt.Sprintf("Registry %s is unreachable: %v", host, err), Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
+
+// pingRegistry sends a GET to the registry's /v2/ endpoint; per the distribution spec
+// both 200 and 401 (authentication required) mean the registry is up
+func pingRegistry(ctx context.Context, host string) error {
+	url := host
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = "https://" + url
+	}
+	url = strings.TrimRight(url, "/") + "/v2/"
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient("registry").Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("/v2/ returned status: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+var haStateRGBW = map[Signal][4]int{} // os.Getenv("HA_STATE_RGBW") // e.g. reboot_required=0,0,0,255;healthy=0,255,0,0
+
+// haColorMode is the color attribute the bulb supports: rgb_color, rgbw_color or rgbww_color,
+// detected from the supported_color_modes of the light entity
+var haColorMode = ""
+var haColorModeCheckedAt time.Time
+
+// parseHAStateRGBW parses HA_STATE_RGBW, semicolon-separated state=r,g,b,w entries, into haStateRGBW
+func parseHAStateRGBW(value string) error {
+	for _, entry := range strings.Split(value, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		state, color, ok := strings.Cut(entry, "=")
+		if !ok {
+			return fmt.Errorf("expected state=r,g,b,w, got %q", entry)
+		}
+		if _, ok := stateColor(Signal(strings.TrimSpace(state))); !ok {
+			return fmt.Errorf("unknown state %q", state)
+		}
+		channels, err := parseChannels(color, 4)
+		if err != nil {
+			return fmt.Errorf("%s: %w", state, err)
+		}
+		haStateRGBW[Signal(strings.TrimSpace(state))] = [4]int(channels)
+	}
+	return nil
+}
+
+// haColorPayload returns the color attribute and value for the signal in the bulb's color mode.
+// RGBW bulbs drive the white channel from the per-state HA_STATE_RGBW value, or from the
+// white share of the rgb color when the state has none.
+func haColorPayload(ctx context.Context, signal Signal, rgb [3]int) (string, []int) {
+	rgbw, ok := haStateRGBW[signal]
+	if !ok {
+		w := min(rgb[0], rgb[1], rgb[2])
+		rgbw = [4]int{rgb[0] - w, rgb[1] - w, rgb[2] - w, w}
+	}
+
+	switch haDetectColorMode(ctx) {
+	case "rgbw_color":
+		return "rgbw_color", rgbw[:]
+	case "rgbww_color":
+		// drive the cold and warm white channels together for a neutral white
+		return "rgbww_color", []int{rgbw[0], rgbw[1], rgbw[2], rgbw[3], rgbw[3]}
+	default:
+		return "rgb_color", rgb[:]
+	}
+}
+
+// haDetectColorMode reads the supported color modes of the light entity once, retrying
+// every minute while Home Assistant is unreachable
+func haDetectColorMode(ctx context.Context) string {
+	// Grouped lights are assumed alike, the first member stands for all of them
+	light, _, _ := strings.Cut(haLightTarget(ctx), ",")
+	if haColorMode != "" || haToken.Get() == "" || haUrl == "" || light == "" {
+		return haColorMode
+	}
+	if time.Since(haColorModeCheckedAt) < time.Minute {
+		return "rgb_color"
+	}
+	haColorModeCheckedAt = time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/states/%s", haUrl, light), nil)
+	if err != nil {
+		log.Printf("Error creating request: %v", err)
+		return "rgb_color"
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", haToken.Get()))
+
+	var entity struct {
+		Attributes struct {
+			SupportedColorModes []string `json:"supported_color_modes"`
+		} `json:"attributes"`
+	}
+	resp, err := httpClient("homeassistant").Do(req)
+	if err != nil {
+		log.Printf("Error reading supported color modes of %s: %v", light, err)
+		return "rgb_color"
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error reading supported color modes of %s: %s", light, resp.Status)
+		return "rgb_color"
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entity); err != nil {
+		log.Printf("Error decoding state of %s: %v", light, err)
+		return "rgb_color"
+	}
+
+	modes := entity.Attributes.SupportedColorModes
+	switch {
+	case slices.Contains(modes, "rgbww"):
+		haColorMode = "rgbww_color"
+	case slices.Contains(modes, "rgbw"):
+		haColorMode = "rgbw_color"
+	default:
+		haColorMode = "rgb_color"
+	}
+	log.Printf("Using %s for %s (supported color modes: %s)", haColorMode, light, strings.Join(modes, ", "))
+	return haColorMode
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var argoRolloutsDashboardUrl = ""       // os.Getenv("ARGO_ROLLOUTS_DASHBOARD_URL") // e.g. http://argo-rollouts.local:3100, links rollouts in notifications
+var rolloutsFailing = map[string]bool{} // rollouts failing on the last run, to notify only once
+
+// RolloutList represents the Argo Rollouts Rollout CRs of the cluster
+type RolloutList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Status struct {
+			Phase           string `json:"phase"` // Healthy, Progressing, Paused, Degraded
+			Message         string `json:"message"`
+			Abort           bool   `json:"abort"`
+			PauseConditions []struct {
+				Reason string `json:"reason"` // CanaryPauseStep, BlueGreenPause, InconclusiveAnalysis, ...
+			} `json:"pauseConditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// checkRollouts reports Argo Rollouts that are degraded, aborted, or paused on an inconclusive
+// analysis, and notifies with a dashboard link when a rollout starts failing
+func checkRollouts(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var rollouts RolloutList
+	found, err := listCustomResources(ctx, clientset, "argoproj.io/v1alpha1", "rollouts", &rollouts)
+	if err != nil {
+		log.Printf("Error fetching rollouts: %v", err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+
+	var issues []Issue
+	failing := map[string]bool{}
+	for _, r := range rollouts.Items {
+		name := r.Metadata.Namespace + "/" + r.Metadata.Name
+		var state string
+		switch {
+		case r.Status.Abort:
+			state = "aborted"
+		case r.Status.Phase == "Degraded":
+			state = "degraded"
+		case r.Status.Phase == "Paused" && len(r.Status.PauseConditions) > 0 && r.Status.PauseConditions[0].Reason == "InconclusiveAnalysis":
+			state = "paused on an inconclusive analysis"
+		default:
+			continue
+		}
+		message := fmt.Sprintf("Rollout %s is %s", name, state)
+		if r.Status.Message != "" {
+			message += ": " + r.Status.Message
+		}
+		failing[name] = true
+		issues = append(issues, Issue{Key: "rollout/" + name, Type: "Rollout", Severity: "warning", Message: message, Timestamp: time.Now(),
+			Namespace: r.Metadata.Namespace, Kind: "Rollout", Name: r.Metadata.Name, Owner: "rollout/" + r.Metadata.Name})
+
+		if !rolloutsFailing[name] {
+			ntfyOpts := NtfyOptions{
+				Title:    fmt.Sprintf("Rollout %s: %s", state, name),
+				Priority: 4, // (required)
+				Active:   func() bool { return rolloutsFailing[name] },
+			}
+			if argoRolloutsDashboardUrl != "" {
+				ntfyOpts.Click = fmt.Sprintf("%s/rollouts/rollout/%s/%s", strings.TrimRight(argoRolloutsDashboardUrl, "/"), r.Metadata.Namespace, r.Metadata.Name)
+			}
+			if err := SendNtfyAlert(message, ntfyOpts); err != nil {
+				log.Printf("Error sending ntfy alert: %v", err)
+			}
+		}
+	}
+	rolloutsFailing = failing
+	return issues
+}
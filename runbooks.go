@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Runbook links issues of a type and/or reason to the page describing what to do about them
+type Runbook struct {
+	Type   string `yaml:"type"`   // issue type, e.g. Pod or Node, empty matches any
+	Reason string `yaml:"reason"` // issue reason, e.g. CrashLoopBackOff, empty matches any
+	URL    string `yaml:"url"`
+}
+
+// validateRunbooks checks every runbook has a URL and something to match
+func validateRunbooks() configProblems {
+	var problems configProblems
+	for i, r := range config.Runbooks {
+		if r.Type == "" && r.Reason == "" {
+			problems.add(i, "runbook %d needs a type or reason", i+1)
+		}
+		if u, err := url.Parse(r.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			problems.add(i, "runbook %d has an invalid url %q", i+1, r.URL)
+		}
+	}
+	return problems
+}
+
+// runbookFor returns the URL of the first runbook matching the issue, or ""
+func runbookFor(issue Issue) string {
+	for _, r := range config.Runbooks {
+		if (r.Type == "" || strings.EqualFold(r.Type, issue.Type)) && (r.Reason == "" || strings.EqualFold(r.Reason, issue.Reason)) {
+			return r.URL
+		}
+	}
+	return ""
+}
+
+// applyRunbooks sets the runbook of the issues in place
+func applyRunbooks(groups ...[]Issue) {
+	if len(config.Runbooks) == 0 {
+		return
+	}
+	for _, issues := range groups {
+		for i := range issues {
+			issues[i].Runbook = runbookFor(issues[i])
+		}
+	}
+}
+
+// runbookLines lists the actionable issues with a runbook for a notification, one line per
+// runbook, and returns the first runbook to open when the notification is tapped
+func runbookLines(issues []Issue) ([]string, string) {
+	var lines []string
+	var first string
+	seen := map[string]bool{}
+	for _, issue := range issues {
+		if issue.Runbook == "" || seen[issue.Runbook] || !isActionable(issue) {
+			continue
+		}
+		if first == "" {
+			first = issue.Runbook
+		}
+		seen[issue.Runbook] = true
+		lines = append(lines, fmt.Sprintf("%s: %s", issue.Message, issue.Runbook))
+	}
+	return lines, first
+}
@@ -0,0 +1,35 @@
+package main
+
+import "testing"
+
+func TestRunbooks(t *testing.T) {
+	config.Runbooks = []Runbook{
+		{Type: "Pod", Reason: "CrashLoopBackOff", URL: "https://wiki/crashloop"},
+		{Type: "node", URL: "https://wiki/node"},
+	}
+	defer func() { config.Runbooks = nil }()
+	if err := validateRunbooks(); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []Issue{
+		{Key: "pod/a/web", Type: "Pod", Reason: "CrashLoopBackOff", Message: "web is crashlooping"},
+		{Key: "pod/a/job", Type: "Pod", Reason: "ImagePullBackOff", Message: "job can't pull"},
+		{Key: "node/n1", Type: "Node", Reason: "NotReady", Message: "n1 is not ready"},
+	}
+	applyRunbooks(issues)
+	for i, want := range []string{"https://wiki/crashloop", "", "https://wiki/node"} {
+		if issues[i].Runbook != want {
+			t.Errorf("%s runbook = %q, want %q", issues[i].Key, issues[i].Runbook, want)
+		}
+	}
+	lines, first := runbookLines(issues)
+	if len(lines) != 2 || first != "https://wiki/crashloop" {
+		t.Errorf("runbook lines %v, first %q", lines, first)
+	}
+
+	config.Runbooks = []Runbook{{URL: "https://wiki/any"}}
+	if validateRunbooks() == nil {
+		t.Error("runbook without type or reason passed validation")
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ScheduleRule changes which signals are shown while its time window is active, e.g. hiding
+// PR signals outside working hours; windows use the local time zone of the process (TZ)
+type ScheduleRule struct {
+	Name   string   `yaml:"name"`
+	Days   []string `yaml:"days"`   // mon, tue, ... sun, default every day
+	From   string   `yaml:"from"`   // HH:MM, default 00:00
+	To     string   `yaml:"to"`     // HH:MM, default 24:00; a window ending before it starts wraps past midnight
+	Hide   []string `yaml:"hide"`   // states dropped while active
+	Only   []string `yaml:"only"`   // if set, every other state is dropped while active
+	Steady []string `yaml:"steady"` // states that don't breathe and leave the blink cycle while other states are active
+}
+
+var scheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// scheduleSteady holds the steady states of the active rules, read by the bulb driver
+var scheduleSteady []Signal
+
+// validateSchedules checks the days, times and states of every rule
+func validateSchedules() configProblems {
+	var problems configProblems
+	for i, rule := range config.Schedules {
+		for _, day := range rule.Days {
+			if _, ok := scheduleDays[strings.ToLower(day)]; !ok {
+				problems.add(i, "schedule %q: unknown day %q", rule.Name, day)
+			}
+		}
+		for _, t := range []string{rule.From, rule.To} {
+			if _, err := scheduleMinutes(t, 0); err != nil {
+				problems.add(i, "schedule %q: %v", rule.Name, err)
+			}
+		}
+		for _, state := range slices.Concat(rule.Hide, rule.Only, rule.Steady) {
+			if !stateKnown(Signal(state)) {
+				problems.add(i, "schedule %q: unknown state %q", rule.Name, state)
+			}
+		}
+	}
+	return problems
+}
+
+// scheduleMinutes parses HH:MM into minutes after midnight, returning def for ""
+func scheduleMinutes(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	var h, m int
+	if _, err := fmt.Sscanf(value, "%d:%d", &h, &m); err != nil || h < 0 || h > 24 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return h*60 + m, nil
+}
+
+// active reports whether the rule applies at now
+func (rule ScheduleRule) active(now time.Time) bool {
+	if len(rule.Days) > 0 && !slices.ContainsFunc(rule.Days, func(day string) bool {
+		return scheduleDays[strings.ToLower(day)] == now.Weekday()
+	}) {
+		return false
+	}
+	from, _ := scheduleMinutes(rule.From, 0)
+	to, _ := scheduleMinutes(rule.To, 24*60)
+	minute := now.Hour()*60 + now.Minute()
+	if to < from {
+		return minute >= from || minute < to
+	}
+	return minute >= from && minute < to
+}
+
+// scheduledState applies the configured schedule rules to the state
+func scheduledState(state ClusterState) ClusterState {
+	return applySchedules(state, config.Schedules, time.Now())
+}
+
+// shownState is the cluster state as the bulb and displays show it. Schedules only change what
+// is shown: the state itself, its history and notifications keep every signal.
+func shownState() ClusterState {
+	return scheduledState(clusterState)
+}
+
+// applySchedules filters the state by the rules active at now and records their steady states
+func applySchedules(state ClusterState, rules []ScheduleRule, now time.Time) ClusterState {
+	scheduleSteady = nil
+	for _, rule := range rules {
+		if !rule.active(now) {
+			continue
+		}
+		state = slices.DeleteFunc(slices.Clone(state), func(s Signal) bool {
+			return slices.Contains(rule.Hide, string(s)) || (len(rule.Only) > 0 && !slices.Contains(rule.Only, string(s)))
+		})
+		for _, s := range rule.Steady {
+			scheduleSteady = append(scheduleSteady, Signal(s))
+		}
+	}
+
+	// Steady states only show while nothing else is active
+	if rest := slices.DeleteFunc(slices.Clone(state), func(s Signal) bool { return slices.Contains(scheduleSteady, s) }); len(rest) > 0 {
+		state = rest
+	}
+	return state
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SCMProvider is a source code host that can be polled for open pull requests
+// and the CI status of a branch
+type SCMProvider interface {
+	// Name returns the provider name used in logs and notifications
+	Name() string
+	// OpenPullRequests returns the currently open pull requests
+	OpenPullRequests(ctx context.Context) ([]PullRequest, error)
+	// CIStatus returns the CI state of the given branch: "success", "failure",
+	// "pending", or "" when the provider has no status to report
+	CIStatus(ctx context.Context, branch string) (string, error)
+	// MergeStatus returns "conflict" when the PR has merge conflicts, "blocked"
+	// when failing required checks prevent merging, or "" otherwise
+	MergeStatus(ctx context.Context, pr PullRequest) (string, error)
+}
+
+var scmProviderName = "github" // os.Getenv("SCM_PROVIDER") // github, gitea, bitbucket
+var ciBranch = ""              // os.Getenv("CI_BRANCH") // branch to watch CI status on, empty disables CI checks
+var ciState = ""               // last known CI state: success, failure, pending or ""
+var prCount = 0                // number of open PRs driving the PR state, across all pages
+
+var ghPRCheckMergeable = false // os.Getenv("GH_PR_CHECK_MERGEABLE") == "true" // costs one or two API calls per open PR
+var ghBlockedPRState = "none"  // "open" when any PR has conflicts or failing required checks
+
+// newSCMProvider returns the provider selected by SCM_PROVIDER, or nil when SCM_PROVIDER is
+// unset and GitHub is not configured. A provider selected explicitly must be configured.
+func newSCMProvider(name string) (SCMProvider, error) {
+	switch name {
+	case "", "github":
+		if ghOrg == "" && (ghOwner == "" || ghRepo == "") {
+			if name == "" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("github needs GH_ORG or GH_OWNER and GH_REPO")
+		}
+		return githubProvider{}, nil
+	case "gitea", "forgejo":
+		if giteaUrl == "" || giteaOwner == "" || giteaRepo == "" {
+			return nil, fmt.Errorf("%s needs GITEA_URL, GITEA_OWNER and GITEA_REPO", name)
+		}
+		return giteaProvider{}, nil
+	case "bitbucket":
+		if bitbucketWorkspace == "" || bitbucketRepo == "" {
+			return nil, fmt.Errorf("bitbucket needs BITBUCKET_WORKSPACE and BITBUCKET_REPO")
+		}
+		return bitbucketProvider{}, nil
+	default:
+		return nil, fmt.Errorf("unknown SCM provider %q", name)
+	}
+}
+
+var scmProvider SCMProvider
+
+// Pull Request and CI Checks
+func scmChecks(ctx context.Context) {
+
+	// Ensure a provider is configured otherwise skip
+	if scmProvider == nil || !integrationReady("scm") {
+		return
+	}
+
+	cycle := &integrationCycle{integration: "scm"}
+	defer cycle.done()
+	if ciBranch != "" {
+		scmCICheck(ctx, cycle)
+	}
+
+	prs, err := scmProvider.OpenPullRequests(ctx)
+	if err != nil {
+		cycle.fail("Error checking pull requests:", err)
+		return
+	}
+
+	// Drafts, labels and authors matching the filters are dropped or shown in their own color
+	prs, ignored := filterPullRequests(prs)
+	var otherIssues []Issue
+	ghIgnoredPRState = "none"
+	if ghPRIgnoredMode == "color" {
+		for _, pr := range ignored {
+			otherIssues = append(otherIssues, Issue{Key: pr.key(), Type: "PullRequestIgnored", Message: pr.Title, Timestamp: time.Now(), PR: pr.detail()})
+		}
+		if len(otherIssues) > 0 {
+			ghIgnoredPRState = "open"
+		}
+	}
+
+	// Dependency update PRs from bots get their own state so they don't look like pending reviews
+	ghBotPRState = "none"
+	if ghPRBotsSeparate {
+		var bots []PullRequest
+		prs, bots = splitBotPullRequests(prs)
+		for _, pr := range bots {
+			otherIssues = append(otherIssues, Issue{Key: pr.key(), Type: "PullRequestDependency", Message: pr.Title, Timestamp: time.Now(), PR: pr.detail()})
+		}
+		if len(bots) > 0 {
+			ghBotPRState = "open"
+		}
+	}
+
+	if len(prs) == 0 && ghPRState != "none" {
+		notifyRecovery("prs_closed", "Pull Requests: all closed", fmt.Sprintf("All %d open pull requests were closed or merged", prCount))
+	} else if len(prs) > 0 && len(prs) < prCount {
+		notifyRecovery("prs_decreased", fmt.Sprintf("Pull Requests: %d", len(prs)), fmt.Sprintf("Open pull requests went down from %d to %d", prCount, len(prs)))
+	}
+	prCount = len(prs)
+	if len(prs) == 0 {
+		//fmt.Println("No open pull requests found.")
+		ghPRState = "none"
+		ghBlockedPRState = "none" // the last blocked PR was closed or merged
+		pullRequests = otherIssues
+		return
+	}
+
+	// PRs with merge conflicts or failing required checks need action, so they get their own state
+	if ghPRCheckMergeable {
+		blocked := scmBlockedPullRequests(ctx, prs, cycle)
+		otherIssues = append(otherIssues, blocked...)
+		newState := "none"
+		if len(blocked) > 0 {
+			newState = "open"
+		}
+		if newState == "open" && ghBlockedPRState != "open" {
+			var lines []string
+			for _, issue := range blocked {
+				lines = append(lines, issue.Message)
+			}
+			ntfyOpts := NtfyOptions{
+				Title:    fmt.Sprintf("Pull Requests blocked: %d", len(blocked)),
+				Priority: 4, // (required)
+				Key:      "prs-blocked",
+				Active:   func() bool { return ghBlockedPRState == "open" },
+			}
+			if err := SendNtfyAlert(strings.Join(lines, "\n"), ntfyOpts); err != nil {
+				log.Printf("Error sending ntfy alert: %v", err)
+			}
+		}
+		ghBlockedPRState = newState
+	}
+
+	var issues []Issue
+	var latestPR PullRequest
+	for _, pr := range prs {
+		//fmt.Printf("PR #%d: %s by %s\n", pr.Number, pr.Title, pr.User.Login)
+		issues = append(issues, Issue{Key: pr.key(), Type: "PullRequest", Message: pr.Title, Timestamp: time.Now(), PR: pr.detail()})
+		if pr.CreatedAt.After(latestPR.CreatedAt) {
+			latestPR = pr
+		}
+	}
+	//ghPRState = "none" // this line to be removed
+	if len(issues) > 0 {
+
+		// Too many or too old PRs escalate the state to its own color
+		newState := "open"
+		if isPREscalated(prs) {
+			newState = "escalated"
+		}
+
+		// if current ghPRState is changing from none to open, or is escalating, send a ntfy message
+		if ghPRState == "none" || (newState == "escalated" && ghPRState != "escalated") {
+			title := fmt.Sprintf("Pull Requests: %d", len(issues))
+			priority := 3
+			if newState == "escalated" {
+				title = fmt.Sprintf("Pull Requests need attention: %d", len(issues))
+				priority = 4
+			}
+			ntfyOpts := NtfyOptions{
+				Title:    title,
+				Priority: priority, // (required)
+				Click:    latestPR.HTMLURL,
+				Markdown: true,
+				Key:      "prs",
+				Active:   func() bool { return ghPRState != "none" },
+			}
+			err := SendNtfyAlert(fmt.Sprintf("Latest: %s\n\n%s", latestPR.link(), formatPRAges(prs)), ntfyOpts)
+			if err != nil {
+				log.Printf("Error sending ntfy alert: %v", err)
+			}
+		}
+
+		ghPRState = newState
+	}
+	pullRequests = append(issues, otherIssues...)
+}
+
+// scmBlockedPullRequests returns an issue for every PR with merge conflicts or failing required checks
+func scmBlockedPullRequests(ctx context.Context, prs []PullRequest, cycle *integrationCycle) []Issue {
+	var issues []Issue
+	for _, pr := range prs {
+		status, err := scmProvider.MergeStatus(ctx, pr)
+		if err != nil {
+			cycle.fail("Error checking pull request mergeability:", err)
+			continue
+		}
+		switch status {
+		case "conflict":
+			issues = append(issues, Issue{Key: pr.key() + "/conflict", Type: "PullRequestConflict", Message: fmt.Sprintf("#%s %s has merge conflicts", pr.key(), pr.Title), Timestamp: time.Now(), PR: pr.detail()})
+		case "blocked":
+			issues = append(issues, Issue{Key: pr.key() + "/blocked", Type: "PullRequestBlocked", Message: fmt.Sprintf("#%s %s is blocked by failing required checks", pr.key(), pr.Title), Timestamp: time.Now(), PR: pr.detail()})
+		}
+	}
+	return issues
+}
+
+// scmCICheck refreshes ciState for CI_BRANCH and notifies when CI starts failing
+func scmCICheck(ctx context.Context, cycle *integrationCycle) {
+	state, err := scmProvider.CIStatus(ctx, ciBranch)
+	if err != nil {
+		cycle.fail("Error checking CI status:", err)
+		return
+	}
+
+	if state == "failure" && ciState != "failure" {
+		ntfyOpts := NtfyOptions{
+			Title:    fmt.Sprintf("CI failing: %s", ciBranch),
+			Priority: 4, // (required)
+			Active:   func() bool { return ciState == "failure" },
+		}
+		err := SendNtfyAlert(fmt.Sprintf("CI on %s (%s) is failing", ciBranch, scmProvider.Name()), ntfyOpts)
+		if err != nil {
+			log.Printf("Error sending ntfy alert: %v", err)
+		}
+	}
+	if state == "success" && ciState == "failure" {
+		notifyRecovery("ci_recovered", fmt.Sprintf("CI recovered: %s", ciBranch), fmt.Sprintf("CI on %s (%s) is passing again", ciBranch, scmProvider.Name()))
+	}
+	ciState = state
+}
+
+// httpGetJSON sends an API request and decodes the JSON response into v
+func httpGetJSON(name string, req *http.Request, v interface{}) error {
+	resp, err := httpClient(strings.ToLower(name)).Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s API returned status: %s", name, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeSCM serves fixed pull requests with a merge status per PR number
+type fakeSCM struct {
+	prs    []PullRequest
+	status map[int]string
+}
+
+func (f *fakeSCM) Name() string { return "fake" }
+
+func (f *fakeSCM) OpenPullRequests(context.Context) ([]PullRequest, error) { return f.prs, nil }
+
+func (f *fakeSCM) CIStatus(context.Context, string) (string, error) { return "", nil }
+
+func (f *fakeSCM) MergeStatus(_ context.Context, pr PullRequest) (string, error) {
+	return f.status[pr.Number], nil
+}
+
+func TestBlockedPRSignalClearsWhenLastPRCloses(t *testing.T) {
+	defer func(p SCMProvider, m bool, d string) {
+		scmProvider, ghPRCheckMergeable, notifyDriver, recording = p, m, d, nil
+		ghPRState, ghBlockedPRState, prCount, pullRequests = "none", "none", 0, nil
+	}(scmProvider, ghPRCheckMergeable, notifyDriver)
+	provider := &fakeSCM{
+		prs:    []PullRequest{{Number: 7, Title: "Bump chart", User: User{Login: "dev"}, CreatedAt: time.Now()}},
+		status: map[int]string{7: "conflict"},
+	}
+	scmProvider, ghPRCheckMergeable, notifyDriver = provider, true, "recording"
+
+	blocked := func() bool {
+		return composeState(StateInputs{PRState: ghPRState, BlockedPRs: ghBlockedPRState == "open"}).Has(SignalPullRequestsBlocked)
+	}
+	scmChecks(context.Background())
+	if !blocked() {
+		t.Fatalf("state without pull_requests_blocked for a conflicting PR, blocked state %q", ghBlockedPRState)
+	}
+
+	provider.prs = nil
+	scmChecks(context.Background())
+	if blocked() || ghBlockedPRState != "none" {
+		t.Errorf("blocked state %q after the last PR closed, want none", ghBlockedPRState)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"maps"
+	"slices"
+)
+
+// ScoringConfig derives the cluster issue signal from a weighted score instead of the presence
+// of any issue, so a flaky pod in a large cluster doesn't look like a dead node
+type ScoringConfig struct {
+	Weights       map[string]int   `yaml:"weights"`        // by issue type, e.g. Node: 10, Pod: 3, Event: 1, PullRequest: 0
+	DefaultWeight *int             `yaml:"default_weight"` // weight of unlisted issue types, default 1
+	Thresholds    []ScoreThreshold `yaml:"thresholds"`     // the highest threshold reached sets the signal
+}
+
+// ScoreThreshold raises State once the score reaches Score
+type ScoreThreshold struct {
+	Score int    `yaml:"score"`
+	State string `yaml:"state"`
+}
+
+// scoringEnabled reports whether the config defines score thresholds
+func scoringEnabled() bool {
+	return len(config.Scoring.Thresholds) > 0
+}
+
+// validateScoring checks the weights and threshold states and sorts the thresholds by score
+func validateScoring() configProblems {
+	var problems configProblems
+	for _, issueType := range slices.Sorted(maps.Keys(config.Scoring.Weights)) {
+		if w := config.Scoring.Weights[issueType]; w < 0 {
+			problems.addField("weights", -1, "weight of %s must not be negative, got %d", issueType, w)
+		}
+	}
+	if w := config.Scoring.DefaultWeight; w != nil && *w < 0 {
+		problems.addField("default_weight", -1, "default_weight must not be negative, got %d", *w)
+	}
+	for i, t := range config.Scoring.Thresholds {
+		if _, err := parseProblemState(t.State); err != nil {
+			problems.addField("thresholds", i, "scoring threshold state %q: %v", t.State, err)
+		}
+	}
+	slices.SortFunc(config.Scoring.Thresholds, func(a, b ScoreThreshold) int { return a.Score - b.Score })
+	return problems
+}
+
+// issueWeight returns the configured weight of the issue type
+func issueWeight(issue Issue) int {
+	if w, ok := config.Scoring.Weights[issue.Type]; ok {
+		return w
+	}
+	// PRs have their own signals, so they only count when weighted explicitly
+	if issue.PR != nil {
+		return 0
+	}
+	if config.Scoring.DefaultWeight != nil {
+		return *config.Scoring.DefaultWeight
+	}
+	return 1
+}
+
+// scoreIssues splits issues into the score of those raising the generic issues_detected signal
+// and the remaining issues, which keep their own signals
+func scoreIssues(issues []Issue) (int, []Issue) {
+	score := 0
+	var rest []Issue
+	for _, issue := range issues {
+		if !isActionable(issue) {
+			continue
+		}
+		if issueState(issue) != SignalIssuesDetected {
+			rest = append(rest, issue)
+			continue
+		}
+		score += issueWeight(issue)
+	}
+	return score, rest
+}
+
+// applyScoring replaces the generic issue signals of the inputs with the signal of the weighted
+// score of issues and returns the score. Warning events are scored as well when they raise
+// issues_detected; otherwise they keep their own signal.
+func applyScoring(in *StateInputs, issues, eventIssues []Issue) int {
+	if in.WarningEventSig == SignalIssuesDetected {
+		issues = slices.Concat(issues, eventIssues)
+		in.WarningEvents = false
+	}
+	score, rest := scoreIssues(issues)
+	in.ClusterIssues, in.CheckIssues = false, rest
+	in.ScoreSig = scoreSignal(score)
+	return score
+}
+
+// scoreSignal returns the state of the highest threshold the score reaches, or "" below all thresholds
+func scoreSignal(score int) Signal {
+	var signal Signal
+	for _, t := range config.Scoring.Thresholds {
+		if score >= t.Score {
+			signal = Signal(t.State)
+		}
+	}
+	return signal
+}
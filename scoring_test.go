@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestScoring(t *testing.T) {
+	defer func() { config, issueTypeStates = Config{}, map[string]Signal{} }()
+	zero := 0
+	config.Scoring = ScoringConfig{
+		Weights:       map[string]int{"Node": 10, "Pod": 3},
+		DefaultWeight: &zero,
+		Thresholds:    []ScoreThreshold{{Score: 10, State: "issues_detected"}, {Score: 3, State: "warning_events"}},
+	}
+	issueTypeStates = map[string]Signal{"Vulnerability": SignalVulnerabilitiesFound}
+	if err := validateScoring(); err != nil {
+		t.Fatal(err)
+	}
+
+	weights := []struct {
+		issue Issue
+		want  int
+	}{
+		{Issue{Type: "Node"}, 10},
+		{Issue{Type: "Pod"}, 3},
+		{Issue{Type: "Probe"}, 0},
+		{Issue{Type: "PullRequest", PR: &PRDetail{}}, 0},
+	}
+	for _, tc := range weights {
+		if got := issueWeight(tc.issue); got != tc.want {
+			t.Errorf("weight of %s = %d, want %d", tc.issue.Type, got, tc.want)
+		}
+	}
+
+	score, rest := scoreIssues([]Issue{{Type: "Pod"}, {Type: "Pod", Severity: "info"}, {Type: "Node"}, {Type: "Vulnerability"}})
+	if score != 13 || len(rest) != 1 || rest[0].Type != "Vulnerability" {
+		t.Errorf("scoreIssues = %d, %v", score, rest)
+	}
+
+	signals := []struct {
+		score int
+		want  Signal
+	}{{0, ""}, {2, ""}, {3, SignalWarningEvents}, {9, SignalWarningEvents}, {10, SignalIssuesDetected}, {50, SignalIssuesDetected}}
+	for _, tc := range signals {
+		if got := scoreSignal(tc.score); got != tc.want {
+			t.Errorf("scoreSignal(%d) = %q, want %q", tc.score, got, tc.want)
+		}
+	}
+}
+
+func TestValidateScoringRejects(t *testing.T) {
+	defer func() { config = Config{} }()
+	negative := -1
+	for name, scoring := range map[string]ScoringConfig{
+		"healthy state":    {Thresholds: []ScoreThreshold{{Score: 1, State: "healthy"}}},
+		"unknown state":    {Thresholds: []ScoreThreshold{{Score: 1, State: "on_fire"}}},
+		"negative weight":  {Weights: map[string]int{"Pod": -3}},
+		"negative default": {DefaultWeight: &negative},
+	} {
+		config.Scoring = scoring
+		if validateScoring() == nil {
+			t.Errorf("%s passed validation", name)
+		}
+	}
+}
+
+func TestApplyScoringKeepsWarningEventSignal(t *testing.T) {
+	defer func() { config = Config{} }()
+	config.Scoring = ScoringConfig{Thresholds: []ScoreThreshold{{Score: 5, State: "issues_detected"}}}
+	events := []Issue{{Type: "Event"}}
+
+	in := StateInputs{WarningEvents: true, WarningEventSig: SignalWarningEvents}
+	applyScoring(&in, []Issue{{Type: "Pod"}}, events)
+	if got := composeState(in).String(); got != "warning_events" {
+		t.Errorf("state = %s, want warning_events", got)
+	}
+
+	in = StateInputs{WarningEvents: true, WarningEventSig: SignalIssuesDetected}
+	if score := applyScoring(&in, []Issue{{Type: "Pod"}}, events); score != 2 || in.WarningEvents {
+		t.Errorf("events mapped to issues_detected: score %d, warning events %v, want them scored", score, in.WarningEvents)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"time"
+)
+
+var httpListenAddr = ":8080" // os.Getenv("HTTP_LISTEN_ADDR")
+
+// httpMux holds the routes served by the built-in HTTP server
+var httpMux = http.NewServeMux()
+
+// startHTTPServer serves httpMux in the background unless HTTP_LISTEN_ADDR is set to ""
+func startHTTPServer() *http.Server {
+	if httpListenAddr == "" {
+		return nil
+	}
+
+	server := &http.Server{
+		Addr:              httpListenAddr,
+		Handler:           httpMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		log.Printf("HTTP server listening on %s", httpListenAddr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("HTTP server failed: %v", err)
+		}
+	}()
+	return server
+}
+
+// requireToken rejects requests without the API_TOKEN bearer token when one is configured
+func requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if apiToken != "" && !tokenValid(r.Header.Get("Authorization")) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// tokenValid reports whether an Authorization value carries the API_TOKEN, compared in
+// constant time so the token can't be guessed from response times
+func tokenValid(authorization string) bool {
+	return subtle.ConstantTimeCompare([]byte(authorization), []byte("Bearer "+apiToken)) == 1
+}
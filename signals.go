@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// externalSignal is a named issue raised or cleared by an external system such as a backup
+// script, a UPS monitor or a cron job
+type externalSignal struct {
+	Name     string `json:"name"`               // e.g. backup-nightly
+	Severity string `json:"severity,omitempty"` // critical, warning (default) or info
+	Message  string `json:"message,omitempty"`
+	TTL      string `json:"ttl,omitempty"`   // e.g. 26h, the signal turns stale unless reported again within it
+	Clear    bool   `json:"clear,omitempty"` // clears the signal instead of raising it
+}
+
+var signalName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,62}$`)
+
+var externalMu sync.Mutex
+var externalIssues = map[string]*externalIssue{}
+
+// externalIssue is a reported signal with the time by which it must be reported again, zero
+// when it stays as it is; cleared signals are only kept to watch their TTL
+type externalIssue struct {
+	issue   Issue
+	raised  bool
+	ttl     time.Duration
+	expires time.Time
+}
+
+// signalHandler raises or clears an external signal with POST, and lists the raised signals with GET
+func signalHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(checkExternalSignals(r.Context(), nil))
+		return
+	case http.MethodPost:
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var signal externalSignal
+	if err := json.NewDecoder(io.LimitReader(r.Body, 64<<10)).Decode(&signal); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if err := applyExternalSignal(signal, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	requestClusterRecheck()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// applyExternalSignal validates the signal and raises or clears its issue
+func applyExternalSignal(signal externalSignal, now time.Time) error {
+	if !signalName.MatchString(signal.Name) {
+		return fmt.Errorf("name must be 1-63 letters, digits, '.', '_' or '-'")
+	}
+	key := "signal/" + signal.Name
+
+	switch signal.Severity {
+	case "":
+		signal.Severity = "warning"
+	case "critical", "warning", "info":
+	default:
+		return fmt.Errorf("severity must be critical, warning or info")
+	}
+	var ttl time.Duration
+	var expires time.Time
+	if signal.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(signal.TTL)
+		if err != nil || ttl <= 0 {
+			return fmt.Errorf("ttl must be a positive duration such as 30m or 26h")
+		}
+		expires = now.Add(ttl)
+	}
+	if signal.Message == "" {
+		signal.Message = fmt.Sprintf("External signal %s raised", signal.Name)
+	}
+
+	externalMu.Lock()
+	defer externalMu.Unlock()
+	previous, known := externalIssues[key]
+	if signal.Clear {
+		if known && previous.raised {
+			log.Printf("External signal %s cleared", signal.Name)
+		}
+		delete(externalIssues, key)
+		// A cleared signal with a TTL is a heartbeat, it turns stale when the next one is missed
+		if ttl > 0 {
+			externalIssues[key] = &externalIssue{
+				issue: Issue{Key: key, Type: "ExternalSignal", Severity: signal.Severity, Timestamp: now, Name: signal.Name},
+				ttl:   ttl, expires: expires,
+			}
+		}
+		return nil
+	}
+
+	if !known || !previous.raised {
+		log.Printf("External signal %s raised (%s): %s", signal.Name, signal.Severity, signal.Message)
+	}
+	externalIssues[key] = &externalIssue{
+		issue:  Issue{Key: key, Type: "ExternalSignal", Severity: signal.Severity, Message: signal.Message, Timestamp: now, Name: signal.Name},
+		raised: true, ttl: ttl, expires: expires,
+	}
+	return nil
+}
+
+// checkExternalSignals returns the raised external signals; a signal not reported again within
+// its TTL is raised as stale until it's reported again
+func checkExternalSignals(_ context.Context, _ kubernetes.Interface) []Issue {
+	externalMu.Lock()
+	defer externalMu.Unlock()
+	issues := []Issue{}
+	now := time.Now()
+	for _, e := range externalIssues {
+		if !e.expires.IsZero() && now.After(e.expires) {
+			log.Printf("External signal %s is stale, not reported within %s", e.issue.Name, e.ttl)
+			e.issue.Type, e.issue.Reason, e.issue.Timestamp = "ExternalSignalStale", "Stale", now
+			e.issue.Message = fmt.Sprintf("External signal %s not reported within %s", e.issue.Name, e.ttl)
+			e.raised, e.expires = true, time.Time{}
+		}
+		if e.raised {
+			issues = append(issues, e.issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
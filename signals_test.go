@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExternalSignals(t *testing.T) {
+	defer func() { externalIssues = map[string]*externalIssue{} }()
+
+	now := time.Now()
+	if err := applyExternalSignal(externalSignal{Name: "backup nightly"}, now); err == nil {
+		t.Error("accepted a name with a space")
+	}
+	if err := applyExternalSignal(externalSignal{Name: "ups", Severity: "fatal"}, now); err == nil {
+		t.Error("accepted an unknown severity")
+	}
+	if err := applyExternalSignal(externalSignal{Name: "ups", Severity: "critical", Message: "UPS on battery"}, now); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := checkExternalSignals(nil, nil)
+	if len(issues) != 1 || issues[0].Key != "signal/ups" || issues[0].Severity != "critical" {
+		t.Fatalf("issues = %+v, want the ups signal", issues)
+	}
+
+	applyExternalSignal(externalSignal{Name: "ups", Clear: true}, now)
+	if issues := checkExternalSignals(nil, nil); len(issues) != 0 {
+		t.Errorf("issues after clearing = %+v", issues)
+	}
+}
+
+func TestExternalSignalsTurnStale(t *testing.T) {
+	defer func() { externalIssues = map[string]*externalIssue{} }()
+
+	now := time.Now()
+	// A heartbeat cleared within its TTL raises nothing
+	applyExternalSignal(externalSignal{Name: "backup", Clear: true, TTL: "26h"}, now.Add(-time.Hour))
+	// A missed heartbeat and a raised signal not reported again turn stale
+	applyExternalSignal(externalSignal{Name: "restic", Clear: true, TTL: "26h"}, now.Add(-27*time.Hour))
+	applyExternalSignal(externalSignal{Name: "ups", Severity: "critical", TTL: "1h"}, now.Add(-2*time.Hour))
+
+	issues := checkExternalSignals(nil, nil)
+	if len(issues) != 2 || issues[0].Key != "signal/restic" || issues[1].Key != "signal/ups" {
+		t.Fatalf("issues = %+v, want the restic and ups signals stale", issues)
+	}
+	for _, issue := range issues {
+		if issue.Type != "ExternalSignalStale" || issue.Reason != "Stale" {
+			t.Errorf("issue = %+v, want stale", issue)
+		}
+	}
+	if issues[1].Severity != "critical" || issues[1].Message != "External signal ups not reported within 1h0m0s" {
+		t.Errorf("issue = %+v, want the severity kept", issues[1])
+	}
+
+	// Stale until reported again
+	if issues := checkExternalSignals(nil, nil); len(issues) != 2 {
+		t.Errorf("issues = %+v, want the stale signals kept", issues)
+	}
+	applyExternalSignal(externalSignal{Name: "restic", Clear: true, TTL: "26h"}, now)
+	applyExternalSignal(externalSignal{Name: "ups", Clear: true}, now)
+	if issues := checkExternalSignals(nil, nil); len(issues) != 0 {
+		t.Errorf("issues = %+v, want none after the signals were reported", issues)
+	}
+}
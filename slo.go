@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+var sloTarget = 0.0            // os.Getenv("SLO_TARGET") // availability target in percent, e.g. 99.5, 0 disables the budget signal
+var sloBudgetLowPercent = 10.0 // os.Getenv("SLO_BUDGET_LOW_PERCENT") // show slo_budget_low when less of the 30d error budget remains
+var sloBudgetLow = false       // set when the 30d error budget is nearly spent
+
+// sloWindows are the rolling windows availability is computed over
+var sloWindows = []struct {
+	name   string
+	length time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// SLOReport is the rolling availability and the remaining 30d error budget
+type SLOReport struct {
+	Availability           map[string]float64 `json:"availability"` // percent per window
+	Target                 float64            `json:"target,omitempty"`
+	BudgetRemainingPercent *float64           `json:"budget_remaining_percent,omitempty"`
+}
+
+// isOutage reports whether the state counts against availability; PR, CI and other
+// informational signals don't
+func isOutage(state ClusterState) bool {
+	return state.Has(SignalIssuesDetected) || state.Has(SignalIncidentsOpen)
+}
+
+// historyTransition records the transition and opens and closes outage periods as the state
+// changes
+func historyTransition(t Transition) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	n := len(history.Outages)
+	ongoing := n > 0 && history.Outages[n-1].End == nil
+	switch {
+	case isOutage(t.To) && !ongoing:
+		history.Outages = append(history.Outages, Outage{Start: t.At})
+	case !isOutage(t.To) && ongoing:
+		end := t.At
+		history.Outages[n-1].End = &end
+	}
+
+	history.Transitions = append(history.Transitions, stateChange(t))
+	if historyFile != "" {
+		if err := saveHistory(); err != nil {
+			log.Printf("Error saving issue history: %v", err)
+		}
+	}
+}
+
+// availability returns the percentage of the window during which the cluster was not in an
+// outage. The window is bounded by the start of the history and by HISTORY_RETENTION_DAYS, as
+// older outages are no longer known.
+func availability(window time.Duration, now time.Time) float64 {
+	start := now.Add(-window)
+	if history.Since.After(start) {
+		start = history.Since
+	}
+	if retained := now.AddDate(0, 0, -historyRetentionDays); retained.After(start) {
+		start = retained
+	}
+	total := now.Sub(start)
+	if total <= 0 {
+		return 100
+	}
+
+	var down time.Duration
+	for _, o := range history.Outages {
+		end := now
+		if o.End != nil {
+			end = *o.End
+		}
+		from := o.Start
+		if from.Before(start) {
+			from = start
+		}
+		if end.After(from) {
+			down += end.Sub(from)
+		}
+	}
+	return 100 * (1 - down.Seconds()/total.Seconds())
+}
+
+// sloReport computes the availability of every window and the remaining error budget
+func sloReport() SLOReport {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	now := time.Now()
+	report := SLOReport{Availability: map[string]float64{}, Target: sloTarget}
+	for _, w := range sloWindows {
+		report.Availability[w.name] = availability(w.length, now)
+	}
+	if sloTarget > 0 && sloTarget < 100 {
+		remaining := 100 * (1 - (100-report.Availability["30d"])/(100-sloTarget))
+		report.BudgetRemainingPercent = &remaining
+	}
+	return report
+}
+
+// updateSLO refreshes the availability metrics and the error budget signal
+func updateSLO() {
+	report := sloReport()
+	for name, value := range report.Availability {
+		setGauge("clusterbulb_availability_percent", fmt.Sprintf("window=%q", name), value)
+	}
+	sloBudgetLow = false
+	if report.BudgetRemainingPercent != nil {
+		setGauge("clusterbulb_error_budget_remaining_percent", "", *report.BudgetRemainingPercent)
+		sloBudgetLow = *report.BudgetRemainingPercent < sloBudgetLowPercent
+	}
+}
+
+// sloHandler serves the SLO report as JSON
+func sloHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sloReport()); err != nil {
+		log.Printf("Error encoding SLO report: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityUsesObservedWindow(t *testing.T) {
+	defer func(h issueHistory, days int) { history, historyRetentionDays = h, days }(history, historyRetentionDays)
+	now := time.Now()
+	end := now.Add(-12 * time.Hour)
+
+	// One day of history with a 12h outage is 50% available, not 98% of 30 days
+	history = issueHistory{Since: now.Add(-24 * time.Hour), Outages: []Outage{{Start: now.Add(-24 * time.Hour), End: &end}}}
+	historyRetentionDays = 30
+	if got := availability(30*24*time.Hour, now); math.Abs(got-50) > 0.01 {
+		t.Errorf("young history: 30d availability = %.2f, want 50", got)
+	}
+
+	// With a 7 day retention, the 30d window only covers the retained 7 days
+	history.Since = now.Add(-60 * 24 * time.Hour)
+	historyRetentionDays = 7
+	if got, want := availability(30*24*time.Hour, now), 100*(1-12.0/(7*24)); math.Abs(got-want) > 0.01 {
+		t.Errorf("short retention: 30d availability = %.2f, want %.2f", got, want)
+	}
+}
+
+func TestRecoveryIsAudited(t *testing.T) {
+	defer func(h issueHistory, f string, r map[string]bool) { history, historyFile, ntfyRecoveries = h, f, r }(history, historyFile, ntfyRecoveries)
+	history = issueHistory{Since: time.Now(), Open: map[string]*IssueRecord{}}
+	historyFile = t.TempDir() + "/history.json"
+	ntfyRecoveries = map[string]bool{} // audited even when not notified
+
+	notifyRecovery("ci_recovered", "CI recovered: main", "CI is passing again on main")
+	audit := historySummary().Audit
+	if len(audit) != 1 || audit[0].Kind != "recovery/ci_recovered" || audit[0].Message != "CI is passing again on main" {
+		t.Fatalf("audit = %+v, want the recovery", audit)
+	}
+
+	// The audit trail survives a restart
+	history = issueHistory{}
+	if err := loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Audit) != 1 {
+		t.Errorf("loaded %d audit entries, want 1", len(history.Audit))
+	}
+}
+
+func TestTransitionsFromHistory(t *testing.T) {
+	defer func(h issueHistory, f string) { history, historyFile = h, f }(history, historyFile)
+	history = issueHistory{Since: time.Now(), Open: map[string]*IssueRecord{}}
+	historyFile = t.TempDir() + "/history.json"
+
+	start := time.Now().Add(-time.Hour)
+	for i := range recentTransitionsMax + 5 {
+		from, to := ClusterState{SignalHealthy}, ClusterState{SignalIssuesDetected}
+		if i%2 == 1 {
+			from, to = to, from
+		}
+		historyTransition(Transition{From: from, To: to, Added: to, Removed: from, At: start.Add(time.Duration(i) * time.Minute)})
+	}
+
+	// The handler serves the last transitions of the history, oldest first
+	rec := httptest.NewRecorder()
+	transitionsHandler(rec, httptest.NewRequest("GET", "/api/v1/transitions", nil))
+	var got []StateChange
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != recentTransitionsMax {
+		t.Fatalf("got %d transitions, want %d", len(got), recentTransitionsMax)
+	}
+	if !got[0].At.Equal(start.Add(5*time.Minute)) || got[0].To != "healthy" || got[len(got)-1].To != "issues_detected" {
+		t.Errorf("transitions = %+v, want the last ones oldest first", got)
+	}
+	if len(history.Outages) != (recentTransitionsMax+5+1)/2 {
+		t.Errorf("got %d outages, want one per unhealthy transition", len(history.Outages))
+	}
+
+	// The transitions survive a restart
+	history = issueHistory{}
+	if err := loadHistory(); err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Transitions) != recentTransitionsMax+5 {
+		t.Errorf("loaded %d transitions, want %d", len(history.Transitions), recentTransitionsMax+5)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+)
+
+var haMediaPlayerEntityId = ""                   // os.Getenv("HA_MEDIA_PLAYER_ENTITY_ID") // e.g. media_player.kitchen, enables audible alerts
+var haSoundUrl = ""                              // os.Getenv("HA_SOUND_URL") // media played, e.g. media-source://media_source/local/alert.mp3
+var haSoundVolume = -1.0                         // os.Getenv("HA_SOUND_VOLUME") // 0-1, the player's volume is kept when unset
+var soundStates = []Signal{SignalIssuesDetected} // os.Getenv("SOUND_STATES") // states that play the sound when raised
+var soundInterval = 15 * time.Minute             // os.Getenv("SOUND_INTERVAL_MINUTES") // minimum time between two sounds
+
+// soundTransition plays the alert sound when a critical state is raised, unless notifications are
+// silenced or a sound played within SOUND_INTERVAL_MINUTES
+func soundTransition(t Transition) {
+	if t.Initial || !slices.ContainsFunc(t.Added, func(s Signal) bool { return slices.Contains(soundStates, s) }) {
+		return
+	}
+	if notifySilenced(4) || notifyRateLimited("sound", soundInterval) {
+		return
+	}
+	log.Printf("Playing alert sound on %s for %s", haMediaPlayerEntityId, t.To)
+	playAlertSound(appCtx)
+}
+
+// playAlertSound plays HA_SOUND_URL on the media player, which may be any Home Assistant
+// media_player such as a Sonos speaker or a Google Cast device
+func playAlertSound(ctx context.Context) {
+	if !haConfigured() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	if haSoundVolume >= 0 {
+		haCallService(ctx, "media_player", "volume_set", map[string]interface{}{"entity_id": haMediaPlayerEntityId, "volume_level": haSoundVolume})
+	}
+	haCallService(ctx, "media_player", "play_media", map[string]interface{}{
+		"entity_id":          haMediaPlayerEntityId,
+		"media_content_id":   haSoundUrl,
+		"media_content_type": "music",
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// spotNodeLabels identifies spot/preemptible nodes as key=value labels
+var spotNodeLabels = []string{ // os.Getenv("SPOT_NODE_LABELS")
+	"eks.amazonaws.com/capacityType=SPOT",
+	"karpenter.sh/capacity-type=spot",
+	"cloud.google.com/gke-spot=true",
+	"cloud.google.com/gke-preemptible=true",
+}
+
+// spotTerminationSignals are taint keys or node condition types announcing an imminent interruption
+var spotTerminationSignals = []string{ // os.Getenv("SPOT_TERMINATION_SIGNALS")
+	"aws-node-termination-handler/spot-itn",
+	"aws-node-termination-handler/rebalance-recommendation",
+	"cloud.google.com/impending-node-termination",
+}
+
+// checkSpotInterruptions reports spot nodes carrying a termination notice taint or condition,
+// so the bulb changes before the node actually vanishes
+func checkSpotInterruptions(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching nodes: %v", err)
+		return nil
+	}
+
+	var issues []Issue
+	for _, node := range nodes.Items {
+		if !isSpotNode(node) {
+			continue
+		}
+		if signal := terminationSignal(node); signal != "" {
+			message := fmt.Sprintf("Spot node %s is about to be interrupted (%s)", node.Name, signal)
+			issues = append(issues, Issue{Key: "spot/" + node.Name, Type: "SpotInterruption", Severity: "warning", Message: message, Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
+
+// isSpotNode reports whether the node carries any of SPOT_NODE_LABELS
+func isSpotNode(node v1.Node) bool {
+	for _, label := range spotNodeLabels {
+		key, value, _ := strings.Cut(label, "=")
+		if v, ok := node.Labels[key]; ok && strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// terminationSignal returns the first termination taint or true condition found on the node
+func terminationSignal(node v1.Node) string {
+	for _, signal := range spotTerminationSignals {
+		for _, taint := range node.Spec.Taints {
+			if taint.Key == signal {
+				return signal
+			}
+		}
+		for _, cond := range node.Status.Conditions {
+			if string(cond.Type) == signal && cond.Status == v1.ConditionTrue {
+				return signal
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Signal is one active condition of the cluster state, shown as one bulb color
+type Signal string
+
+const (
+	SignalHealthy               Signal = "healthy"
+	SignalPullRequestsOpen      Signal = "pull_requests_open"
+	SignalPullRequestsEscalated Signal = "pull_requests_escalated"
+	SignalPullRequestsIgnored   Signal = "pull_requests_ignored"
+	SignalDependencyUpdatesOpen Signal = "dependency_updates_open"
+	SignalPullRequestsBlocked   Signal = "pull_requests_blocked"
+	SignalCIFailing             Signal = "ci_failing"
+	SignalSecurityAlertsOpen    Signal = "security_alerts_open"
+	SignalIncidentsOpen         Signal = "incidents_open"
+	SignalVulnerabilitiesFound  Signal = "vulnerabilities_found"
+	SignalSpotInterruption      Signal = "spot_interruption"
+	SignalRebootRequired        Signal = "reboot_required"
+	SignalSLOBudgetLow          Signal = "slo_budget_low"
+	SignalWarningEvents         Signal = "warning_events"
+	SignalIssuesDetected        Signal = "issues_detected"
+	SignalStarting              Signal = "starting"
+	SignalBooting               Signal = "booting"
+)
+
+// ClusterState is the set of active signals in blink order; no signals means healthy
+type ClusterState []Signal
+
+// Signals returns the active signals, or healthy when there are none
+func (s ClusterState) Signals() []Signal {
+	if len(s) == 0 {
+		return []Signal{SignalHealthy}
+	}
+	return s
+}
+
+// Has reports whether the signal is active
+func (s ClusterState) Has(signal Signal) bool {
+	return slices.Contains(s.Signals(), signal)
+}
+
+// String joins the signals with "|", e.g. "pull_requests_open|issues_detected"
+func (s ClusterState) String() string {
+	var parts []string
+	for _, signal := range s.Signals() {
+		parts = append(parts, string(signal))
+	}
+	return strings.Join(parts, "|")
+}
+
+// parseProblemState returns the state an env var maps a problem to. Healthy is rejected, as it
+// would turn the problem green.
+func parseProblemState(value string) (Signal, error) {
+	var valid []string
+	for signal := range stateColors() {
+		if signal != SignalHealthy {
+			valid = append(valid, string(signal))
+		}
+	}
+	slices.Sort(valid)
+	if _, ok := stateColor(Signal(value)); !ok || Signal(value) == SignalHealthy {
+		return "", fmt.Errorf("expected one of %s", strings.Join(valid, ", "))
+	}
+	return Signal(value), nil
+}
+
+// StateInputs is a snapshot of every signal source the cluster state is composed from
+type StateInputs struct {
+	PRState         string // none, open or escalated
+	IgnoredPRs      bool
+	BotPRs          bool
+	BlockedPRs      bool
+	CIFailing       bool
+	SecurityAlerts  bool
+	ClusterIssues   bool    // node or pod issues
+	ScoreSig        Signal  // signal of the weighted issue score, replaces ClusterIssues and WarningEvents when scoring is configured
+	WarningEvents   bool    // warning event issues
+	WarningEventSig Signal  // signal raised by warning events alone
+	CheckIssues     []Issue // issues of the registered checkers
+	LabeledIssues   bool    // open GitHub issues with GH_ISSUE_LABEL
+	LabeledIssueSig Signal  // signal raised by labeled issues
+	SLOBudgetLow    bool    // the error budget of SLO_TARGET is nearly spent
+	Booting         bool    // the cluster just restarted, replaces the cluster issue signals until it settles
+}
+
+// composeState returns the cluster state for the inputs, with signals in blink order
+func composeState(in StateInputs) ClusterState {
+	var state ClusterState
+	add := func(signal Signal) {
+		if !slices.Contains(state, signal) {
+			state = append(state, signal)
+		}
+	}
+
+	switch in.PRState {
+	case "open":
+		add(SignalPullRequestsOpen)
+	case "escalated":
+		add(SignalPullRequestsEscalated)
+	}
+	if in.IgnoredPRs {
+		add(SignalPullRequestsIgnored)
+	}
+	if in.BotPRs {
+		add(SignalDependencyUpdatesOpen)
+	}
+	if in.BlockedPRs {
+		add(SignalPullRequestsBlocked)
+	}
+	if in.CIFailing {
+		add(SignalCIFailing)
+	}
+	if in.SecurityAlerts {
+		add(SignalSecurityAlertsOpen)
+	}
+	if in.Booting {
+		add(SignalBooting)
+	}
+	if in.ClusterIssues {
+		add(SignalIssuesDetected)
+	}
+	if in.ScoreSig != "" {
+		add(in.ScoreSig)
+	}
+	for _, issue := range in.CheckIssues {
+		if isActionable(issue) {
+			add(issueState(issue))
+		}
+	}
+	// Warning events only get their own signal while nothing is actually down
+	if in.WarningEvents && !slices.Contains(state, SignalIssuesDetected) {
+		add(in.WarningEventSig)
+	}
+	if in.LabeledIssues {
+		add(in.LabeledIssueSig)
+	}
+	if in.SLOBudgetLow {
+		add(SignalSLOBudgetLow)
+	}
+	return state
+}
+
+// Transition is emitted whenever the set of active signals changes
+type Transition struct {
+	From    ClusterState
+	To      ClusterState
+	Added   []Signal
+	Removed []Signal
+	At      time.Time
+	Initial bool // the first state found after startup rather than a change
+}
+
+// clusterStateKnown is set once the first check cycle established the cluster state
+var clusterStateKnown bool
+
+// transitionListeners are called in registration order for every transition
+var transitionListeners []func(Transition)
+
+// onTransition registers a consumer of state transitions, e.g. the light driver or a notifier
+func onTransition(listener func(Transition)) {
+	transitionListeners = append(transitionListeners, listener)
+}
+
+// setClusterState replaces the cluster state and emits a transition when the active signals
+// changed; a different blink order alone is not a transition
+func setClusterState(state ClusterState) {
+	added, removed := diffStates(clusterState, state)
+	from := clusterState
+	clusterState = state
+	initial := !clusterStateKnown
+	clusterStateKnown = true
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+
+	t := Transition{From: from, To: state, Added: added, Removed: removed, At: time.Now(), Initial: initial}
+	for _, listener := range transitionListeners {
+		listener(t)
+	}
+}
+
+// diffStates returns the signals active in to but not in from, and the other way round
+func diffStates(from, to ClusterState) (added, removed []Signal) {
+	for _, signal := range to.Signals() {
+		if !from.Has(signal) {
+			added = append(added, signal)
+		}
+	}
+	for _, signal := range from.Signals() {
+		if !to.Has(signal) {
+			removed = append(removed, signal)
+		}
+	}
+	return added, removed
+}
+
+// auditTransition logs every transition as the audit trail of the bulb
+func auditTransition(t Transition) {
+	log.Printf("State changed: %s -> %s", t.From, t.To)
+}
+
+// metricsTransition counts transitions and exports the active signals
+func metricsTransition(t Transition) {
+	addCounter("clusterbulb_state_transitions_total", "", 1)
+	for _, signal := range t.Removed {
+		setGauge("clusterbulb_state_signal", fmt.Sprintf("signal=%q", signal), 0)
+	}
+	for _, signal := range t.Added {
+		setGauge("clusterbulb_state_signal", fmt.Sprintf("signal=%q", signal), 1)
+	}
+}
+
+var ntfyStateChanges = false // os.Getenv("NTFY_STATE_CHANGES") == "true" // notify on every state transition
+
+// ntfyTransition notifies about signals appearing or clearing; the state found at startup is
+// not a change, so restarting the pod doesn't notify about issues that were already known
+func ntfyTransition(t Transition) {
+	if t.Initial {
+		return
+	}
+	var lines []string
+	for _, signal := range t.Added {
+		lines = append(lines, "+ "+string(signal))
+	}
+	for _, signal := range t.Removed {
+		lines = append(lines, "- "+string(signal))
+	}
+	priority := 3
+	if slices.Contains(t.Added, SignalIssuesDetected) {
+		priority = 4
+	}
+	ntfyOpts := NtfyOptions{
+		Title:    fmt.Sprintf("Cluster state: %s", t.To),
+		Priority: priority, // (required)
+		Key:      "cluster-state",
+		Active:   func() bool { return slices.ContainsFunc(t.Added, clusterState.Has) },
+	}
+	// Tell which components are affected and what to do about the new issues, not just what broke
+	if report := latestReport(); report != nil && len(t.Added) > 0 {
+		if components := componentLines(report.Components); len(components) > 0 {
+			lines = append(append(lines, "", "Components:"), components...)
+		}
+		runbooks, first := runbookLines(slices.Concat(report.NodeIssues, report.PodIssues, report.EventIssues, report.CheckIssues))
+		if len(runbooks) > 0 {
+			lines = append(append(lines, "", "Runbooks:"), runbooks...)
+			ntfyOpts.Click = first
+		}
+	}
+	if err := SendNtfyAlert(strings.Join(lines, "\n"), ntfyOpts); err != nil {
+		log.Printf("Error sending ntfy alert: %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComposeState(t *testing.T) {
+	issueTypeStates = map[string]Signal{"Vulnerability": SignalVulnerabilitiesFound}
+	defer func() { issueTypeStates = map[string]Signal{} }()
+
+	tests := []struct {
+		name string
+		in   StateInputs
+		want string
+	}{
+		{"healthy", StateInputs{PRState: "none"}, "healthy"},
+		{"open PRs", StateInputs{PRState: "open"}, "pull_requests_open"},
+		{"escalated PRs", StateInputs{PRState: "escalated"}, "pull_requests_escalated"},
+		{"cluster issues", StateInputs{ClusterIssues: true}, "issues_detected"},
+		{"PRs and issues", StateInputs{PRState: "open", ClusterIssues: true}, "pull_requests_open|issues_detected"},
+		{"score signal", StateInputs{PRState: "open", ScoreSig: SignalWarningEvents}, "pull_requests_open|warning_events"},
+		{"warning events with default signal", StateInputs{WarningEvents: true, WarningEventSig: SignalIssuesDetected}, "issues_detected"},
+		{"warning events only", StateInputs{WarningEvents: true, WarningEventSig: SignalWarningEvents}, "warning_events"},
+		{"warning events and cluster issues", StateInputs{ClusterIssues: true, WarningEvents: true, WarningEventSig: SignalWarningEvents}, "issues_detected"},
+		{
+			"warning events and failing check",
+			StateInputs{WarningEvents: true, WarningEventSig: SignalWarningEvents, CheckIssues: []Issue{{Type: "Probe", Severity: "critical"}}},
+			"issues_detected",
+		},
+		{
+			"every PR signal in blink order",
+			StateInputs{PRState: "open", IgnoredPRs: true, BotPRs: true, BlockedPRs: true, CIFailing: true, SecurityAlerts: true},
+			"pull_requests_open|pull_requests_ignored|dependency_updates_open|pull_requests_blocked|ci_failing|security_alerts_open",
+		},
+		{"info check issue", StateInputs{CheckIssues: []Issue{{Type: "Probe", Severity: "info"}}}, "healthy"},
+		{"warning check issue", StateInputs{CheckIssues: []Issue{{Type: "Probe", Severity: "warning"}}}, "issues_detected"},
+		{"mapped check issue", StateInputs{CheckIssues: []Issue{{Type: "Vulnerability", Severity: "warning"}}}, "vulnerabilities_found"},
+		{
+			"duplicate signals collapse",
+			StateInputs{ClusterIssues: true, CheckIssues: []Issue{{Type: "Probe", Severity: "critical"}, {Type: "DNS", Severity: "warning"}}},
+			"issues_detected",
+		},
+		{"labeled issues", StateInputs{LabeledIssues: true, LabeledIssueSig: SignalIncidentsOpen}, "incidents_open"},
+		{"labeled issues with default signal", StateInputs{ClusterIssues: true, LabeledIssues: true, LabeledIssueSig: SignalIssuesDetected}, "issues_detected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := composeState(tt.in).String(); got != tt.want {
+				t.Errorf("composeState() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffStates(t *testing.T) {
+	tests := []struct {
+		name        string
+		from, to    ClusterState
+		wantAdded   []Signal
+		wantRemoved []Signal
+	}{
+		{"unchanged healthy", nil, nil, nil, nil},
+		{"healthy to issues", nil, ClusterState{SignalIssuesDetected}, []Signal{SignalIssuesDetected}, []Signal{SignalHealthy}},
+		{"issues to healthy", ClusterState{SignalIssuesDetected}, nil, []Signal{SignalHealthy}, []Signal{SignalIssuesDetected}},
+		{
+			"signal added to combination",
+			ClusterState{SignalPullRequestsOpen},
+			ClusterState{SignalPullRequestsOpen, SignalCIFailing},
+			[]Signal{SignalCIFailing}, nil,
+		},
+		{"reordered only", ClusterState{SignalPullRequestsOpen, SignalCIFailing}, ClusterState{SignalCIFailing, SignalPullRequestsOpen}, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffStates(tt.from, tt.to)
+			if !slices.Equal(added, tt.wantAdded) || !slices.Equal(removed, tt.wantRemoved) {
+				t.Errorf("diffStates() = %v, %v, want %v, %v", added, removed, tt.wantAdded, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestSetClusterStateEmitsTransitions(t *testing.T) {
+	var got []Transition
+	transitionListeners = []func(Transition){func(tr Transition) { got = append(got, tr) }}
+	clusterState = nil
+	defer func() { transitionListeners, clusterState = nil, nil }()
+
+	setClusterState(ClusterState{SignalPullRequestsOpen})
+	setClusterState(ClusterState{SignalPullRequestsOpen})
+	setClusterState(ClusterState{SignalPullRequestsOpen, SignalIssuesDetected})
+	setClusterState(nil)
+
+	want := []string{"healthy -> pull_requests_open", "pull_requests_open -> pull_requests_open|issues_detected", "pull_requests_open|issues_detected -> healthy"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d transitions, want %d", len(got), len(want))
+	}
+	for i, tr := range got {
+		if s := tr.From.String() + " -> " + tr.To.String(); s != want[i] {
+			t.Errorf("transition %d = %q, want %q", i, s, want[i])
+		}
+	}
+}
+
+func TestNextBlinkSignal(t *testing.T) {
+	combined := ClusterState{SignalPullRequestsOpen, SignalCIFailing, SignalIssuesDetected}
+	tests := []struct {
+		name  string
+		state ClusterState
+		last  Signal
+		want  Signal
+	}{
+		{"healthy", nil, SignalHealthy, SignalHealthy},
+		{"single signal", ClusterState{SignalIssuesDetected}, SignalHealthy, SignalIssuesDetected},
+		{"starts at first", combined, SignalHealthy, SignalPullRequestsOpen},
+		{"advances", combined, SignalPullRequestsOpen, SignalCIFailing},
+		{"wraps around", combined, SignalIssuesDetected, SignalPullRequestsOpen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextBlinkSignal(tt.state, tt.last); got != tt.want {
+				t.Errorf("nextBlinkSignal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyCompositions(t *testing.T) {
+	rules := []CompositionRule{
+		{Name: "everything red", Match: []string{"ci_failing", "issues_detected"}, State: "all_red"},
+		{Name: "security first", Match: []string{"security_alerts_open"}, State: "security_alerts_open", Priority: 10},
+		{Name: "any PR signal", Match: []string{"pull_requests_*"}, State: "pull_requests_open", Keep: true},
+	}
+
+	tests := []struct {
+		name  string
+		state ClusterState
+		want  string
+	}{
+		{"no match", ClusterState{SignalIssuesDetected}, "issues_detected"},
+		{"all patterns must match", ClusterState{SignalCIFailing, SignalWarningEvents}, "ci_failing|warning_events"},
+		{"matched states are replaced", ClusterState{SignalPullRequestsOpen, SignalCIFailing, SignalIssuesDetected}, "pull_requests_open|all_red"},
+		{"higher priority moves first", ClusterState{SignalIssuesDetected, SignalSecurityAlertsOpen, SignalCIFailing}, "all_red|security_alerts_open"},
+		{"pattern keeps matched states", ClusterState{SignalIssuesDetected, SignalPullRequestsBlocked}, "pull_requests_open|issues_detected|pull_requests_blocked"},
+		{"healthy is untouched", nil, "healthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyCompositions(tt.state, rules).String(); got != tt.want {
+				t.Errorf("applyCompositions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySchedules(t *testing.T) {
+	evenings := ScheduleRule{From: "18:00", To: "09:00", Only: []string{"issues_detected", "warning_events"}}
+	weekends := ScheduleRule{Days: []string{"sat", "sun"}, Steady: []string{"warning_events"}}
+	rules := []ScheduleRule{evenings, weekends}
+
+	// 2026-03-06 is a Friday
+	tests := []struct {
+		name  string
+		state ClusterState
+		at    time.Time
+		want  string
+	}{
+		{"working hours", ClusterState{SignalPullRequestsOpen, SignalIssuesDetected}, time.Date(2026, 3, 6, 10, 0, 0, 0, time.Local), "pull_requests_open|issues_detected"},
+		{"evening hides PRs", ClusterState{SignalPullRequestsOpen, SignalIssuesDetected}, time.Date(2026, 3, 6, 20, 0, 0, 0, time.Local), "issues_detected"},
+		{"window wraps past midnight", ClusterState{SignalPullRequestsOpen}, time.Date(2026, 3, 6, 8, 59, 0, 0, time.Local), "healthy"},
+		{"weekend warnings leave the blink cycle", ClusterState{SignalIssuesDetected, SignalWarningEvents}, time.Date(2026, 3, 7, 20, 0, 0, 0, time.Local), "issues_detected"},
+		{"weekend warnings alone still show", ClusterState{SignalWarningEvents}, time.Date(2026, 3, 7, 12, 0, 0, 0, time.Local), "warning_events"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applySchedules(tt.state, rules, tt.at).String(); got != tt.want {
+				t.Errorf("applySchedules() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedulesOnlyChangeShownState(t *testing.T) {
+	saved, savedState := config.Schedules, clusterState
+	t.Cleanup(func() { config.Schedules, clusterState = saved, savedState })
+	config.Schedules = []ScheduleRule{{Hide: []string{"pull_requests_open"}}}
+
+	clusterState = ClusterState{SignalPullRequestsOpen, SignalIssuesDetected}
+	if got := shownState().String(); got != "issues_detected" {
+		t.Errorf("shownState() = %q, want issues_detected", got)
+	}
+	if view := currentDisplayView(); view.Signal != SignalIssuesDetected || strings.Contains(view.Message, "pull requests") {
+		t.Errorf("currentDisplayView() = %+v, want only issues_detected", view)
+	}
+}
+
+func TestParseProblemState(t *testing.T) {
+	if state, err := parseProblemState("incidents_open"); err != nil || state != SignalIncidentsOpen {
+		t.Errorf("incidents_open = %q, %v", state, err)
+	}
+	for _, value := range []string{"healthy", "on_fire", ""} {
+		_, err := parseProblemState(value)
+		if err == nil {
+			t.Errorf("%q was accepted", value)
+		} else if !strings.Contains(err.Error(), "issues_detected") || strings.Contains(err.Error(), "healthy") {
+			t.Errorf("%q error %q should list the valid states without healthy", value, err)
+		}
+	}
+}
+
+func TestInitialTransitionIsNotNotified(t *testing.T) {
+	var got []Transition
+	transitionListeners = []func(Transition){func(tr Transition) { got = append(got, tr) }}
+	clusterState, clusterStateKnown = nil, false
+	defer func() { transitionListeners, clusterState = nil, nil }()
+
+	setClusterState(ClusterState{SignalIssuesDetected})
+	setClusterState(nil)
+	if len(got) != 2 || !got[0].Initial || got[1].Initial {
+		t.Fatalf("transitions = %+v, want the first one marked initial", got)
+	}
+
+	defer func(d string) { notifyDriver, recording = d, nil }(notifyDriver)
+	notifyDriver, recording = "recording", nil
+	ntfyTransition(got[0])
+	ntfyTransition(got[1])
+	if len(recording) != 1 {
+		t.Errorf("recorded %d notifications, want only the one of the change: %v", len(recording), recording)
+	}
+}
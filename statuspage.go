@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatusPageConfig publishes a public status page of the components of the cluster, without
+// issue details
+type StatusPageConfig struct {
+	Title      string      `yaml:"title"`      // enables the page at /status and /status.json
+	Components []Component `yaml:"components"` // deprecated, moved to the top-level components
+	GitHub     struct {
+		Repo   string `yaml:"repo"`   // owner/repo the page is pushed to on changes, e.g. for GitHub Pages
+		Branch string `yaml:"branch"` // default gh-pages
+		Path   string `yaml:"path"`   // directory of index.html and status.json, default the root
+	} `yaml:"github"`
+}
+
+// StatusPage is the published status, also served as /status.json
+type StatusPage struct {
+	Title      string            `json:"title"`
+	Status     string            `json:"status"` // worst status of the components
+	Components []ComponentStatus `json:"components"`
+	Updated    time.Time         `json:"updated"`
+}
+
+// ComponentStatus is operational, degraded or outage
+type ComponentStatus struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+var statusPageMu sync.Mutex
+var statusPage *StatusPage
+var statusPageSha = map[string]string{} // blob SHAs of the pushed files, needed to update them
+
+// statusPageEnabled reports whether the config defines a status page
+func statusPageEnabled() bool {
+	return config.StatusPage.Title != ""
+}
+
+// migrateStatusPageComponents moves the components of older configs, which were only used by
+// the status page, to the top-level components
+func migrateStatusPageComponents() configProblems {
+	var problems configProblems
+	sp := &config.StatusPage
+	if len(sp.Components) == 0 {
+		return nil
+	}
+	if len(config.Components) > 0 {
+		problems.addField("components", -1, "status_page.components moved to the top-level components, which are set as well; merge them into components")
+		return problems
+	}
+	log.Printf("Config warning: status_page.components moved to the top-level components, using them as components")
+	config.Components, sp.Components = sp.Components, nil
+	return nil
+}
+
+// validateStatusPage checks the publishing settings and fills in the defaults
+func validateStatusPage() configProblems {
+	var problems configProblems
+	sp := &config.StatusPage
+	if sp.Title == "" {
+		if sp.GitHub.Repo != "" {
+			problems.add(-1, "status_page needs a title")
+		}
+		return problems
+	}
+	if sp.GitHub.Repo != "" {
+		if owner, repo, ok := strings.Cut(sp.GitHub.Repo, "/"); !ok || owner == "" || repo == "" {
+			problems.addField("github", -1, "status_page.github.repo must be owner/repo, got %q", sp.GitHub.Repo)
+		}
+		if ghToken.Get() == "" {
+			problems.addField("github", -1, "status_page.github needs GH_TOKEN with permission to write contents")
+		}
+		if sp.GitHub.Branch == "" {
+			sp.GitHub.Branch = "gh-pages"
+		}
+	}
+	return problems
+}
+
+// updateStatusPage recomputes the status page from the component health, or from all issues as
+// a single Cluster component without components, and publishes it when a component changed
+func updateStatusPage(ctx context.Context, health []ComponentHealth, issues []Issue) {
+	if !statusPageEnabled() {
+		return
+	}
+	if len(config.Components) == 0 {
+		health = []ComponentHealth{{Name: "Cluster", Status: componentStatus(issues)}}
+	}
+	page := &StatusPage{Title: config.StatusPage.Title, Status: "operational", Updated: time.Now()}
+	rank := map[string]int{"operational": 0, "degraded": 1, "outage": 2}
+	for _, h := range health {
+		page.Components = append(page.Components, ComponentStatus{Name: h.Name, Status: h.Status})
+		if rank[h.Status] > rank[page.Status] {
+			page.Status = h.Status
+		}
+	}
+
+	statusPageMu.Lock()
+	changed := statusPage == nil || !slices.Equal(statusPage.Components, page.Components)
+	if !changed {
+		page.Updated = statusPage.Updated
+	}
+	statusPage = page
+	statusPageMu.Unlock()
+
+	if changed {
+		log.Printf("Status page: %s", page.Status)
+		if config.StatusPage.GitHub.Repo != "" {
+			publishStatusPage(ctx, page)
+		}
+	}
+}
+
+// publishStatusPage pushes index.html and status.json to the configured GitHub branch
+func publishStatusPage(ctx context.Context, page *StatusPage) {
+	if !integrationReady("status-page") {
+		return
+	}
+	html, err := renderStatusPage(page)
+	if err != nil {
+		log.Printf("Error rendering status page: %v", err)
+		return
+	}
+	data, _ := json.MarshalIndent(page, "", "  ")
+	for name, content := range map[string][]byte{"index.html": html, "status.json": data} {
+		if err := ghPutFile(ctx, name, content, "Update status: "+page.Status); err != nil {
+			HandleError("status-page", "Error publishing status page:", err)
+			return
+		}
+	}
+	integrationOK("status-page")
+}
+
+// ghPutFile creates or updates a file of the status page branch
+func ghPutFile(ctx context.Context, name string, content []byte, message string) error {
+	gh := config.StatusPage.GitHub
+	filePath := strings.Trim(strings.Trim(gh.Path, "/")+"/"+name, "/")
+	u := fmt.Sprintf("%s/repos/%s/contents/%s", ghApiUrl, gh.Repo, filePath)
+	if statusPageSha[filePath] == "" {
+		// A missing file has no SHA and is created
+		var existing struct {
+			Sha string `json:"sha"`
+		}
+		if err := ghGet(ctx, u+"?ref="+gh.Branch, &existing); err == nil {
+			statusPageSha[filePath] = existing.Sha
+		}
+	}
+	payload := map[string]string{
+		"message": message,
+		"content": base64.StdEncoding.EncodeToString(content),
+		"branch":  gh.Branch,
+	}
+	if sha := statusPageSha[filePath]; sha != "" {
+		payload["sha"] = sha
+	}
+	var updated struct {
+		Content struct {
+			Sha string `json:"sha"`
+		} `json:"content"`
+	}
+	if err := ghSend(ctx, http.MethodPut, u, payload, &updated); err != nil {
+		delete(statusPageSha, filePath)
+		return err
+	}
+	statusPageSha[filePath] = updated.Content.Sha
+	return nil
+}
+
+var statusPageTemplate = template.Must(template.New("status").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 40em; margin: 2em auto; padding: 0 1em; color: #222; }
+.banner { padding: 1em; border-radius: 6px; color: #fff; font-weight: bold; }
+ul { list-style: none; padding: 0; }
+li { display: flex; justify-content: space-between; padding: .75em 0; border-bottom: 1px solid #ddd; }
+.operational { background: #2e9e4f; } .degraded { background: #e0a800; } .outage { background: #d9363e; }
+li span { color: #fff; padding: 0 .5em; border-radius: 4px; }
+footer { color: #777; font-size: .85em; margin-top: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="banner {{.Status}}">{{if eq .Status "operational"}}All systems operational{{else if eq .Status "degraded"}}Some systems degraded{{else}}Outage in progress{{end}}</div>
+<ul>
+{{range .Components}}<li>{{.Name}} <span class="{{.Status}}">{{.Status}}</span></li>
+{{end}}</ul>
+<footer>Updated {{.Updated.UTC.Format "2006-01-02 15:04 UTC"}}</footer>
+</body>
+</html>
+`))
+
+// renderStatusPage renders the page as HTML
+func renderStatusPage(page *StatusPage) ([]byte, error) {
+	var buf bytes.Buffer
+	err := statusPageTemplate.Execute(&buf, page)
+	return buf.Bytes(), err
+}
+
+// statusPageHandler serves the status page as HTML, or as JSON at /status.json; it's public and
+// shows no issue details
+func statusPageHandler(w http.ResponseWriter, r *http.Request) {
+	statusPageMu.Lock()
+	page := statusPage
+	statusPageMu.Unlock()
+	if page == nil {
+		http.Error(w, "no status yet", http.StatusServiceUnavailable)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, ".json") {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+		return
+	}
+	html, err := renderStatusPage(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(html)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStatusPage(t *testing.T) {
+	config.StatusPage = StatusPageConfig{Title: "Lab"}
+	config.Components = []Component{
+		{issueGroup: issueGroup{Name: "Media", Selector: "app.kubernetes.io/part-of=media"}},
+		{issueGroup: issueGroup{Name: "Home", Workloads: []string{"deployment/home-assistant"}}},
+		{issueGroup: issueGroup{Name: "Infra", Namespaces: []string{"kube-system"}, Nodes: true}},
+	}
+	defer func() { config.StatusPage, config.Components, statusPage = StatusPageConfig{}, nil, nil }()
+	if err := validateComponents(); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateStatusPage(); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []Issue{
+		{Type: "Pod", Namespace: "jellyfin", Severity: "warning", labels: map[string]string{"app.kubernetes.io/part-of": "media"}},
+		{Type: "CriticalWorkload", Namespace: "home", Owner: "deployment/home-assistant", Severity: "critical"},
+		{Type: "Provisioning", Namespace: "kube-system", Severity: "info"},
+	}
+	updateComponents(nil, issues[:1], nil, issues[1:])
+	updateStatusPage(context.Background(), componentHealth(), issues)
+
+	w := httptest.NewRecorder()
+	statusPageHandler(w, httptest.NewRequest("GET", "/status.json", nil))
+	var page StatusPage
+	if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"Media": "degraded", "Home": "outage", "Infra": "operational"}
+	for _, c := range page.Components {
+		if c.Status != want[c.Name] {
+			t.Errorf("%s is %s, want %s", c.Name, c.Status, want[c.Name])
+		}
+	}
+	if page.Status != "outage" {
+		t.Errorf("overall status %s, want outage", page.Status)
+	}
+
+	w = httptest.NewRecorder()
+	statusPageHandler(w, httptest.NewRequest("GET", "/status", nil))
+	if body := w.Body.String(); !strings.Contains(body, "Outage in progress") || !strings.Contains(body, "<title>Lab</title>") {
+		t.Errorf("unexpected status page:\n%s", body)
+	}
+}
+
+func TestStatusPageMatchesCheckerOutput(t *testing.T) {
+	defer func(w []string) {
+		criticalWorkloads, criticalWorkloadsDegraded = w, map[string]Issue{}
+		config.StatusPage, config.Components, statusPage = StatusPageConfig{}, nil, nil
+	}(criticalWorkloads)
+	criticalWorkloads = []string{"media/jellyfin"}
+	config.StatusPage = StatusPageConfig{Title: "Lab"}
+	config.Components = []Component{
+		{issueGroup: issueGroup{Name: "Media", Workloads: []string{"deployment/jellyfin"}}},
+		{issueGroup: issueGroup{Name: "Web", Namespaces: []string{"web"}}},
+		{issueGroup: issueGroup{Name: "Infra", Namespaces: []string{"kube-system"}}},
+	}
+	if err := validateComponents(); err != nil {
+		t.Fatal(err)
+	}
+
+	replicas := int32(1)
+	clientset := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "media", Name: "jellyfin"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas}},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "web", Name: "blog", CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour))},
+			Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		},
+	)
+	checkIssues := slices.Concat(checkCriticalWorkloads(context.Background(), clientset), checkLoadBalancers(context.Background(), clientset))
+	updateComponents(nil, nil, nil, checkIssues)
+	updateStatusPage(context.Background(), componentHealth(), checkIssues)
+
+	want := map[string]string{"Media": "outage", "Web": "degraded", "Infra": "operational"}
+	for _, c := range statusPage.Components {
+		if c.Status != want[c.Name] {
+			t.Errorf("%s is %s, want %s", c.Name, c.Status, want[c.Name])
+		}
+	}
+}
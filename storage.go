@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var longhornIssues []Issue // Longhorn issues of the last successful check, kept while the volumes can't be listed
+var cephIssues []Issue     // Ceph issues of the last successful check, kept while the clusters can't be listed
+
+// LonghornVolumeList represents the Longhorn Volume CRs of the cluster
+type LonghornVolumeList struct {
+	Items []struct {
+		Metadata struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+		Status struct {
+			State            string `json:"state"`      // attached, detached, ...
+			Robustness       string `json:"robustness"` // healthy, degraded, faulted, unknown
+			KubernetesStatus struct {
+				Namespace string `json:"namespace"`
+				PVCName   string `json:"pvcName"`
+			} `json:"kubernetesStatus"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// CephClusterList represents the Rook CephCluster CRs of the cluster
+type CephClusterList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+		Status struct {
+			Ceph struct {
+				Health  string `json:"health"` // HEALTH_OK, HEALTH_WARN, HEALTH_ERR
+				Details map[string]struct {
+					Message  string `json:"message"`
+					Severity string `json:"severity"`
+				} `json:"details"`
+			} `json:"ceph"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// checkStorage reports degraded Longhorn volumes and unhealthy Rook Ceph clusters; each
+// backend is only checked when its CRDs are installed. A failed list keeps the issues of the last
+// successful check, so a flaky API server doesn't hide a faulted volume.
+func checkStorage(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	issues := checkLonghornVolumes(ctx, clientset)
+	return append(issues, checkCephClusters(ctx, clientset)...)
+}
+
+// checkLonghornVolumes raises an issue for every degraded or faulted Longhorn volume
+func checkLonghornVolumes(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var volumes LonghornVolumeList
+	found, err := listCustomResources(ctx, clientset, "longhorn.io/v1beta2", "volumes", &volumes)
+	if err != nil {
+		HandleError("longhorn", "Error fetching Longhorn volumes:", err)
+		return longhornIssues
+	}
+	if !found {
+		longhornIssues = nil
+		return nil
+	}
+	integrationOK("longhorn")
+
+	var issues []Issue
+	for _, v := range volumes.Items {
+		severity := ""
+		switch v.Status.Robustness {
+		case "faulted":
+			severity = "critical"
+		case "degraded":
+			severity = "warning"
+		default:
+			continue
+		}
+		name := v.Metadata.Name
+		issue := Issue{Key: "longhorn/" + v.Metadata.Name, Type: "Storage", Severity: severity, Timestamp: time.Now()}
+		if pvc := v.Status.KubernetesStatus; pvc.PVCName != "" {
+			name = fmt.Sprintf("%s (%s/%s)", name, pvc.Namespace, pvc.PVCName)
+			issue.Namespace, issue.Kind, issue.Name = pvc.Namespace, "PersistentVolumeClaim", pvc.PVCName
+		}
+		issue.Message = fmt.Sprintf("Longhorn volume %s is %s", name, v.Status.Robustness)
+		issues = append(issues, issue)
+	}
+	longhornIssues = issues
+	return issues
+}
+
+// checkCephClusters raises an issue for every Rook CephCluster not reporting HEALTH_OK
+func checkCephClusters(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var clusters CephClusterList
+	found, err := listCustomResources(ctx, clientset, "ceph.rook.io/v1", "cephclusters", &clusters)
+	if err != nil {
+		HandleError("ceph", "Error fetching Ceph clusters:", err)
+		return cephIssues
+	}
+	if !found {
+		cephIssues = nil
+		return nil
+	}
+	integrationOK("ceph")
+
+	var issues []Issue
+	for _, c := range clusters.Items {
+		severity := ""
+		switch c.Status.Ceph.Health {
+		case "HEALTH_ERR":
+			severity = "critical"
+		case "HEALTH_WARN":
+			severity = "warning"
+		default:
+			continue
+		}
+		var details []string
+		for check, d := range c.Status.Ceph.Details {
+			details = append(details, fmt.Sprintf("%s: %s", check, d.Message))
+		}
+		sort.Strings(details)
+		message := fmt.Sprintf("Ceph cluster %s/%s is %s", c.Metadata.Namespace, c.Metadata.Name, c.Status.Ceph.Health)
+		if len(details) > 0 {
+			message += " (" + strings.Join(details, "; ") + ")"
+		}
+		issues = append(issues, Issue{Key: fmt.Sprintf("ceph/%s/%s", c.Metadata.Namespace, c.Metadata.Name), Type: "Storage", Severity: severity, Message: message, Timestamp: time.Now()})
+	}
+	cephIssues = issues
+	return issues
+}
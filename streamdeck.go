@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var streamDeckDevice = "" // os.Getenv("STREAMDECK_DEVICE") // auto, or a hidraw path such as /dev/hidraw4
+
+// streamDeckKeys maps key indexes, counted left to right and top to bottom, to their role
+var streamDeckKeys = map[int]string{0: "state", 12: "ack", 13: "snooze", 14: "recheck"} // os.Getenv("STREAMDECK_KEYS")
+
+// streamDeckModels are the supported Elgato Stream Decks by USB product id with their key image size;
+// these all use the JPEG image protocol
+var streamDeckModels = map[uint32]int{
+	0x006d: 72, // Stream Deck Original V2
+	0x0080: 72, // Stream Deck MK.2
+	0x006c: 96, // Stream Deck XL
+	0x008f: 96, // Stream Deck XL V2
+}
+
+const streamDeckVendorID = 0x0fd9
+
+// streamDeckActionColors are the key colors of the actions
+var streamDeckActionColors = map[string][3]int{
+	"ack":     {0, 90, 0},
+	"snooze":  {60, 60, 60},
+	"recheck": {0, 60, 120},
+}
+
+// streamDeckDisplay paints state keys with the cluster state color and count, and maps
+// presses of action keys to the ack, snooze and recheck actions
+type streamDeckDisplay struct {
+	path      string
+	size      int
+	keysDrawn bool
+}
+
+// parseStreamDeckKeys parses STREAMDECK_KEYS, comma-separated key=role pairs
+func parseStreamDeckKeys(value string) error {
+	keys := map[int]string{}
+	for _, entry := range splitList(value) {
+		k, role, ok := strings.Cut(entry, "=")
+		key, err := strconv.Atoi(strings.TrimSpace(k))
+		if !ok || err != nil || key < 0 {
+			return fmt.Errorf("expected key=role, got %q", entry)
+		}
+		role = strings.TrimSpace(role)
+		if role != "state" && role != "ack" && role != "snooze" && role != "recheck" {
+			return fmt.Errorf("unknown role %q, expected state, ack, snooze or recheck", role)
+		}
+		keys[key] = role
+	}
+	streamDeckKeys = keys
+	return nil
+}
+
+// newStreamDeckDisplay opens the configured Stream Deck, or the first one found when device is "auto",
+// and starts listening for key presses
+func newStreamDeckDisplay(device string) (*streamDeckDisplay, error) {
+	path, _, productID, err := findHIDDevice(device, func(vendorID, productID uint32) bool {
+		_, ok := streamDeckModels[productID]
+		return vendorID == streamDeckVendorID && ok
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no supported Stream Deck found: %w", err)
+	}
+	deck := &streamDeckDisplay{path: path, size: streamDeckModels[productID]}
+	go deck.readKeys()
+	return deck, nil
+}
+
+func (d *streamDeckDisplay) Name() string {
+	return "Stream Deck"
+}
+
+func (d *streamDeckDisplay) Show(ctx context.Context, view DisplayView) error {
+	f, err := os.OpenFile(d.path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", d.path, err)
+	}
+	defer f.Close()
+
+	for key, role := range streamDeckKeys {
+		switch {
+		case role == "state":
+			err = d.setKeyImage(f, key, view.Color, fmt.Sprintf("%d", min(view.Count, 999)))
+		case !d.keysDrawn:
+			err = d.setKeyImage(f, key, streamDeckActionColors[role], strings.ToUpper(role[:1]))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	d.keysDrawn = true
+	return nil
+}
+
+// setKeyImage paints a key with the color and a label, sent as a JPEG in 1024 byte reports
+func (d *streamDeckDisplay) setKeyImage(f *os.File, key int, fill [3]int, label string) error {
+	img := image.NewRGBA(image.Rect(0, 0, d.size, d.size))
+	bg := color.RGBA{uint8(fill[0]), uint8(fill[1]), uint8(fill[2]), 255}
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, bg.A
+	}
+	drawLabel(img, label)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 90}); err != nil {
+		return fmt.Errorf("failed to encode key image: %w", err)
+	}
+
+	data := buf.Bytes()
+	for page := 0; len(data) > 0; page++ {
+		n := min(len(data), 1016)
+		last := byte(0)
+		if n == len(data) {
+			last = 1
+		}
+		report := make([]byte, 1024)
+		copy(report, []byte{0x02, 0x07, byte(key), last, byte(n), byte(n >> 8), byte(page), byte(page >> 8)})
+		copy(report[8:], data[:n])
+		if _, err := f.Write(report); err != nil {
+			return fmt.Errorf("failed to write key image to %s: %w", d.path, err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// readKeys turns key presses into user actions; input reports carry one byte per key from offset 4
+func (d *streamDeckDisplay) readKeys() {
+	f, err := os.Open(d.path)
+	if err != nil {
+		log.Printf("Error opening %s for key presses: %v", d.path, err)
+		return
+	}
+	defer f.Close()
+
+	pressed := map[int]bool{}
+	report := make([]byte, 512)
+	for {
+		n, err := f.Read(report)
+		if err != nil {
+			log.Printf("Error reading key presses from %s: %v", d.path, err)
+			return
+		}
+		if n < 4 || report[0] != 0x01 {
+			continue
+		}
+		for key, state := range report[4:n] {
+			down := state != 0
+			if down && !pressed[key] {
+				if role := streamDeckKeys[key]; role != "" && role != "state" {
+					requestUserAction(role)
+				}
+			}
+			pressed[key] = down
+		}
+	}
+}
+
+// streamDeckGlyphs is a 3x5 pixel font for key labels
+var streamDeckGlyphs = map[rune][5]string{
+	'0': {"111", "101", "101", "101", "111"},
+	'1': {"010", "110", "010", "010", "111"},
+	'2': {"111", "001", "111", "100", "111"},
+	'3': {"111", "001", "111", "001", "111"},
+	'4': {"101", "101", "111", "001", "001"},
+	'5': {"111", "100", "111", "001", "111"},
+	'6': {"111", "100", "111", "101", "111"},
+	'7': {"111", "001", "001", "001", "001"},
+	'8': {"111", "101", "111", "101", "111"},
+	'9': {"111", "101", "111", "001", "111"},
+	'A': {"010", "101", "111", "101", "101"},
+	'S': {"011", "100", "010", "001", "110"},
+	'R': {"110", "101", "110", "101", "101"},
+}
+
+// drawLabel draws the label centered in white; Stream Decks show images rotated by 180°,
+// so the pixels are drawn mirrored on both axes
+func drawLabel(img *image.RGBA, label string) {
+	size := img.Bounds().Dx()
+	scale := size / (4*len(label) + 2)
+	scale = min(scale, size/8)
+	width := (4*len(label) - 1) * scale
+	x0, y0 := (size-width)/2, (size-5*scale)/2
+
+	white := color.RGBA{255, 255, 255, 255}
+	for i, r := range label {
+		glyph := streamDeckGlyphs[r]
+		for row, line := range glyph {
+			for col, bit := range line {
+				if bit != '1' {
+					continue
+				}
+				for dy := range scale {
+					for dx := range scale {
+						x := x0 + (4*i+col)*scale + dx
+						y := y0 + row*scale + dy
+						img.SetRGBA(size-1-x, size-1-y, white)
+					}
+				}
+			}
+		}
+	}
+}
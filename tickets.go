@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+)
+
+// ticketTracker files work items for long-lived critical issues, e.g. GitHub issues, and resolves
+// them once the issue clears. Tickets carry the issue key so they are found again after a restart.
+type ticketTracker interface {
+	Name() string
+	After() time.Duration                                       // how long an issue is active before a ticket is filed
+	OpenTickets(ctx context.Context) (map[string]string, error) // issue key to ticket reference
+	Open(ctx context.Context, issue Issue) (string, error)
+	Resolve(ctx context.Context, ref string, key string) error
+}
+
+var ticketTrackers []ticketTracker
+
+// ticketsSyncInterval bounds how often the trackers are queried for their open tickets
+const ticketsSyncInterval = time.Minute
+
+var ticketsLastSync time.Time
+
+// ticketResolveAfter is the number of consecutive syncs an issue must be gone for before its ticket
+// is resolved, so a flapping issue doesn't close and reopen tickets
+const ticketResolveAfter = 3
+
+// ticketsAbsent counts the consecutive syncs the issue of each open ticket was gone, by tracker
+var ticketsAbsent = map[string]map[string]int{}
+
+// ticketKeyMarker is embedded in ticket descriptions to map tickets back to their issue key
+var ticketKeyMarker = regexp.MustCompile(`clusterbulb:key=(\S+?)(?:\s|-->|$)`)
+
+// ticketMarker returns the marker for the issue key
+func ticketMarker(key string) string {
+	return fmt.Sprintf("<!-- clusterbulb:key=%s -->", key)
+}
+
+// ticketEligible reports whether the issue is critical and has been active for at least after
+func ticketEligible(issue Issue, after time.Duration, now time.Time) bool {
+	critical := issue.Severity == "critical" || issue.Type == "Node"
+	return critical && !issue.FirstSeen.IsZero() && now.Sub(issue.FirstSeen) >= after
+}
+
+// syncTickets opens tickets for long-lived critical issues and resolves the tickets of issues
+// that have been gone for ticketResolveAfter syncs. While an integration fails, e.g. a checker
+// that can't reach its API, the issues it would report are unknown and no ticket is resolved.
+func syncTickets(ctx context.Context, active []Issue) {
+	if len(ticketTrackers) == 0 || time.Since(ticketsLastSync) < ticketsSyncInterval {
+		return
+	}
+	ticketsLastSync = time.Now()
+
+	activeKeys := map[string]bool{}
+	for _, issue := range active {
+		activeKeys[issue.Key] = true
+	}
+	var trackers []string
+	for _, t := range ticketTrackers {
+		trackers = append(trackers, t.Name())
+	}
+	complete := !integrationsFailing(trackers...)
+	for _, t := range ticketTrackers {
+		if !integrationReady(t.Name()) {
+			continue
+		}
+		cycle := &integrationCycle{integration: t.Name()}
+		open, err := t.OpenTickets(ctx)
+		if err != nil {
+			cycle.fail("Error listing tickets:", err)
+			continue
+		}
+
+		for _, issue := range active {
+			if _, ok := open[issue.Key]; ok || !ticketEligible(issue, t.After(), time.Now()) {
+				continue
+			}
+			ref, err := t.Open(ctx, issue)
+			if err != nil {
+				cycle.fail(fmt.Sprintf("Error opening ticket for %s:", issue.Key), err)
+				continue
+			}
+			open[issue.Key] = ref
+			log.Printf("Opened %s ticket %s for %s", t.Name(), ref, issue.Key)
+		}
+		absent := map[string]int{}
+		for key, ref := range open {
+			if activeKeys[key] {
+				continue
+			}
+			absent[key] = ticketsAbsent[t.Name()][key]
+			if !complete {
+				continue
+			}
+			if absent[key]++; absent[key] < ticketResolveAfter {
+				continue
+			}
+			if err := t.Resolve(ctx, ref, key); err != nil {
+				cycle.fail(fmt.Sprintf("Error resolving ticket %s:", ref), err)
+				continue
+			}
+			delete(absent, key)
+			log.Printf("Resolved %s ticket %s for %s", t.Name(), ref, key)
+		}
+		ticketsAbsent[t.Name()] = absent
+		cycle.done()
+	}
+}
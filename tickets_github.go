@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var ghTicketRepo = ""                // os.Getenv("GH_TICKET_REPO") // owner/repo to open issues in, empty disables
+var ghTicketLabel = "clusterbulb"    // os.Getenv("GH_TICKET_LABEL") // label of the opened issues
+var ghTicketAfter = 30 * time.Minute // os.Getenv("GH_TICKET_AFTER_MINUTES") // how long a critical issue is active before an issue is opened
+
+// githubTickets opens GitHub issues in GH_TICKET_REPO
+type githubTickets struct{}
+
+func (githubTickets) Name() string         { return "github-tickets" }
+func (githubTickets) After() time.Duration { return ghTicketAfter }
+
+// OpenTickets lists the open issues carrying GH_TICKET_LABEL by the issue key in their body
+func (githubTickets) OpenTickets(ctx context.Context) (map[string]string, error) {
+	u := fmt.Sprintf("%s/repos/%s/issues?state=open&per_page=100&labels=%s", ghApiUrl, ghTicketRepo, url.QueryEscape(ghTicketLabel))
+	issues, err := ghGetAll[struct {
+		Number int    `json:"number"`
+		Body   string `json:"body"`
+	}](ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	open := map[string]string{}
+	for _, i := range issues {
+		if m := ticketKeyMarker.FindStringSubmatch(i.Body); m != nil {
+			open[m[1]] = strconv.Itoa(i.Number)
+		}
+	}
+	return open, nil
+}
+
+// Open creates an issue with the issue key embedded in its body
+func (githubTickets) Open(ctx context.Context, issue Issue) (string, error) {
+	details := fmt.Sprintf("- Key: `%s`\n- Type: %s\n- First seen: %s", issue.Key, issue.Type, issue.FirstSeen.Format(time.RFC3339))
+	if issue.Runbook != "" {
+		details += "\n- Runbook: " + issue.Runbook
+	}
+	body := fmt.Sprintf("%s\n\n%s\n\nOpened by clusterbulb, closed automatically once the issue clears.\n\n%s", issue.Message, details, ticketMarker(issue.Key))
+	var created struct {
+		Number int `json:"number"`
+	}
+	err := ghSend(ctx, "POST", fmt.Sprintf("%s/repos/%s/issues", ghApiUrl, ghTicketRepo), map[string]interface{}{
+		"title":  issue.Message,
+		"body":   body,
+		"labels": []string{ghTicketLabel},
+	}, &created)
+	return strconv.Itoa(created.Number), err
+}
+
+// Resolve comments on the issue and closes it
+func (githubTickets) Resolve(ctx context.Context, ref string, key string) error {
+	u := fmt.Sprintf("%s/repos/%s/issues/%s", ghApiUrl, ghTicketRepo, ref)
+	if err := ghSend(ctx, "POST", u+"/comments", map[string]string{"body": fmt.Sprintf("`%s` cleared at %s.", key, time.Now().Format(time.RFC3339))}, nil); err != nil {
+		return err
+	}
+	return ghSend(ctx, "PATCH", u, map[string]string{"state": "closed", "state_reason": "completed"}, nil)
+}
+
+// ghSend performs an authenticated GitHub API request with a JSON payload and decodes the
+// response into v unless it is nil
+func ghSend(ctx context.Context, method, url string, payload interface{}, v interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+ghToken.Get())
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient("github").Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	ghUpdateRateLimit(resp)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("GitHub API returned status: %s", resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var jiraUrl = ""                 // os.Getenv("JIRA_URL") // Jira base URL, e.g. https://example.atlassian.net, empty disables
+var jiraUser = ""                // os.Getenv("JIRA_USER") // Jira account email
+var jiraApiToken = ""            // os.Getenv("JIRA_API_TOKEN") // Jira API token
+var jiraProject = ""             // os.Getenv("JIRA_PROJECT") // project key tickets are created in
+var jiraIssueType = "Task"       // os.Getenv("JIRA_ISSUE_TYPE") // issue type of the created tickets
+var jiraDoneTransition = "Done"  // os.Getenv("JIRA_DONE_TRANSITION") // transition applied once the issue clears
+var jiraAfter = 30 * time.Minute // os.Getenv("JIRA_AFTER_MINUTES") // how long a critical issue is active before a ticket is created
+
+// jiraLabel marks the tickets created by clusterbulb
+const jiraLabel = "clusterbulb"
+
+// jiraTickets creates Jira tickets in JIRA_PROJECT
+type jiraTickets struct{}
+
+func (jiraTickets) Name() string         { return "jira" }
+func (jiraTickets) After() time.Duration { return jiraAfter }
+
+// OpenTickets searches the unresolved clusterbulb tickets of the project by the issue key in their
+// description, following the nextPageToken of the enhanced JQL search
+func (jiraTickets) OpenTickets(ctx context.Context) (map[string]string, error) {
+	jql := fmt.Sprintf(`project = "%s" AND labels = %s AND statusCategory != Done`, jiraProject, jiraLabel)
+	open := map[string]string{}
+	pageToken := ""
+	for {
+		path := "/rest/api/3/search/jql?maxResults=100&fields=description&jql=" + url.QueryEscape(jql)
+		if pageToken != "" {
+			path += "&nextPageToken=" + url.QueryEscape(pageToken)
+		}
+		req, err := jiraRequest(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Issues []struct {
+				Key    string `json:"key"`
+				Fields struct {
+					Description interface{} `json:"description"` // Atlassian Document Format
+				} `json:"fields"`
+			} `json:"issues"`
+			NextPageToken string `json:"nextPageToken"`
+			IsLast        bool   `json:"isLast"`
+		}
+		if err := httpGetJSON("Jira", req, &result); err != nil {
+			return nil, err
+		}
+		for _, i := range result.Issues {
+			if m := ticketKeyMarker.FindStringSubmatch(jiraText(i.Fields.Description)); m != nil {
+				open[m[1]] = i.Key
+			}
+		}
+		if result.IsLast || result.NextPageToken == "" {
+			return open, nil
+		}
+		pageToken = result.NextPageToken
+	}
+}
+
+// jiraText returns the text of an Atlassian Document Format node, one text node per line
+func jiraText(node interface{}) string {
+	switch n := node.(type) {
+	case string:
+		return n
+	case map[string]interface{}:
+		if text, ok := n["text"].(string); ok {
+			return text
+		}
+		return jiraText(n["content"])
+	case []interface{}:
+		var lines []string
+		for _, child := range n {
+			if text := jiraText(child); text != "" {
+				lines = append(lines, text)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return ""
+}
+
+// Open creates a ticket with the issue key in its description
+func (jiraTickets) Open(ctx context.Context, issue Issue) (string, error) {
+	// Jira doesn't render HTML comments, the marker is kept as plain text on the last line
+	details := fmt.Sprintf("* Key: {{%s}}\n* Type: %s\n* First seen: %s", issue.Key, issue.Type, issue.FirstSeen.Format(time.RFC3339))
+	if issue.Runbook != "" {
+		details += "\n* Runbook: " + issue.Runbook
+	}
+	description := fmt.Sprintf("%s\n\n%s\n\nCreated by clusterbulb, transitioned automatically once the issue clears.\n\nclusterbulb:key=%s", issue.Message, details, issue.Key)
+	var created struct {
+		Key string `json:"key"`
+	}
+	err := jiraSend(ctx, http.MethodPost, "/rest/api/2/issue", map[string]interface{}{
+		"fields": map[string]interface{}{
+			"project":     map[string]string{"key": jiraProject},
+			"issuetype":   map[string]string{"name": jiraIssueType},
+			"summary":     issue.Message,
+			"description": description,
+			"labels":      []string{jiraLabel},
+		},
+	}, &created)
+	return created.Key, err
+}
+
+// Resolve applies JIRA_DONE_TRANSITION to the ticket with a comment
+func (jiraTickets) Resolve(ctx context.Context, ref string, key string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(ref) + "/transitions"
+	req, err := jiraRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	var transitions struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"transitions"`
+	}
+	if err := httpGetJSON("Jira", req, &transitions); err != nil {
+		return err
+	}
+	for _, t := range transitions.Transitions {
+		if !strings.EqualFold(t.Name, jiraDoneTransition) {
+			continue
+		}
+		return jiraSend(ctx, http.MethodPost, path, map[string]interface{}{
+			"transition": map[string]string{"id": t.ID},
+			"update": map[string]interface{}{
+				"comment": []interface{}{map[string]interface{}{"add": map[string]string{
+					"body": fmt.Sprintf("{{%s}} cleared at %s.", key, time.Now().Format(time.RFC3339)),
+				}}},
+			},
+		}, nil)
+	}
+	return fmt.Errorf("transition '%s' not available for %s", jiraDoneTransition, ref)
+}
+
+// jiraRequest builds an authenticated Jira API request
+func jiraRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(jiraUrl, "/")+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(jiraUser, jiraApiToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// jiraSend sends a JSON payload to the Jira API and decodes the response into v unless it is nil
+func jiraSend(ctx context.Context, method, path string, payload interface{}, v interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	req, err := jiraRequest(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient("jira").Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Jira API returned status: %s", resp.Status)
+	}
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
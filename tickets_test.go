@@ -0,0 +1,228 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeTracker struct {
+	open     map[string]string
+	resolved []string
+}
+
+func (f *fakeTracker) Name() string         { return "fake-tickets" }
+func (f *fakeTracker) After() time.Duration { return 10 * time.Minute }
+func (f *fakeTracker) OpenTickets(ctx context.Context) (map[string]string, error) {
+	open := map[string]string{}
+	for k, v := range f.open {
+		open[k] = v
+	}
+	return open, nil
+}
+func (f *fakeTracker) Open(ctx context.Context, issue Issue) (string, error) {
+	f.open[issue.Key] = issue.Key
+	return issue.Key, nil
+}
+func (f *fakeTracker) Resolve(ctx context.Context, ref string, key string) error {
+	delete(f.open, key)
+	f.resolved = append(f.resolved, key)
+	return nil
+}
+
+func TestSyncTickets(t *testing.T) {
+	f := &fakeTracker{open: map[string]string{"node/gone/notready": "1"}}
+	ticketTrackers = []ticketTracker{f}
+	defer func() { ticketTrackers, ticketsLastSync, ticketsAbsent = nil, time.Time{}, map[string]map[string]int{} }()
+	defer func(saved map[string]*IntegrationHealth) { integrations = saved }(integrations)
+	integrations = map[string]*IntegrationHealth{}
+
+	old := time.Now().Add(-time.Hour)
+	for range ticketResolveAfter {
+		ticketsLastSync = time.Time{}
+		syncTickets(context.Background(), []Issue{
+			{Key: "pod/prod/api/crashloop", Severity: "critical", FirstSeen: old},
+			{Key: "pod/prod/web/crashloop", Severity: "critical", FirstSeen: time.Now()},
+			{Key: "pod/prod/job/pending", Severity: "warning", FirstSeen: old},
+		})
+	}
+	if len(f.open) != 1 || f.open["pod/prod/api/crashloop"] == "" {
+		t.Errorf("open tickets %v, want only the long-lived critical issue", f.open)
+	}
+	if len(f.resolved) != 1 || f.resolved[0] != "node/gone/notready" {
+		t.Errorf("resolved %v, want the cleared node", f.resolved)
+	}
+
+	if m := ticketKeyMarker.FindStringSubmatch("text\n\n" + ticketMarker("pod/a/b/crashloop")); m == nil || m[1] != "pod/a/b/crashloop" {
+		t.Errorf("marker round trip gave %v", m)
+	}
+}
+
+func TestSyncTicketsFlappingIssue(t *testing.T) {
+	f := &fakeTracker{open: map[string]string{"pod/prod/api/crashloop": "1"}}
+	ticketTrackers = []ticketTracker{f}
+	defer func() { ticketTrackers, ticketsLastSync, ticketsAbsent = nil, time.Time{}, map[string]map[string]int{} }()
+	defer func(saved map[string]*IntegrationHealth) { integrations = saved }(integrations)
+	integrations = map[string]*IntegrationHealth{}
+
+	crashing := []Issue{{Key: "pod/prod/api/crashloop", Severity: "critical", FirstSeen: time.Now().Add(-time.Hour)}}
+	sync := func(active []Issue) {
+		ticketsLastSync = time.Time{}
+		syncTickets(context.Background(), active)
+	}
+
+	// Gone, back and gone again never reaches ticketResolveAfter consecutive syncs
+	for _, active := range [][]Issue{nil, nil, crashing, nil, nil} {
+		sync(active)
+	}
+	if len(f.resolved) != 0 {
+		t.Fatalf("resolved %v while the issue flapped", f.resolved)
+	}
+
+	// A failing checker may hide the issue, the absence isn't counted
+	integrations["alertmanager"] = &IntegrationHealth{Failures: 1}
+	sync(nil)
+	if len(f.resolved) != 0 {
+		t.Fatalf("resolved %v while a checker failed", f.resolved)
+	}
+
+	delete(integrations, "alertmanager")
+	sync(nil)
+	if len(f.resolved) != 1 || f.resolved[0] != "pod/prod/api/crashloop" {
+		t.Errorf("resolved %v, want the ticket once the issue was gone for %d syncs", f.resolved, ticketResolveAfter)
+	}
+}
+
+func TestJiraTickets(t *testing.T) {
+	defer func(u, user, token, project string) {
+		jiraUrl, jiraUser, jiraApiToken, jiraProject = u, user, token, project
+	}(jiraUrl, jiraUser, jiraApiToken, jiraProject)
+	jiraUser, jiraApiToken, jiraProject = "bot@example.com", "secret", "OPS"
+
+	var created map[string]interface{}
+	var transitioned string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "bot@example.com" || token != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /rest/api/3/search/jql":
+			if jql := r.URL.Query().Get("jql"); jql != `project = "OPS" AND labels = clusterbulb AND statusCategory != Done` {
+				t.Errorf("jql = %s", jql)
+			}
+			// Two pages, descriptions in Atlassian Document Format
+			if r.URL.Query().Get("nextPageToken") == "" {
+				fmt.Fprint(w, `{"issues":[{"key":"OPS-1","fields":{"description":{"type":"doc","version":1,"content":[
+					{"type":"paragraph","content":[{"type":"text","text":"Pod crashing"}]},
+					{"type":"paragraph","content":[{"type":"text","text":"clusterbulb:key=pod/prod/api/crashloop"}]}]}}},
+					{"key":"OPS-2","fields":{"description":null}}],"nextPageToken":"page-2","isLast":false}`)
+				return
+			}
+			fmt.Fprint(w, `{"issues":[{"key":"OPS-3","fields":{"description":{"type":"doc","version":1,"content":[
+				{"type":"paragraph","content":[{"type":"text","text":"clusterbulb:key=node/worker-1/notready"}]}]}}}],"isLast":true}`)
+		case "POST /rest/api/2/issue":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"id":"10004","key":"OPS-4"}`)
+		case "GET /rest/api/2/issue/OPS-1/transitions":
+			fmt.Fprint(w, `{"transitions":[{"id":"21","name":"In Progress"},{"id":"31","name":"Done"}]}`)
+		case "POST /rest/api/2/issue/OPS-1/transitions":
+			var payload struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			transitioned = payload.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /rest/api/2/issue/OPS-3/transitions":
+			fmt.Fprint(w, `{"transitions":[{"id":"21","name":"In Progress"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	jiraUrl = srv.URL + "/"
+
+	tracker := jiraTickets{}
+	open, err := tracker.OpenTickets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 2 || open["pod/prod/api/crashloop"] != "OPS-1" || open["node/worker-1/notready"] != "OPS-3" {
+		t.Errorf("open tickets = %v, want the marked tickets of both pages", open)
+	}
+
+	ref, err := tracker.Open(context.Background(), Issue{Key: "pod/prod/web/crashloop", Type: "Pod", Message: "web is crash looping", FirstSeen: time.Now()})
+	if err != nil || ref != "OPS-4" {
+		t.Fatalf("Open = %q, %v", ref, err)
+	}
+	fields, _ := created["fields"].(map[string]interface{})
+	if fields["summary"] != "web is crash looping" || !strings.HasSuffix(fields["description"].(string), "clusterbulb:key=pod/prod/web/crashloop") {
+		t.Errorf("created fields = %v", fields)
+	}
+
+	if err := tracker.Resolve(context.Background(), "OPS-1", "pod/prod/api/crashloop"); err != nil || transitioned != "31" {
+		t.Errorf("Resolve = %v, transitioned %q, want the Done transition", err, transitioned)
+	}
+	if err := tracker.Resolve(context.Background(), "OPS-3", "node/worker-1/notready"); err == nil {
+		t.Error("resolved a ticket without a Done transition")
+	}
+}
+
+func TestGithubTickets(t *testing.T) {
+	defer func(u, repo string) { ghApiUrl, ghTicketRepo = u, repo }(ghApiUrl, ghTicketRepo)
+	ghTicketRepo = "homelab/cluster"
+
+	var created map[string]interface{}
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/homelab/cluster/issues":
+			if r.URL.Query().Get("labels") != "clusterbulb" || r.URL.Query().Get("state") != "open" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `[{"number":7,"body":"Pod crashing\n\n<!-- clusterbulb:key=pod/prod/api/crashloop -->"},{"number":8,"body":"opened by hand"}]`)
+		case "POST /repos/homelab/cluster/issues":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"number":9}`)
+		case "POST /repos/homelab/cluster/issues/7/comments", "PATCH /repos/homelab/cluster/issues/7":
+			fmt.Fprint(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ghApiUrl = srv.URL
+
+	tracker := githubTickets{}
+	open, err := tracker.OpenTickets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(open) != 1 || open["pod/prod/api/crashloop"] != "7" {
+		t.Errorf("open tickets = %v, want the marked issue", open)
+	}
+
+	ref, err := tracker.Open(context.Background(), Issue{Key: "pod/prod/web/crashloop", Type: "Pod", Message: "web is crash looping", FirstSeen: time.Now()})
+	if err != nil || ref != "9" {
+		t.Fatalf("Open = %q, %v", ref, err)
+	}
+	if created["title"] != "web is crash looping" || !strings.HasSuffix(created["body"].(string), ticketMarker("pod/prod/web/crashloop")) {
+		t.Errorf("created issue = %v", created)
+	}
+
+	calls = nil
+	if err := tracker.Resolve(context.Background(), "7", "pod/prod/api/crashloop"); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "POST /repos/homelab/cluster/issues/7/comments" || calls[1] != "PATCH /repos/homelab/cluster/issues/7" {
+		t.Errorf("calls = %v, want a comment then closing the issue", calls)
+	}
+}
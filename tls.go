@@ -0,0 +1,51 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+var tlsCAFile = ""                // os.Getenv("TLS_CA_FILE") // PEM bundle trusted in addition to the system roots
+var tlsInsecureSkipVerify = false // os.Getenv("TLS_INSECURE_SKIP_VERIFY") == "true" // explicit opt-in, disables certificate verification
+
+// configureOutboundTLS applies HTTP(S)_PROXY/NO_PROXY and the custom TLS settings to the default
+// transport, which every outbound HTTP call (Home Assistant, ntfy, SCM providers, probes) uses.
+// The Kubernetes client has its own transport and is not affected.
+func configureOutboundTLS() error {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected default transport %T", http.DefaultTransport)
+	}
+	transport.Proxy = http.ProxyFromEnvironment
+
+	if tlsCAFile == "" && !tlsInsecureSkipVerify {
+		return nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if tlsCAFile != "" {
+		pem, err := os.ReadFile(tlsCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read TLS_CA_FILE: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in TLS_CA_FILE %s", tlsCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if tlsInsecureSkipVerify {
+		log.Printf("WARNING: TLS_INSECURE_SKIP_VERIFY is set, outbound TLS certificates are not verified")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	transport.TLSClientConfig = tlsConfig
+	return nil
+}
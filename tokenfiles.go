@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var tokenReloadInterval = 5 * time.Minute // os.Getenv("TOKEN_RELOAD_MINUTES") // how often the *_TOKEN_FILE files are re-read
+
+// secretToken is a token that the scheduler reloads while the HTTP handlers, the gRPC server and
+// the notifications read it
+type secretToken struct {
+	mu    sync.RWMutex
+	value string
+}
+
+// Get returns the current token, empty when unset
+func (t *secretToken) Get() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.value
+}
+
+// Set replaces the token
+func (t *secretToken) Set(value string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.value = value
+}
+
+// tokenFile keeps a token in sync with a file, e.g. a key of a mounted Kubernetes secret, which
+// the kubelet updates in place when the secret is rotated
+type tokenFile struct {
+	name  string       // env var of the token, e.g. HA_TOKEN
+	path  string       // os.Getenv(name + "_FILE")
+	token *secretToken // the variable the integration reads
+}
+
+var tokenFiles []*tokenFile
+
+// tokenReload asks the scheduler to re-read the token files, e.g. after a 401 response
+var tokenReload = make(chan struct{}, 1)
+
+// tokenReloadAsked throttles the reloads asked for by 401 responses, which arrive from any
+// goroutine making requests
+var (
+	tokenReloadAsked   time.Time
+	tokenReloadAskedMu sync.Mutex
+)
+
+// addTokenFile reads the token from path and registers it for reloading; an empty path keeps
+// the token of the env var
+func addTokenFile(name, path string, token *secretToken) error {
+	if path == "" {
+		return nil
+	}
+	t := &tokenFile{name: name, path: path, token: token}
+	value, err := readTokenFile(path)
+	if err != nil {
+		return err
+	}
+	token.Set(value)
+	tokenFiles = append(tokenFiles, t)
+	return nil
+}
+
+// readTokenFile returns the trimmed contents of the token file
+func readTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// reloadTokens re-reads every token file, keeping the current token when a file can't be read,
+// e.g. while the kubelet swaps the secret. It runs on the scheduler goroutine, which makes most
+// of the calls using the tokens.
+func reloadTokens() {
+	for _, t := range tokenFiles {
+		value, err := readTokenFile(t.path)
+		if err != nil {
+			log.Printf("Error reloading %s: %v", t.name, err)
+			continue
+		}
+		if value != t.token.Get() {
+			t.token.Set(value)
+			log.Printf("Reloaded %s from %s", t.name, t.path)
+		}
+	}
+}
+
+// requestTokenReload asks for the token files to be re-read at most every 30 seconds, so a
+// revoked token doesn't turn every request into a file read
+func requestTokenReload(integration string) {
+	if len(tokenFiles) == 0 {
+		return
+	}
+	tokenReloadAskedMu.Lock()
+	throttled := time.Since(tokenReloadAsked) < 30*time.Second
+	if !throttled {
+		tokenReloadAsked = time.Now()
+	}
+	tokenReloadAskedMu.Unlock()
+	if throttled {
+		return
+	}
+	log.Printf("%s rejected the token, reloading the token files", integration)
+	select {
+	case tokenReload <- struct{}{}:
+	default:
+	}
+}
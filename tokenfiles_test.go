@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var token secretToken
+	defer func() { tokenFiles, tokenReloadAsked = nil, time.Time{} }()
+
+	if err := addTokenFile("HA_TOKEN", path, &token); err != nil || token.Get() != "old" {
+		t.Fatalf("token %q, err %v", token.Get(), err)
+	}
+
+	// A 401 asks the scheduler to reload, a second one right after doesn't
+	if err := os.WriteFile(path, []byte("new"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	requestTokenReload("homeassistant")
+	requestTokenReload("homeassistant")
+	select {
+	case <-tokenReload:
+	default:
+		t.Fatal("no reload requested after a 401")
+	}
+	if len(tokenReload) != 0 {
+		t.Error("reload requested twice within the throttle window")
+	}
+	reloadTokens()
+	if token.Get() != "new" {
+		t.Errorf("token %q after the reload, want new", token.Get())
+	}
+
+	// An unreadable file keeps the current token
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	reloadTokens()
+	if token.Get() != "new" {
+		t.Errorf("token %q after the file vanished, want new", token.Get())
+	}
+}
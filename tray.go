@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"strings"
+	"time"
+)
+
+// trayInterval is how often the tray icon polls the server
+var trayInterval = 10 * time.Second
+
+// trayIssuesShown is the number of issues listed in the tray menu
+const trayIssuesShown = 10
+
+// trayView is what the tray shows: the icon color, the tooltip and one menu line per issue
+type trayView struct {
+	Color   [3]int
+	Tooltip string
+	Lines   []string
+}
+
+// trayErrorColor is the icon color while the server can't be reached
+var trayErrorColor = [3]int{128, 128, 128}
+
+// trayFetch reads the state and issues of the running instance, preferring issues_detected as
+// the icon color like the displays do
+func trayFetch(c *cliClient) (trayView, error) {
+	var report HealthReport
+	if err := c.get("/api/v1/report", &report); err != nil {
+		return trayView{Color: trayErrorColor, Tooltip: "clusterbulb: " + err.Error()}, err
+	}
+	var issues []Issue
+	if err := c.get("/api/v1/issues", &issues); err != nil {
+		return trayView{Color: trayErrorColor, Tooltip: "clusterbulb: " + err.Error()}, err
+	}
+
+	signals := strings.Split(report.ClusterState, "|")
+	signal := Signal(signals[0])
+	if slices.Contains(signals, string(SignalIssuesDetected)) {
+		signal = SignalIssuesDetected
+	}
+	color, ok := stateColor(signal)
+	if !ok {
+		color = trayErrorColor
+	}
+	view := trayView{Color: color, Tooltip: fmt.Sprintf("clusterbulb: %s, %d issues", report.ClusterState, len(issues))}
+	for i, issue := range issues {
+		if i == trayIssuesShown {
+			view.Lines = append(view.Lines, fmt.Sprintf("… and %d more", len(issues)-trayIssuesShown))
+			break
+		}
+		view.Lines = append(view.Lines, issue.Message)
+	}
+	if len(issues) == 0 {
+		view.Lines = []string{"No active issues"}
+	}
+	return view, nil
+}
+
+// trayIcon draws a dot in the color as a 32x32 PNG, wrapped in an ICO container for Windows
+func trayIcon(c [3]int, ico bool) []byte {
+	const size = 32
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	fill := color.NRGBA{R: uint8(c[0]), G: uint8(c[1]), B: uint8(c[2]), A: 255}
+	for y := range size {
+		for x := range size {
+			dx, dy := float64(x)-size/2+0.5, float64(y)-size/2+0.5
+			if dx*dx+dy*dy <= (size/2-1)*(size/2-1) {
+				img.SetNRGBA(x, y, fill)
+			}
+		}
+	}
+	var out bytes.Buffer
+	if err := png.Encode(&out, img); err != nil {
+		return nil
+	}
+	if !ico {
+		return out.Bytes()
+	}
+
+	// An ICO with a single PNG image: the ICONDIR header, one ICONDIRENTRY, then the PNG
+	var icon bytes.Buffer
+	binary.Write(&icon, binary.LittleEndian, struct {
+		Reserved, Type, Count            uint16
+		Width, Height, Colors, Reserved2 uint8
+		Planes, BitCount                 uint16
+		Size, Offset                     uint32
+	}{Type: 1, Count: 1, Width: size, Height: size, Planes: 1, BitCount: 32, Size: uint32(out.Len()), Offset: 22})
+	icon.Write(out.Bytes())
+	return icon.Bytes()
+}
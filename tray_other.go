@@ -0,0 +1,10 @@
+//go:build !tray
+
+package main
+
+import "fmt"
+
+// cliTray needs the native tray bindings, which are only built with the tray build tag
+func cliTray(_ *cliClient, _ []string) error {
+	return fmt.Errorf("built without tray support, rebuild with: go get fyne.io/systray && go build -tags tray")
+}
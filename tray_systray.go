@@ -0,0 +1,54 @@
+//go:build tray
+
+package main
+
+import (
+	"runtime"
+	"time"
+
+	"fyne.io/systray"
+)
+
+// cliTray shows the cluster state of the running instance as a tray icon in the bulb color,
+// with the active issues in its menu, until Quit is clicked
+func cliTray(c *cliClient, _ []string) error {
+	systray.Run(func() { go trayLoop(c) }, func() {})
+	return nil
+}
+
+// trayLoop builds the menu and refreshes the icon, tooltip and issue lines every trayInterval
+func trayLoop(c *cliClient) {
+	systray.SetTooltip("clusterbulb")
+	server := systray.AddMenuItem(c.server, "")
+	server.Disable()
+	systray.AddSeparator()
+	lines := make([]*systray.MenuItem, trayIssuesShown+1)
+	for i := range lines {
+		lines[i] = systray.AddMenuItem("", "")
+		lines[i].Disable()
+		lines[i].Hide()
+	}
+	systray.AddSeparator()
+	quit := systray.AddMenuItem("Quit", "Quit the tray icon")
+	go func() {
+		<-quit.ClickedCh
+		systray.Quit()
+	}()
+
+	ticker := time.NewTicker(trayInterval)
+	defer ticker.Stop()
+	for {
+		view, _ := trayFetch(c)
+		systray.SetIcon(trayIcon(view.Color, runtime.GOOS == "windows"))
+		systray.SetTooltip(view.Tooltip)
+		for i, item := range lines {
+			if i < len(view.Lines) {
+				item.SetTitle(view.Lines[i])
+				item.Show()
+			} else {
+				item.Hide()
+			}
+		}
+		<-ticker.C
+	}
+}
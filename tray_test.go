@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+	"testing"
+)
+
+func TestTrayFetch(t *testing.T) {
+	var issues []Issue
+	for i := range trayIssuesShown + 2 {
+		issues = append(issues, Issue{Key: fmt.Sprintf("pod/prod/api-%d", i), Type: "Pod", Message: fmt.Sprintf("Pod prod/api-%d is crash looping", i)})
+	}
+	srv, _ := cliTestServer(t, HealthReport{ClusterState: "pull_requests_open|issues_detected"}, issues)
+
+	c := &cliClient{server: srv.URL, token: "secret"}
+	view, err := trayFetch(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if red, _ := stateColor(SignalIssuesDetected); view.Color != red {
+		t.Errorf("color = %v, want the issues_detected color", view.Color)
+	}
+	if len(view.Lines) != trayIssuesShown+1 || view.Lines[0] != "Pod prod/api-0 is crash looping" || view.Lines[trayIssuesShown] != "… and 2 more" {
+		t.Errorf("lines = %q, want the first issues and a count of the rest", view.Lines)
+	}
+
+	c.token = "wrong"
+	if view, err := trayFetch(c); err == nil || view.Color != trayErrorColor {
+		t.Errorf("view = %+v, err %v, want the error color when the server rejects the token", view, err)
+	}
+}
+
+func TestTrayIcon(t *testing.T) {
+	img, err := png.Decode(bytes.NewReader(trayIcon([3]int{255, 0, 0}, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, g, b, a := img.At(16, 16).RGBA(); r>>8 != 255 || g != 0 || b != 0 || a>>8 != 255 {
+		t.Errorf("center = %d,%d,%d,%d, want opaque red", r>>8, g>>8, b>>8, a>>8)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Error("corner is not transparent")
+	}
+
+	// The ICO header points at the PNG that follows it
+	ico := trayIcon([3]int{255, 0, 0}, true)
+	if binary.LittleEndian.Uint16(ico[2:]) != 1 || binary.LittleEndian.Uint16(ico[4:]) != 1 {
+		t.Fatalf("header = %x, want an icon with one image", ico[:6])
+	}
+	size, offset := binary.LittleEndian.Uint32(ico[14:]), binary.LittleEndian.Uint32(ico[18:])
+	if _, err := png.Decode(bytes.NewReader(ico[offset : offset+size])); err != nil {
+		t.Errorf("ICO image: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+var trivyCriticalThreshold = 0        // os.Getenv("TRIVY_CRITICAL_THRESHOLD") // warn when more critical CVEs than this are reported
+var trivyState = SignalIssuesDetected // os.Getenv("TRIVY_STATE") // bulb state shown for vulnerability issues
+var vulnerabilitySummary = ""         // latest aggregated Trivy summary
+var trivyIssues []Issue               // issues of the last successful check, kept while the reports can't be listed
+
+// VulnerabilityReportList represents the Trivy Operator VulnerabilityReports of the cluster
+type VulnerabilityReportList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Report struct {
+			Artifact struct {
+				Repository string `json:"repository"`
+				Tag        string `json:"tag"`
+			} `json:"artifact"`
+			Summary struct {
+				CriticalCount int `json:"criticalCount"`
+				HighCount     int `json:"highCount"`
+			} `json:"summary"`
+		} `json:"report"`
+	} `json:"items"`
+}
+
+// checkTrivy aggregates the Trivy Operator VulnerabilityReports and raises a warning when the
+// number of critical CVEs exceeds TRIVY_CRITICAL_THRESHOLD; it does nothing when the operator isn't installed.
+// While the reports can't be listed the issues of the last successful check are kept.
+func checkTrivy(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var reports VulnerabilityReportList
+	found, err := listCustomResources(ctx, clientset, "aquasecurity.github.io/v1alpha1", "vulnerabilityreports", &reports)
+	if err != nil {
+		HandleError("trivy", "Error fetching vulnerability reports:", err)
+		return trivyIssues
+	}
+	if !found {
+		trivyIssues = nil
+		return nil
+	}
+	integrationOK("trivy")
+
+	type image struct {
+		name     string
+		critical int
+	}
+	var images []image
+	critical, high := 0, 0
+	for _, r := range reports.Items {
+		critical += r.Report.Summary.CriticalCount
+		high += r.Report.Summary.HighCount
+		if r.Report.Summary.CriticalCount > 0 {
+			name := r.Report.Artifact.Repository
+			if r.Report.Artifact.Tag != "" {
+				name += ":" + r.Report.Artifact.Tag
+			}
+			images = append(images, image{name: fmt.Sprintf("%s/%s (%s)", r.Metadata.Namespace, r.Metadata.Labels["trivy-operator.resource.name"], name), critical: r.Report.Summary.CriticalCount})
+		}
+	}
+	vulnerabilitySummary = fmt.Sprintf("%d critical and %d high CVEs across %d reports", critical, high, len(reports.Items))
+
+	if critical <= trivyCriticalThreshold {
+		trivyIssues = nil
+		return nil
+	}
+
+	// List the worst offenders first
+	sort.Slice(images, func(i, j int) bool { return images[i].critical > images[j].critical })
+	var worst []string
+	for i, img := range images {
+		if i == 3 {
+			break
+		}
+		worst = append(worst, fmt.Sprintf("%s: %d", img.name, img.critical))
+	}
+	message := fmt.Sprintf("%d critical CVEs (threshold %d), worst: %s", critical, trivyCriticalThreshold, strings.Join(worst, ", "))
+	trivyIssues = []Issue{{Key: "trivy/critical", Type: "Vulnerability", Severity: "warning", Message: message, Timestamp: time.Now()}}
+	return trivyIssues
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// tuiInterval is how often the terminal UI refreshes
+var tuiInterval = 2 * time.Second
+
+// tuiEngine is the client of the terminal UI when `tui` runs the monitor in this process
+var tuiEngine *cliClient
+
+// tuiKeys maps the keys of the terminal UI to user actions
+var tuiKeys = map[byte]string{'r': "recheck", 'a': "ack", 's': "snooze", 'u': "unmute"}
+
+// cliTUI redraws the state, issues, pull requests and recent transitions until interrupted or
+// q is pressed; it reads the same API as the other commands, so it shows exactly what the bulb
+// shows. r, a, s and u recheck, acknowledge, snooze and unmute like a Stream Deck.
+func cliTUI(c *cliClient, _ []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Keys are read unbuffered from a terminal, which then needs explicit carriage returns
+	keys := make(chan byte)
+	newline := "\n"
+	if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+		saved, err := term.MakeRaw(fd)
+		if err != nil {
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+		defer term.Restore(fd, saved)
+		newline = "\r\n"
+		go func() {
+			buf := make([]byte, 1)
+			for {
+				if n, err := os.Stdin.Read(buf); err != nil || n == 0 {
+					return
+				}
+				keys <- buf[0]
+			}
+		}()
+	}
+
+	out := c.out
+	defer fmt.Fprint(out, "\x1b[?25h") // show the cursor again
+	fmt.Fprint(out, "\x1b[?25l")
+	ticker := time.NewTicker(tuiInterval)
+	defer ticker.Stop()
+	status := ""
+	for {
+		var frame bytes.Buffer
+		frameClient := *c
+		frameClient.out = &frame
+		if err := tuiFrame(&frameClient); err != nil {
+			fmt.Fprintf(&frame, "\n%s\n", frameClient.paint(SignalIssuesDetected, "Error: "+err.Error()))
+		}
+		fmt.Fprintf(&frame, "\n[r]echeck [a]ck [s]nooze [u]nmute [q]uit  %s\n", status)
+		// Home the cursor and clear the screen, then draw the frame in one write to avoid flicker
+		fmt.Fprint(out, "\x1b[H\x1b[2J"+strings.ReplaceAll(frame.String(), "\n", newline))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case key := <-keys:
+			if key == 'q' || key == 3 { // Ctrl-C doesn't raise SIGINT in raw mode
+				return nil
+			}
+			if action, ok := tuiKeys[key]; ok {
+				status = tuiAction(c, action)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// tuiAction runs a user action and returns the status line to show. A remote monitor only
+// exposes recheck, the in-process one takes every action like a Stream Deck key.
+func tuiAction(c *cliClient, action string) string {
+	if tuiEngine != nil {
+		if !requestUserAction(action) {
+			return action + " dropped, too many pending actions"
+		}
+		return action + " requested"
+	}
+	if action != "recheck" {
+		return action + " needs the monitor in this process, run tui without --server"
+	}
+	req, err := http.NewRequest("POST", c.server+"/api/v1/recheck", nil)
+	if err != nil {
+		return "recheck failed: " + err.Error()
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := httpClient("clusterbulb").Do(req)
+	if err != nil {
+		return "recheck failed: " + err.Error()
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return "recheck failed: " + resp.Status
+	}
+	return "recheck requested"
+}
+
+// startTUIEngine shows the terminal UI of the monitor running in this process. The API is served
+// on a loopback port for the UI only, logs go to the bottom of the screen instead of scrolling
+// through it, and closing the UI stops the monitor.
+func startTUIEngine(c *cliClient, stop context.CancelFunc) error {
+	// The checks exit without a cluster config, which would be hidden behind the UI
+	if _, err := kubeRestConfig(); err != nil {
+		return fmt.Errorf("failed to get cluster config: %w", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen for the terminal UI: %w", err)
+	}
+	go http.Serve(listener, httpMux)
+	c.server, c.token = "http://"+listener.Addr().String(), apiToken
+
+	log.SetOutput(&tuiLog)
+	requestClusterRecheck() // don't wait for the first tick to have a report
+	go func() {
+		defer stop()
+		defer log.SetOutput(os.Stderr)
+		if err := cliTUI(c, nil); err != nil {
+			log.Printf("Terminal UI failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// tuiLogLines is how many recent log lines the terminal UI of an in-process monitor shows
+const tuiLogLines = 5
+
+// tuiLogBuffer keeps the last log lines of an in-process monitor for the terminal UI
+type tuiLogBuffer struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+var tuiLog tuiLogBuffer
+
+func (b *tuiLogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lines = append(b.lines, strings.Split(strings.TrimRight(string(p), "\n"), "\n")...)
+	if len(b.lines) > tuiLogLines {
+		b.lines = b.lines[len(b.lines)-tuiLogLines:]
+	}
+	return len(p), nil
+}
+
+// Lines returns a copy of the kept log lines, oldest first
+func (b *tuiLogBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.lines...)
+}
+
+// tuiFrame renders one screen of the terminal UI
+func tuiFrame(c *cliClient) error {
+	source := c.server
+	if tuiEngine != nil {
+		source = "(in-process)"
+	}
+	fmt.Fprintf(c.out, "clusterbulb %s  %s\n\n", source, time.Now().Format("15:04:05"))
+	if err := cliStatus(c, nil); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.out, "\nIssues")
+	if err := cliIssues(c, nil); err != nil {
+		return err
+	}
+
+	var report HealthReport
+	if err := c.get("/api/v1/report", &report); err != nil {
+		return err
+	}
+	if len(report.PullRequests) > 0 {
+		fmt.Fprintln(c.out, "\nPull requests")
+		for _, pr := range report.PullRequests {
+			line := pr.Message
+			if pr.PR != nil {
+				line = fmt.Sprintf("#%d %s (%s, %s)", pr.PR.Number, pr.Message, pr.PR.Author, pr.PR.Age)
+			}
+			fmt.Fprintln(c.out, c.paint(issueState(pr), "●")+" "+line)
+		}
+	}
+
+	var transitions []StateChange
+	if err := c.get("/api/v1/transitions", &transitions); err != nil {
+		return err
+	}
+	if len(transitions) > 0 {
+		fmt.Fprintln(c.out, "\nRecent transitions")
+		for i := len(transitions) - 1; i >= 0; i-- {
+			t := transitions[i]
+			var changes []string
+			for _, s := range t.Added {
+				changes = append(changes, c.paint(Signal(s), "+"+s))
+			}
+			for _, s := range t.Removed {
+				changes = append(changes, "-"+s)
+			}
+			fmt.Fprintf(c.out, "%s  %s\n", t.At.Local().Format("15:04:05"), strings.Join(changes, " "))
+		}
+	}
+
+	if lines := tuiLog.Lines(); len(lines) > 0 {
+		fmt.Fprintln(c.out, "\nLog")
+		for _, line := range lines {
+			fmt.Fprintln(c.out, line)
+		}
+	}
+	return nil
+}
+
+// tuiFlags registers the flags of the tui command
+func tuiFlags(flags *flag.FlagSet) {
+	flags.DurationVar(&tuiInterval, "interval", tuiInterval, "refresh interval")
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTUIFrame(t *testing.T) {
+	defer func(h issueHistory) { history = h }(history)
+	history = issueHistory{Since: time.Now(), Open: map[string]*IssueRecord{}}
+	at := time.Now().Add(-time.Minute)
+	historyTransition(Transition{From: ClusterState{SignalHealthy}, To: ClusterState{SignalIssuesDetected}, Added: []Signal{SignalIssuesDetected}, Removed: []Signal{SignalHealthy}, At: at})
+
+	srv, _ := cliTestServer(t, HealthReport{
+		Timestamp:    time.Now(),
+		ClusterState: "issues_detected",
+		TotalIssues:  1,
+		PullRequests: []Issue{{Key: "pr/42", Type: "PullRequest", Message: "Bump chart", PR: &PRDetail{Number: 42, Author: "renovate", Age: "2h"}}},
+	}, []Issue{{Key: "pod/prod/api", Type: "Pod", Namespace: "prod", Name: "api", Reason: "CrashLoopBackOff", Message: "Pod prod/api is crash looping"}})
+
+	var out strings.Builder
+	c := &cliClient{server: srv.URL, token: "secret", out: &out}
+	if err := tuiFrame(c); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"clusterbulb " + srv.URL,
+		"● issues_detected",
+		"Issues\n",
+		"CrashLoopBackOff",
+		"Pull requests\n● #42 Bump chart (renovate, 2h)",
+		"Recent transitions\n" + at.Local().Format("15:04:05") + "  +issues_detected -healthy",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+
+	// A failing request fails the frame, which cliTUI shows instead of exiting
+	c.token = "wrong"
+	if err := tuiFrame(c); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("frame with a wrong token: %v, want 401", err)
+	}
+}
+
+func TestTUIActions(t *testing.T) {
+	// Drain the actions the keys queued, as no scheduler runs them
+	t.Cleanup(func() {
+		for len(userActions) > 0 {
+			<-userActions
+		}
+	})
+	srv, _ := cliTestServer(t, HealthReport{}, nil)
+	c := &cliClient{server: srv.URL, token: "secret"}
+
+	// A remote monitor only takes a recheck
+	if status := tuiAction(c, "recheck"); status != "recheck requested" {
+		t.Errorf("remote recheck = %q", status)
+	}
+	if status := tuiAction(c, "snooze"); !strings.Contains(status, "without --server") {
+		t.Errorf("remote snooze = %q, want a hint to run in-process", status)
+	}
+	if action := <-userActions; action != "recheck" {
+		t.Errorf("queued %q, want recheck", action)
+	}
+
+	// The in-process monitor takes every action
+	defer func(e *cliClient) { tuiEngine = e }(tuiEngine)
+	tuiEngine = c
+	if status := tuiAction(c, "snooze"); status != "snooze requested" {
+		t.Errorf("in-process snooze = %q", status)
+	}
+	if action := <-userActions; action != "snooze" {
+		t.Errorf("queued %q, want snooze", action)
+	}
+}
+
+func TestTUILog(t *testing.T) {
+	defer func(lines []string) { tuiLog.lines = lines }(tuiLog.lines)
+	tuiLog.lines = nil
+	for i := range tuiLogLines + 2 {
+		fmt.Fprintf(&tuiLog, "line %d\n", i)
+	}
+	if lines := tuiLog.Lines(); len(lines) != tuiLogLines || lines[0] != "line 2" {
+		t.Errorf("lines = %q, want the last %d", lines, tuiLogLines)
+	}
+
+	defer func(e *cliClient) { tuiEngine = e }(tuiEngine)
+	srv, _ := cliTestServer(t, HealthReport{Timestamp: time.Now(), ClusterState: "healthy"}, nil)
+	c := &cliClient{server: srv.URL, token: "secret"}
+	tuiEngine = c
+	var out strings.Builder
+	c.out = &out
+	if err := tuiFrame(c); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"clusterbulb (in-process)", "\nLog\nline 2\n"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var k8sEOLUrl = "" // os.Getenv("K8S_EOL_URL") // e.g. https://endoflife.date/api/kubernetes.json, refreshes the built-in table
+var k8sMaxSkew = 2 // os.Getenv("K8S_MAX_SKEW") // minor versions a kubelet may lag behind the control plane
+
+// k8sEOL maps Kubernetes minor versions to the end of upstream patch support
+var k8sEOL = map[string]time.Time{
+	"1.27": time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC),
+	"1.28": time.Date(2024, 10, 28, 0, 0, 0, 0, time.UTC),
+	"1.29": time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+	"1.30": time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC),
+	"1.31": time.Date(2025, 10, 28, 0, 0, 0, 0, time.UTC),
+	"1.32": time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+	"1.33": time.Date(2026, 6, 28, 0, 0, 0, 0, time.UTC),
+	"1.34": time.Date(2026, 10, 27, 0, 0, 0, 0, time.UTC),
+	"1.35": time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC),
+}
+
+// checkVersions warns when the control plane runs a Kubernetes version past its end of life,
+// or when a kubelet lags further behind (or runs ahead of) the control plane than supported
+func checkVersions(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	if k8sEOLUrl != "" {
+		refreshK8sEOL(ctx)
+	}
+
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		log.Printf("Error fetching server version: %v", err)
+		return nil
+	}
+	serverMinor, err := strconv.Atoi(strings.TrimRight(info.Minor, "+"))
+	if err != nil {
+		log.Printf("Error parsing server version %q: %v", info.GitVersion, err)
+		return nil
+	}
+
+	var issues []Issue
+	cycle := fmt.Sprintf("%s.%d", info.Major, serverMinor)
+	if eol, ok := k8sEOL[cycle]; ok && time.Now().After(eol) {
+		issues = append(issues, Issue{Key: "version/server", Type: "Version", Severity: "warning", Message: fmt.Sprintf("Kubernetes %s reached end of life on %s", info.GitVersion, eol.Format("2006-01-02")), Timestamp: time.Now()})
+	}
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching nodes: %v", err)
+		return issues
+	}
+	for _, node := range nodes.Items {
+		kubelet := node.Status.NodeInfo.KubeletVersion
+		minor, ok := minorVersion(kubelet)
+		if !ok {
+			continue
+		}
+		if skew := serverMinor - minor; skew > k8sMaxSkew || skew < 0 {
+			message := fmt.Sprintf("Node %s runs kubelet %s, control plane is %s", node.Name, kubelet, info.GitVersion)
+			issues = append(issues, Issue{Key: "version/node/" + node.Name, Type: "Version", Severity: "warning", Message: message, Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
+
+// minorVersion returns the minor version of a version string such as v1.31.2+k3s1
+func minorVersion(version string) (int, bool) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) < 2 {
+		return 0, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	return minor, err == nil
+}
+
+// refreshK8sEOL updates the EOL table from an endoflife.date compatible API
+func refreshK8sEOL(ctx context.Context) {
+	req, err := http.NewRequestWithContext(ctx, "GET", k8sEOLUrl, nil)
+	if err != nil {
+		log.Printf("Error refreshing Kubernetes EOL table: %v", err)
+		return
+	}
+	var cycles []struct {
+		Cycle string `json:"cycle"`
+		EOL   string `json:"eol"`
+	}
+	if err := httpGetJSON("endoflife.date", req, &cycles); err != nil {
+		log.Printf("Error refreshing Kubernetes EOL table: %v", err)
+		return
+	}
+	for _, c := range cycles {
+		if eol, err := time.Parse("2006-01-02", c.EOL); err == nil {
+			k8sEOL[c.Cycle] = eol
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+var warmupDuration time.Duration // os.Getenv("WARMUP_MINUTES") // grace period after startup without notifications
+var warmupOnClusterBoot = false  // os.Getenv("WARMUP_ON_CLUSTER_BOOT") == "true" // restart the grace period when all nodes just became ready
+
+// warmupUntil is the end of the current warm-up window
+var warmupUntil time.Time
+
+// warmingUp reports whether the warm-up window is running; issues are still checked and recorded,
+// but notifications are held back and the bulb shows the starting color
+func warmingUp() bool {
+	return time.Now().Before(warmupUntil)
+}
+
+// startWarmup opens the warm-up window, e.g. on startup
+func startWarmup(from time.Time) {
+	if warmupDuration <= 0 {
+		return
+	}
+	if until := from.Add(warmupDuration); until.After(warmupUntil) {
+		warmupUntil = until
+		log.Printf("Warming up until %s, notifications are held back", warmupUntil.Format(time.RFC3339))
+	}
+}
+
+// warmupAlert is a notification held back by the warm-up window
+type warmupAlert struct {
+	message string
+	opts    NtfyOptions
+}
+
+var warmupAlerts []warmupAlert
+var warmupAlertsMu sync.Mutex
+
+// holdWarmupAlert keeps a notification until the warm-up window ends, replacing an earlier one
+// about the same thing
+func holdWarmupAlert(message string, opts NtfyOptions) {
+	key := ntfyKey(opts)
+	warmupAlertsMu.Lock()
+	defer warmupAlertsMu.Unlock()
+	warmupAlerts = slices.DeleteFunc(warmupAlerts, func(a warmupAlert) bool {
+		return ntfyKey(a.opts) == key
+	})
+	warmupAlerts = append(warmupAlerts, warmupAlert{message: message, opts: opts})
+}
+
+// sendWarmupAlerts sends the notifications held back once the warm-up window ended, skipping
+// those whose condition cleared during warm-up
+func sendWarmupAlerts() {
+	if warmingUp() {
+		return
+	}
+	warmupAlertsMu.Lock()
+	alerts := warmupAlerts
+	warmupAlerts = nil
+	warmupAlertsMu.Unlock()
+
+	for _, a := range alerts {
+		if a.opts.Active != nil && !a.opts.Active() {
+			continue
+		}
+		if err := SendNtfyAlert(a.message, a.opts); err != nil {
+			log.Printf("Error sending ntfy alert: %v", err)
+		}
+	}
+}
+
+// noteClusterReady extends the warm-up window when the cluster just booted, i.e. every node
+// became ready within the window; readySince is the oldest ready transition of the nodes
+func noteClusterReady(readySince time.Time) {
+	if !warmupOnClusterBoot || readySince.IsZero() || time.Since(readySince) > warmupDuration {
+		return
+	}
+	startWarmup(readySince)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWarmupAlertsSentWhenStillActive(t *testing.T) {
+	defer func(d string) { notifyDriver, recording, warmupUntil, warmupAlerts = d, nil, time.Time{}, nil }(notifyDriver)
+	notifyDriver, recording, warmupAlerts = "recording", nil, nil
+	warmupUntil = time.Now().Add(time.Hour)
+
+	cleared, active := false, true
+	SendNtfyAlert("CI is failing", NtfyOptions{Title: "CI failing: main", Priority: 4, Active: func() bool { return cleared }})
+	SendNtfyAlert("1 open", NtfyOptions{Title: "Pull Requests: 1", Priority: 3, Key: "prs", Active: func() bool { return active }})
+	SendNtfyAlert("2 open", NtfyOptions{Title: "Pull Requests: 2", Priority: 3, Key: "prs", Active: func() bool { return active }})
+	sendWarmupAlerts()
+	if len(recording) != 0 {
+		t.Fatalf("recorded %d notifications during warm-up", len(recording))
+	}
+
+	warmupUntil = time.Now().Add(-time.Second)
+	sendWarmupAlerts()
+	if len(recording) != 1 {
+		t.Fatalf("recorded %d notifications, want only the active one: %+v", len(recording), recording)
+	}
+	if payload := recording[0].Payload.(map[string]interface{}); payload["message"] != "2 open" {
+		t.Errorf("sent %v, want the latest alert about the pull requests", payload)
+	}
+
+	// The queue is emptied once sent
+	sendWarmupAlerts()
+	if len(recording) != 1 {
+		t.Errorf("recorded %d notifications, want the held back ones sent once", len(recording))
+	}
+}
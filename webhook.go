@@ -0,0 +1,87 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+var ghWebhookSecret = "" // os.Getenv("GH_WEBHOOK_SECRET") // enables the /webhooks/github endpoint
+
+// scmRecheck asks the scheduler to run scmChecks outside of the normal interval.
+// It is buffered so that a burst of webhook deliveries collapses into a single check.
+var scmRecheck = make(chan struct{}, 1)
+
+// webhookPayload holds the fields of interest shared by the supported GitHub events
+type webhookPayload struct {
+	Action string `json:"action"`
+}
+
+// ghWebhookHandler accepts GitHub pull_request, workflow_run and check_suite
+// webhooks, validates their HMAC signature, and triggers an immediate SCM check
+func ghWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 25<<20)) // GitHub caps payloads at 25MB
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !validGitHubSignature(body, r.Header.Get("X-Hub-Signature-256"), ghWebhookSecret) {
+		log.Printf("Rejected GitHub webhook with invalid signature from %s", r.RemoteAddr)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+	case "pull_request":
+		requestSCMRecheck()
+	case "workflow_run", "check_suite":
+		if payload.Action == "completed" {
+			requestSCMRecheck()
+		}
+	default:
+		log.Printf("Ignoring unsupported GitHub webhook event '%s'", event)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validGitHubSignature checks an X-Hub-Signature-256 header against the HMAC of the body
+func validGitHubSignature(body []byte, signature string, secret string) bool {
+	sig, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// requestSCMRecheck schedules an immediate SCM check unless one is already pending
+func requestSCMRecheck() {
+	select {
+	case scmRecheck <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkAdmissionWebhooks raises a critical issue for admission webhooks that are failing calls
+// (seen in "failed calling webhook" events) or whose backing service has no ready endpoints
+// while failurePolicy=Fail, since either silently blocks every matching create or update
+func checkAdmissionWebhooks(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	var issues []Issue
+	failing := map[string]bool{}
+
+	events, err := clientset.CoreV1().Events("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching events: %v", err)
+	} else {
+		since := time.Now().Add(-10 * time.Minute)
+		for _, e := range events.Items {
+			if eventLastSeen(e).Before(since) {
+				continue
+			}
+			name, ok := failedWebhookName(e.Message)
+			if !ok || failing[name] {
+				continue
+			}
+			failing[name] = true
+			message := fmt.Sprintf("Admission webhook %s is failing: %s/%s %s", name, e.Namespace, e.InvolvedObject.Name, e.Reason)
+			issues = append(issues, Issue{Key: "webhook/" + name, Type: "AdmissionWebhook", Severity: "critical", Message: message, Timestamp: time.Now()})
+		}
+	}
+
+	// Webhooks that fail closed need a ready backend
+	type webhook struct {
+		name    string
+		service *admissionv1.ServiceReference
+		policy  *admissionv1.FailurePolicyType
+	}
+	var webhooks []webhook
+	validating, err := clientset.AdmissionregistrationV1().ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching validating webhooks: %v", err)
+		return issues
+	}
+	for _, c := range validating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, webhook{w.Name, w.ClientConfig.Service, w.FailurePolicy})
+		}
+	}
+	mutating, err := clientset.AdmissionregistrationV1().MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching mutating webhooks: %v", err)
+		return issues
+	}
+	for _, c := range mutating.Items {
+		for _, w := range c.Webhooks {
+			webhooks = append(webhooks, webhook{w.Name, w.ClientConfig.Service, w.FailurePolicy})
+		}
+	}
+
+	for _, w := range webhooks {
+		// failurePolicy defaults to Fail in admissionregistration/v1
+		if w.service == nil || failing[w.name] || (w.policy != nil && *w.policy == admissionv1.Ignore) {
+			continue
+		}
+		hasEndpoints, err := serviceHasReadyEndpoints(ctx, clientset, w.service.Namespace, w.service.Name)
+		if err != nil {
+			log.Printf("Error fetching endpoints of webhook %s: %v", w.name, err)
+			continue
+		}
+		if !hasEndpoints {
+			failing[w.name] = true
+			message := fmt.Sprintf("Admission webhook %s has no ready endpoints behind %s/%s and failurePolicy=Fail", w.name, w.service.Namespace, w.service.Name)
+			issues = append(issues, Issue{Key: "webhook/" + w.name, Type: "AdmissionWebhook", Severity: "critical", Message: message, Timestamp: time.Now()})
+		}
+	}
+	return issues
+}
+
+// failedWebhookName extracts the webhook name from an apiserver error such as
+// `Internal error occurred: failed calling webhook "validate.nginx.ingress.kubernetes.io": ...`
+func failedWebhookName(message string) (string, bool) {
+	_, rest, ok := strings.Cut(message, `failed calling webhook "`)
+	if !ok {
+		return "", false
+	}
+	name, _, ok := strings.Cut(rest, `"`)
+	return name, ok
+}
+
+// serviceHasReadyEndpoints reports whether any EndpointSlice of the service has a ready endpoint
+func serviceHasReadyEndpoints(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (bool, error) {
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + name})
+	if err != nil {
+		return false, err
+	}
+	for _, slice := range slices.Items {
+		for _, ep := range slice.Endpoints {
+			if ep.Conditions.Ready == nil || *ep.Conditions.Ready {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
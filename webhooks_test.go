@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEventLastSeen(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	for _, tc := range []struct {
+		name  string
+		event v1.Event
+		want  time.Time
+	}{
+		{"core/v1", v1.Event{LastTimestamp: metav1.NewTime(now)}, now},
+		{"events.k8s.io/v1", v1.Event{EventTime: metav1.NewMicroTime(now)}, now},
+		{"series", v1.Event{EventTime: metav1.NewMicroTime(now.Add(-time.Hour)), Series: &v1.EventSeries{LastObservedTime: metav1.NewMicroTime(now)}}, now},
+	} {
+		if got := eventLastSeen(tc.event); !got.Equal(tc.want) {
+			t.Errorf("%s: %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAdmissionWebhookEventsWithEventTime(t *testing.T) {
+	event := &v1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "e1", Namespace: "web"},
+		InvolvedObject: v1.ObjectReference{Name: "web-abc"},
+		Reason:         "FailedCreate",
+		Message:        `Internal error occurred: failed calling webhook "validate.nginx.ingress.kubernetes.io": connection refused`,
+		EventTime:      metav1.NewMicroTime(time.Now()),
+	}
+	issues := checkAdmissionWebhooks(context.Background(), fake.NewSimpleClientset(event))
+	if len(issues) != 1 || issues[0].Key != "webhook/validate.nginx.ingress.kubernetes.io" {
+		t.Errorf("issues = %v, want the failing webhook", issues)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var zoneOutageWindow = 5 * time.Minute // os.Getenv("ZONE_OUTAGE_MINUTES") // how long a zone or its replicas must be down, so rolling restarts don't count
+
+// checkZones groups nodes and pods by topology.kubernetes.io/zone and raises a single critical
+// issue when every node of a zone has been NotReady for zoneOutageWindow, or when a workload
+// spread over zones lost every replica in one of them for as long. Clusters without zone labels
+// are skipped.
+func checkZones(ctx context.Context, clientset kubernetes.Interface) []Issue {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching nodes: %v", err)
+		return nil
+	}
+
+	nodeZones := map[string]string{}
+	total, ready := map[string]int{}, map[string]int{}
+	downSince := map[string]time.Time{} // when the last node of the zone went NotReady
+	for _, node := range nodes.Items {
+		zone := node.Labels[v1.LabelTopologyZone]
+		if zone == "" {
+			continue
+		}
+		nodeZones[node.Name] = zone
+		total[zone]++
+		since := node.CreationTimestamp.Time
+		for _, cond := range node.Status.Conditions {
+			if cond.Type != v1.NodeReady {
+				continue
+			}
+			if cond.Status == v1.ConditionTrue {
+				ready[zone]++
+			}
+			since = cond.LastTransitionTime.Time
+		}
+		if since.After(downSince[zone]) {
+			downSince[zone] = since
+		}
+	}
+	if len(total) == 0 {
+		return nil
+	}
+
+	var issues []Issue
+	var zones []string
+	for zone := range total {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	for _, zone := range zones {
+		if ready[zone] == 0 && time.Since(downSince[zone]) >= zoneOutageWindow {
+			issues = append(issues, Issue{Key: "zone/" + zone, Type: "ZoneOutage", Severity: "critical", Message: fmt.Sprintf("Zone %s is down: all %d nodes are NotReady", zone, total[zone]), Timestamp: time.Now()})
+		}
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		log.Printf("Error fetching pods: %v", err)
+		return issues
+	}
+
+	// Count ready pods per workload and zone; a zone with pods but none ready lost all its replicas
+	type zoneCount struct {
+		pods, ready int
+		since       time.Time // when the last replica in the zone stopped being ready
+	}
+	type workload struct{ namespace, owner string }
+	owners := &podOwners{clientset: clientset, replicaSets: map[string]string{}}
+	workloads := map[workload]map[string]*zoneCount{}
+	for _, pod := range pods.Items {
+		zone := nodeZones[pod.Spec.NodeName]
+		if zone == "" || pod.Status.Phase == v1.PodSucceeded {
+			continue
+		}
+		w := workload{pod.Namespace, owners.owner(ctx, pod)}
+		if w.owner == "" {
+			continue
+		}
+		if workloads[w] == nil {
+			workloads[w] = map[string]*zoneCount{}
+		}
+		if workloads[w][zone] == nil {
+			workloads[w][zone] = &zoneCount{}
+		}
+		c := workloads[w][zone]
+		c.pods++
+		since := pod.CreationTimestamp.Time
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type != v1.PodReady {
+				continue
+			}
+			if cond.Status == v1.ConditionTrue {
+				c.ready++
+			}
+			since = cond.LastTransitionTime.Time
+		}
+		if since.After(c.since) {
+			c.since = since
+		}
+	}
+	for w, counts := range workloads {
+		if len(counts) < 2 {
+			continue
+		}
+		_, name, _ := strings.Cut(w.owner, "/")
+		for zone, c := range counts {
+			// Whole-zone outages are already reported above
+			if c.ready == 0 && ready[zone] > 0 && time.Since(c.since) >= zoneOutageWindow {
+				issues = append(issues, Issue{Key: fmt.Sprintf("zone/%s/%s/%s", zone, w.namespace, w.owner), Type: "ZoneOutage", Severity: "critical",
+					Message: fmt.Sprintf("%s/%s lost all %d replicas in zone %s", w.namespace, w.owner, c.pods, zone), Timestamp: time.Now(),
+					Namespace: w.namespace, Name: name, Owner: w.owner})
+			}
+		}
+	}
+	return issues
+}
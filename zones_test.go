@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestZoneOutageNeedsWholeZoneDownForWindow(t *testing.T) {
+	node := func(name, zone string, ready bool, changedAgo time.Duration) *v1.Node {
+		status := v1.ConditionFalse
+		if ready {
+			status = v1.ConditionTrue
+		}
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{v1.LabelTopologyZone: zone}},
+			Status: v1.NodeStatus{Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: status, LastTransitionTime: metav1.NewTime(time.Now().Add(-changedAgo))},
+			}},
+		}
+	}
+	for _, tc := range []struct {
+		name  string
+		nodes []*v1.Node
+		want  int
+	}{
+		{"one node restarting", []*v1.Node{node("a1", "a", false, time.Hour), node("a2", "a", true, time.Hour), node("b1", "b", true, time.Hour)}, 0},
+		{"single-node zone rebooting", []*v1.Node{node("a1", "a", false, time.Minute), node("b1", "b", true, time.Hour)}, 0},
+		{"zone just went down", []*v1.Node{node("a1", "a", false, time.Hour), node("a2", "a", false, time.Minute), node("b1", "b", true, time.Hour)}, 0},
+		{"zone down", []*v1.Node{node("a1", "a", false, time.Hour), node("a2", "a", false, 10*time.Minute), node("b1", "b", true, time.Hour)}, 1},
+	} {
+		clientset := fake.NewSimpleClientset()
+		for _, n := range tc.nodes {
+			clientset.Tracker().Add(n)
+		}
+		if issues := checkZones(context.Background(), clientset); len(issues) != tc.want {
+			t.Errorf("%s: %d issues %v, want %d", tc.name, len(issues), issues, tc.want)
+		}
+	}
+}